@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// AlertRule raises an alert when Threshold entries at Level or above matching
+// Match are logged within Window, at most once per Cooldown:
+//
+//	l.AddAlert(AlertRule{Name: "db down", Match: "connection refused", Threshold: 5, Window: time.Minute})
+type AlertRule struct {
+	Name      string
+	Level     Level         // least severe level counted; zero (DEBUG) means ERROR
+	Match     any           // as for AddFilter; nil counts every entry
+	Threshold int           // entries within Window that raise the alert
+	Window    time.Duration // how far back entries are counted
+	Cooldown  time.Duration // quiet time after an alert, Window if zero
+}
+
+// Alert is raised by an AlertRule.
+type Alert struct {
+	Rule    string
+	Count   int           // entries counted within the window
+	Window  time.Duration // the rule's window
+	First   time.Time     // time of the first counted entry
+	Last    Record        // the entry that raised the alert
+	Message string        // summary, also logged at CRITICAL
+}
+
+// AlertSink receives alerts, e.g. to page someone. Send is called on its own
+// goroutine, so it may block on the network.
+type AlertSink interface {
+	SendAlert(a Alert) error
+}
+
+// AlertFunc adapts a function to an AlertSink.
+type AlertFunc func(a Alert) error
+
+func (f AlertFunc) SendAlert(a Alert) error {
+	return f(a)
+}
+
+// alerts is the state of the alert rules.
+type alerts struct {
+	mu    sync.Mutex
+	rules []*alertRule
+	sinks []AlertSink
+	wg    sync.WaitGroup // sends in flight, waited for on shutdown
+}
+
+type alertRule struct {
+	AlertRule
+	match func(Record) bool
+	hits  []time.Time
+	fired time.Time
+	fire  func(Alert) // if set, called instead of logging and sending the alert
+}
+
+// AddAlert adds an alert rule. When it fires, a CRITICAL entry describing
+// the alert, with an "alert" field holding the rule name, is logged (without
+// exiting) and the alert is sent to every sink added with AddAlertSink.
+func (l *Mylogger) AddAlert(r AlertRule) error {
+	if err := l.addAlertRule(r, nil); err != nil {
+		return fmt.Errorf("add alert: %w", err)
+	}
+	return nil
+}
+
+// addAlertRule adds r, calling fire when it fires if fire isn't nil.
+func (l *Mylogger) addAlertRule(r AlertRule, fire func(Alert)) error {
+	if r.Threshold < 1 || r.Window <= 0 {
+		return errors.New("threshold and window must be positive")
+	}
+	if r.Level.severity() < 0 {
+		return fmt.Errorf("unknown level %v", r.Level)
+	}
+	if r.Level == DEBUG {
+		r.Level = ERROR
+	}
+	if r.Cooldown <= 0 {
+		r.Cooldown = r.Window
+	}
+	ar := &alertRule{AlertRule: r, match: func(Record) bool { return true }, fire: fire}
+	if r.Match != nil {
+		fn, err := matcher(r.Match)
+		if err != nil {
+			return err
+		}
+		ar.match = fn
+	}
+	l.alerts.mu.Lock()
+	defer l.alerts.mu.Unlock()
+	l.alerts.rules = append(l.alerts.rules, ar)
+	return nil
+}
+
+// AddAlertSink sends the alerts raised by the alert rules to s.
+func (l *Mylogger) AddAlertSink(s AlertSink) {
+	l.alerts.mu.Lock()
+	defer l.alerts.mu.Unlock()
+	l.alerts.sinks = append(l.alerts.sinks, s)
+}
+
+// checkAlerts counts r against the alert rules and raises the alerts that fire.
+func (l *Mylogger) checkAlerts(r Record) {
+	l.alerts.mu.Lock()
+	var fired []Alert
+	for _, ar := range l.alerts.rules {
+		if r.Level.severity() < ar.Level.severity() || !l.matches("alert "+ar.Name, ar.match, r) {
+			continue
+		}
+		cutoff := r.Time.Add(-ar.Window)
+		i := 0
+		for i < len(ar.hits) && !ar.hits[i].After(cutoff) {
+			i++
+		}
+		ar.hits = append(ar.hits[i:], r.Time)
+		if len(ar.hits) < ar.Threshold || r.Time.Sub(ar.fired) < ar.Cooldown {
+			continue
+		}
+		ar.fired = r.Time
+		a := Alert{Rule: ar.Name, Count: len(ar.hits), Window: ar.Window, First: ar.hits[0], Last: r}
+		a.Message = fmt.Sprintf("alert %s: %d entries in %s, last: %s", ar.Name, a.Count, helpers.Duration(ar.Window), r.Message)
+		if ar.fire != nil {
+			ar.fire(a)
+			continue
+		}
+		fired = append(fired, a)
+	}
+	sinks := l.alerts.sinks
+	l.alerts.mu.Unlock()
+
+	for _, a := range fired {
+		l.write(entry{level: CRITICAL, value: a.Message, alert: true, attrs: []Attr{Field("alert", a.Rule)}})
+		for _, s := range sinks {
+			l.alerts.wg.Add(1)
+			go func(s AlertSink, a Alert) {
+				defer l.alerts.wg.Done()
+				if err := helpers.Safe(func() error { return s.SendAlert(a) }); err != nil {
+					l.writef(ERROR, "alert %s: sending failed: %v", a.Rule, err)
+				}
+			}(s, a)
+		}
+	}
+}
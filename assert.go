@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// WithPanicOnAssert makes failed assertions panic straight away, see
+// Assert. Meant for tests and development, where a broken invariant should
+// stop everything at the spot.
+func WithPanicOnAssert() Option {
+	return func(l *Mylogger) error {
+		l.assertPanics = true
+		return nil
+	}
+}
+
+// Assert checks an invariant. If cond is false, msg is logged at CRITICAL
+// with the stack, and the logger shuts down gracefully, as Shutdown does
+// with an error: tracked routines are waited for and the program exits with
+// status 1. With WithPanicOnAssert it panics instead. It returns cond, so
+// the caller can bail out while shutdown runs.
+// Example:
+// if !l.Assert(len(batch) <= max, "batch over the limit") { return }
+func (l *Mylogger) Assert(cond bool, msg string) bool {
+	if !cond {
+		l.assertFailed(errors.New("assertion failed: " + msg))
+	}
+	return cond
+}
+
+// AssertErr is Assert for an error that shouldn't happen: it fails if err
+// isn't nil, and reports whether err is nil.
+func (l *Mylogger) AssertErr(err error) bool {
+	if err != nil {
+		l.assertFailed(fmt.Errorf("assertion failed: unexpected error: %w", err))
+	}
+	return err == nil
+}
+
+func (l *Mylogger) assertFailed(err error) {
+	if l.assertPanics {
+		panic(err)
+	}
+	en := entry{level: CRITICAL, value: err, attrs: []Attr{Field("stack", string(debug.Stack()))}}
+	if l.caller {
+		en.caller = caller()
+	}
+	l.send(en)
+	// the caller may be a tracked routine that Shutdown waits for.
+	go l.Shutdown(err)
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// audit records a change to the logger's settings made while it runs, with
+// the old and new values and what made it, so configuration drift can be
+// traced in an incident. It's written whatever the level: a change that
+// raises the level would otherwise hide its own record.
+func (l *Mylogger) audit(by, setting string, old, new any) {
+	l.send(entry{
+		level:  INFO,
+		value:  fmt.Sprintf("Logger %s changed from %v to %v by %s", setting, old, new, by),
+		attrs:  []Attr{Field("setting", setting), Field("old", old), Field("new", new), Field("changed_by", by)},
+		always: true,
+	})
+}
+
+// encoderName returns the format name of enc, as accepted by encoderByName.
+func encoderName(enc Encoder) string {
+	switch e := enc.(type) {
+	case TextEncoder:
+		return "text"
+	case JSONEncoder:
+		if e.Schema == 1 {
+			return "json-v1"
+		}
+		return "json"
+	case LogfmtEncoder:
+		return "logfmt"
+	case DockerEncoder:
+		return "docker"
+	case CBOREncoder:
+		return "cbor"
+	}
+	encodersMu.RLock()
+	name, ok := encoderTypes[reflect.TypeOf(enc)]
+	encodersMu.RUnlock()
+	if ok {
+		return name
+	}
+	return fmt.Sprintf("%T", enc)
+}
+
+// name describes the destination: "stdout", "stderr", a file path, or the
+// writer's type, e.g. "bytes.Buffer".
+func (s *swapWriter) name() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.std != "" {
+		return s.std
+	}
+	if f, ok := s.w.(*os.File); ok {
+		return f.Name()
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", s.w), "*")
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// lineCounter counts the lines written to it, so a benchmark can wait for
+// every entry to come out of the transport.
+type lineCounter struct{ n atomic.Int64 }
+
+func (c *lineCounter) Write(p []byte) (int, error) {
+	c.n.Add(int64(bytes.Count(p, []byte{'\n'})))
+	return len(p), nil
+}
+
+// BenchmarkTransports logs b.N entries, spread over a number of goroutines,
+// through each transport and for each message size, and waits for all of
+// them to be written. There's no ring buffer transport: the channel queue
+// is the logger's only asynchronous one.
+func BenchmarkTransports(b *testing.B) {
+	transports := []struct {
+		name string
+		opts []Option
+	}{
+		{"channel", nil},
+		{"locked", []Option{WithLockedThread()}},
+		{"sync", []Option{WithSyncMode()}},
+	}
+	for _, tr := range transports {
+		for _, size := range []int{16, 256, 4096} {
+			for _, goroutines := range []int{1, 4, 16} {
+				b.Run(fmt.Sprintf("%s/size=%d/goroutines=%d", tr.name, size, goroutines), func(b *testing.B) {
+					benchmarkTransport(b, tr.opts, size, goroutines)
+				})
+			}
+		}
+	}
+}
+
+func benchmarkTransport(b *testing.B, opts []Option, size, goroutines int) {
+	out := &lineCounter{}
+	l, err := StartLogger(append([]Option{WithOutput(out)}, opts...)...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer l.Shutdown(nil)
+	// entries the logger writes itself, like the start up ones, aren't counted.
+	time.Sleep(10 * time.Millisecond)
+	base := out.n.Load()
+	msg := strings.Repeat("x", size)
+	b.SetBytes(int64(size))
+	b.ReportAllocs()
+	b.ResetTimer()
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		n := b.N / goroutines
+		if g < b.N%goroutines {
+			n++
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				l.Info(msg)
+			}
+		}()
+	}
+	wg.Wait()
+	for out.n.Load()-base < int64(b.N) {
+		time.Sleep(10 * time.Microsecond)
+	}
+	b.StopTimer()
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// boost is a temporary level, see BoostLevel.
+type boost struct {
+	mu    sync.Mutex
+	timer *time.Timer // set while a boost is on
+	prev  Level       // the level to go back to
+	to    Level       // the boosted level
+	gen   int         // counts boosts, so a stale end does nothing
+}
+
+// BoostLevel lowers the level to e for d, then puts the previous level
+// back, so DEBUG can be turned on for live debugging without the risk of
+// leaving it on. The start and the end are logged like any level change,
+// see SetLevel. Boosting again while a boost is on replaces it, keeping the
+// level from before the first one. If the level is changed during the boost,
+// it's left alone at the end. The returned func ends the boost early.
+// Example:
+// defer l.BoostLevel(DEBUG, 5*time.Minute)()
+func (l *Mylogger) BoostLevel(e Level, d time.Duration) (end func()) {
+	by := caller()
+	b := &l.boost
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.timer == nil {
+		if e.severity() < 0 || e.severity() >= l.Level().severity() {
+			return func() {}
+		}
+		b.prev = l.Level()
+	} else {
+		b.timer.Stop()
+	}
+	b.gen++
+	gen := b.gen
+	b.to = e
+	l.setLevel(e, fmt.Sprintf("BoostLevel for %s at %s", helpers.Duration(d), by))
+	end = func() { l.endBoost(gen, by) }
+	b.timer = time.AfterFunc(d, end)
+	return end
+}
+
+// endBoost puts the level from before boost gen back.
+func (l *Mylogger) endBoost(gen int, by string) {
+	b := &l.boost
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.gen != gen || b.timer == nil {
+		return
+	}
+	b.timer.Stop()
+	b.timer = nil
+	if l.Level() == b.to {
+		l.setLevel(b.prev, "the end of BoostLevel at "+by)
+	}
+}
@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// maxCaptured is how many entries a Capture holds; past it the oldest go.
+const maxCaptured = 1000
+
+// Capture is a Scope for one request that holds its entries back, at every
+// level, DEBUG included, and writes them only if the request turns out
+// badly: it failed, logged an error, or was slow. Good requests cost a few
+// appends, and bad ones get their full trace. Call End when the request is
+// done.
+// Example:
+// c := l.Capture(time.Second, Field("request_id", id))
+// defer func() { c.End(err) }()
+// c.Debug("cache miss") // written only if the request fails or is slow
+type Capture struct {
+	*Scope
+	start time.Time
+	slow  time.Duration
+}
+
+// capture holds the entries of a Capture and of the Scopes made from it.
+type capture struct {
+	mu      sync.Mutex
+	entries []entry // a ring once full, the oldest at oldest
+	oldest  int
+	failed  bool // an ERROR or worse was logged
+	dropped int  // entries dropped for being older than the last maxCaptured
+	done    bool // End was called; later entries are logged as usual
+}
+
+// Capture returns a Capture with attrs on every entry. Its entries are
+// written if End is called with an error, if ERROR or worse was logged, or
+// if the request took slow or longer; a slow of 0 turns that last check off.
+func (l *Mylogger) Capture(slow time.Duration, attrs ...Attr) *Capture {
+	s := (&Scope{l: l, capture: &capture{}}).With(attrs...)
+	return &Capture{Scope: s, start: time.Now(), slow: slow}
+}
+
+// End writes the captured entries if err isn't nil, an error was logged, or
+// the request was slow, and discards them otherwise. It reports whether they
+// were written. Entries logged through the Capture after End are logged as
+// usual.
+func (c *Capture) End(err error) bool {
+	bad := err != nil || c.slow > 0 && time.Since(c.start) >= c.slow
+	return c.capture.end(c.l, bad)
+}
+
+// add holds en back, stamped with the time and caller of the log call.
+func (c *capture) add(l *Mylogger, en entry) {
+	en.at = l.now()
+	if l.caller {
+		en.caller = caller()
+	}
+	c.mu.Lock()
+	if c.done {
+		c.mu.Unlock()
+		if l.enabled(en.level) {
+			l.send(en)
+		}
+		return
+	}
+	defer c.mu.Unlock()
+	if en.level.severity() >= ERROR.severity() {
+		c.failed = true
+	}
+	if len(c.entries) == maxCaptured {
+		c.entries[c.oldest] = en
+		c.oldest = (c.oldest + 1) % maxCaptured
+		c.dropped++
+		return
+	}
+	c.entries = append(c.entries, en)
+}
+
+// end writes the held entries if bad or an error was logged, and stops
+// holding entries back.
+func (c *capture) end(l *Mylogger, bad bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done {
+		return false
+	}
+	c.done = true
+	entries := c.entries
+	c.entries = nil
+	if !bad && !c.failed {
+		return false
+	}
+	if c.oldest > 0 {
+		entries = append(entries[c.oldest:len(entries):len(entries)], entries[:c.oldest]...)
+	}
+	if c.dropped > 0 {
+		l.send(entry{level: WARNING, value: "%d earlier entries of the capture were dropped", args: []any{c.dropped}, format: true, at: entries[0].at, always: true})
+	}
+	for _, en := range entries {
+		en.always = true
+		l.send(en)
+	}
+	return true
+}
+
+// flush writes what's held so far, before a critical entry. c may be nil.
+func (c *capture) flush(l *Mylogger) {
+	if c != nil {
+		c.end(l, true)
+	}
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCaptureKeepsTheLatestEntries(t *testing.T) {
+	var out syncBuffer
+	l, err := StartLogger(WithOutput(&out), WithEncoder(TextEncoder{}), WithLevel(DEBUG))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := l.Capture(0)
+	n := maxCaptured + 250
+	for i := 0; i < n; i++ {
+		c.Debug(fmt.Sprintf("step %d.", i))
+	}
+	if !c.End(fmt.Errorf("failed")) {
+		t.Fatal("End didn't write the captured entries")
+	}
+	l.Shutdown(nil)
+
+	var got []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if _, msg, ok := strings.Cut(line, ": "); ok {
+			got = append(got, msg)
+		}
+	}
+	want := []string{"250 earlier entries of the capture were dropped"}
+	for i := n - maxCaptured; i < n; i++ {
+		want = append(want, fmt.Sprintf("step %d.", i))
+	}
+	if len(got) < len(want) {
+		t.Fatalf("%d entries written, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("entry %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func BenchmarkCaptureFull(b *testing.B) {
+	l, err := StartLogger(WithSyncMode(), WithOutput(&syncBuffer{}))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer l.Shutdown(nil)
+	c := l.Capture(0)
+	for i := 0; i < maxCaptured; i++ {
+		c.Debug("fill")
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Debug("entry")
+	}
+}
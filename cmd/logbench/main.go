@@ -0,0 +1,244 @@
+// Command logbench is a load generator for the logger. It logs as fast as it
+// can from a number of goroutines, for each combination of transport, message
+// size and goroutine count asked for, and prints the throughput of each,
+// along with the median and 99th percentile latency of a log call:
+//
+//	logbench -modes channel,locked,sync,direct -sizes 16,256,4096 -goroutines 1,4,16 -duration 2s
+//
+// Entries go through a pipe to a reader that counts them, so a run only ends
+// once everything logged has been written out. The "sync" mode is the logger
+// started with WithSyncMode, "locked" runs the mediator on a locked OS thread
+// (WithLockedThread), and "direct" logs with a bare log.Logger, as a
+// baseline for the logger's own transports. The logger has no ring buffer
+// transport, so there's no mode for one: the channel queue is its only
+// asynchronous transport. The same comparison runs as Go benchmarks with
+// go test -bench Transports.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jeanhaley32/logger"
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// a mode starts a logger writing to w, and returns its log function and a
+// function that stops it.
+type mode func(w *os.File) (logf func(string), stop func(), err error)
+
+var modes = map[string]mode{
+	"channel": func(w *os.File) (func(string), func(), error) {
+		return startLogger(logger.WithOutput(w))
+	},
+	"locked": func(w *os.File) (func(string), func(), error) {
+		return startLogger(logger.WithOutput(w), logger.WithLockedThread())
+	},
+	"sync": func(w *os.File) (func(string), func(), error) {
+		return startLogger(logger.WithOutput(w), logger.WithSyncMode())
+	},
+	"direct": func(w *os.File) (func(string), func(), error) {
+		l := log.New(w, "INFO:", log.Lshortfile)
+		return func(s string) { l.Println(s) }, func() {}, nil
+	},
+}
+
+func startLogger(opts ...logger.Option) (func(string), func(), error) {
+	l, err := logger.StartLogger(opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return func(s string) { l.Info(s) }, func() { l.Shutdown(nil) }, nil
+}
+
+type result struct {
+	mode       string
+	size       int
+	goroutines int
+	entries    int64
+	bytes      int64
+	elapsed    time.Duration
+	allocs     uint64
+	latencies  []time.Duration // a sample of log call durations, sorted
+}
+
+// sampleEvery is how often a log call is timed; timing every call would
+// slow the calls down noticeably.
+const sampleEvery = 16
+
+// percentile returns the p-th percentile of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[min(int(float64(len(sorted))*p/100), len(sorted)-1)]
+}
+
+func main() {
+	var (
+		modeList   = flag.String("modes", "channel,locked,sync,direct", "comma separated transports to run: "+strings.Join(modeNames(), ", "))
+		sizeList   = flag.String("sizes", "16,256,4096", "comma separated message sizes, in bytes")
+		goroutines = flag.String("goroutines", "1,4,16", "comma separated numbers of logging goroutines")
+		duration   = flag.Duration("duration", 2*time.Second, "how long each combination logs for")
+	)
+	flag.Parse()
+	sizes, err := parseInts(*sizeList)
+	if err != nil {
+		fatal(fmt.Errorf("-sizes: %w", err))
+	}
+	counts, err := parseInts(*goroutines)
+	if err != nil {
+		fatal(fmt.Errorf("-goroutines: %w", err))
+	}
+	names := strings.Split(*modeList, ",")
+	for _, name := range names {
+		if modes[name] == nil {
+			fatal(fmt.Errorf("unknown mode %q", name))
+		}
+	}
+	fmt.Printf("%s %s/%s, %d CPUs\n\n", runtime.Version(), runtime.GOOS, runtime.GOARCH, runtime.NumCPU())
+	t := helpers.NewTable("mode", "size", "goroutines", "entries", "entries/s", "written/s", "allocs/entry", "p50", "p99")
+	t.RightAlign = []int{1, 2, 3, 4, 5, 6, 7, 8}
+	t.Color = helpers.IsTerminal(os.Stdout)
+	for _, name := range names {
+		for _, size := range sizes {
+			for _, n := range counts {
+				r, err := run(name, size, n, *duration)
+				if err != nil {
+					fatal(err)
+				}
+				secs := r.elapsed.Seconds()
+				t.AddRow(r.mode, helpers.Bytes(int64(r.size)), r.goroutines, helpers.Count(r.entries),
+					helpers.Count(int64(float64(r.entries)/secs)), helpers.Bytes(int64(float64(r.bytes)/secs)),
+					strconv.FormatFloat(float64(r.allocs)/float64(max(r.entries, 1)), 'f', 1, 64),
+					helpers.Duration(percentile(r.latencies, 50)), helpers.Duration(percentile(r.latencies, 99)))
+			}
+		}
+	}
+	t.Render(os.Stdout)
+}
+
+// run logs size byte messages from n goroutines for d, then waits for every
+// entry to come out of the pipe.
+func run(name string, size, n int, d time.Duration) (result, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return result{}, err
+	}
+	defer r.Close()
+	var lines, written atomic.Int64
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+		br := bufio.NewReaderSize(r, 1<<16)
+		buf := make([]byte, 1<<16)
+		for {
+			k, err := br.Read(buf)
+			lines.Add(int64(bytes.Count(buf[:k], []byte{'\n'})))
+			written.Add(int64(k))
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	msg := strings.Repeat("x", size)
+	logf, stop, err := modes[name](w)
+	if err != nil {
+		return result{}, err
+	}
+	var sent atomic.Int64
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	deadline := start.Add(d)
+	var wg sync.WaitGroup
+	samples := make([][]time.Duration, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			lat := make([]time.Duration, 0, 1<<14)
+			for time.Now().Before(deadline) {
+				// check the clock every so often rather than every entry.
+				for j := 0; j < 64; j++ {
+					if j%sampleEvery != 0 {
+						logf(msg)
+						continue
+					}
+					t0 := time.Now()
+					logf(msg)
+					lat = append(lat, time.Since(t0))
+				}
+				sent.Add(64)
+			}
+			samples[i] = lat
+		}(i)
+	}
+	wg.Wait()
+	// wait for the transport to catch up.
+	for lines.Load() < sent.Load() {
+		if time.Since(deadline) > time.Minute {
+			return result{}, fmt.Errorf("%s: only %d of %d entries written after a minute", name, lines.Load(), sent.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	stop()
+	w.Close()
+	<-readDone
+	var latencies []time.Duration
+	for _, lat := range samples {
+		latencies = append(latencies, lat...)
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return result{
+		mode:       name,
+		size:       size,
+		goroutines: n,
+		entries:    sent.Load(),
+		bytes:      written.Load(),
+		elapsed:    elapsed,
+		allocs:     after.Mallocs - before.Mallocs,
+		latencies:  latencies,
+	}, nil
+}
+
+func modeNames() []string {
+	var names []string
+	for name := range modes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func parseInts(s string) ([]int, error) {
+	var out []int
+	for _, f := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("bad number %q", f)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "logbench:", err)
+	os.Exit(1)
+}
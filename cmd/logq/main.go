@@ -0,0 +1,290 @@
+// Command logq answers questions about log files written by the logger:
+//
+//	logq levels [-bucket 1m] [file ...]         entries per level per time bucket
+//	logq errors [-n 10] [file ...]              most frequent error messages
+//	logq slow -field duration [-n 10] [file ...] slowest entries by a duration field
+//	logq validate [file ...]                    JSON lines that don't match the entry schema
+//	logq schema                                 print the entry JSON Schema
+//
+// It reads the JSON output best, but understands the text, logfmt and CBOR
+// formats as well. With no files it reads stdin.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jeanhaley32/logger"
+	"github.com/jeanhaley32/logger/helpers"
+	"github.com/jeanhaley32/logger/internal/logfile"
+)
+
+var levels = []string{"debug", "info", "warning", "error", "critical"}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "levels":
+		err = levelsCmd(args)
+	case "errors":
+		err = errorsCmd(args)
+	case "slow":
+		err = slowCmd(args)
+	case "validate":
+		err = validateCmd(args)
+	case "schema":
+		_, err = os.Stdout.Write(logger.EntryJSONSchema())
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "logq:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: logq levels|errors|slow|validate [flags] [file ...]\n       logq schema")
+	os.Exit(2)
+}
+
+// levelsCmd counts entries by level, per time bucket.
+func levelsCmd(args []string) error {
+	fs := flag.NewFlagSet("levels", flag.ExitOnError)
+	bucket := fs.Duration("bucket", time.Minute, "size of each time bucket")
+	fs.Parse(args)
+	if *bucket <= 0 {
+		return fmt.Errorf("bucket must be positive")
+	}
+	counts := map[time.Time]map[string]int{}
+	err := scan(fs.Args(), func(r logfile.Record) {
+		t := r.Time.Truncate(*bucket)
+		if counts[t] == nil {
+			counts[t] = map[string]int{}
+		}
+		counts[t][r.Level]++
+	})
+	if err != nil {
+		return err
+	}
+	keys := make([]time.Time, 0, len(counts))
+	for t := range counts {
+		keys = append(keys, t)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Before(keys[j]) })
+	t := newTable(append([]string{"time"}, levels...)...)
+	for i := range levels {
+		t.RightAlign = append(t.RightAlign, i+1)
+	}
+	for _, k := range keys {
+		row := []any{"-"}
+		if !k.IsZero() {
+			row[0] = k.Format("2006-01-02 15:04:05")
+		}
+		for _, lvl := range levels {
+			row = append(row, counts[k][lvl])
+		}
+		t.AddRow(row...)
+	}
+	return t.Render(os.Stdout)
+}
+
+// errorsCmd ranks error and critical entries by fingerprint.
+func errorsCmd(args []string) error {
+	fs := flag.NewFlagSet("errors", flag.ExitOnError)
+	n := fs.Int("n", 10, "number of fingerprints to show")
+	fs.Parse(args)
+	type group struct {
+		count       int
+		example     string
+		first, last time.Time
+	}
+	groups := map[string]*group{}
+	err := scan(fs.Args(), func(r logfile.Record) {
+		if logfile.Severity(r.Level) < logfile.Severity("error") {
+			return
+		}
+		fp := helpers.Fingerprint(r.Msg)
+		g := groups[fp]
+		if g == nil {
+			g = &group{example: r.Msg, first: r.Time}
+			groups[fp] = g
+		}
+		g.count++
+		g.last = r.Time
+	})
+	if err != nil {
+		return err
+	}
+	fps := make([]string, 0, len(groups))
+	for fp := range groups {
+		fps = append(fps, fp)
+	}
+	sort.Slice(fps, func(i, j int) bool {
+		if a, b := groups[fps[i]].count, groups[fps[j]].count; a != b {
+			return a > b
+		}
+		return fps[i] < fps[j]
+	})
+	t := newTable("count", "last seen", "fingerprint", "example")
+	t.RightAlign = []int{0}
+	t.MaxWidth = 60
+	for _, fp := range fps[:min(*n, len(fps))] {
+		g := groups[fp]
+		t.AddRow(g.count, formatTime(g.last), fp, g.example)
+	}
+	return t.Render(os.Stdout)
+}
+
+// slowCmd lists the entries with the largest value in a duration field.
+func slowCmd(args []string) error {
+	fs := flag.NewFlagSet("slow", flag.ExitOnError)
+	field := fs.String("field", "duration", "field holding the duration")
+	unit := fs.Duration("unit", time.Millisecond, "unit of durations logged as plain numbers")
+	n := fs.Int("n", 10, "number of entries to show")
+	fs.Parse(args)
+	type entry struct {
+		d   time.Duration
+		rec logfile.Record
+	}
+	var slow []entry
+	err := scan(fs.Args(), func(r logfile.Record) {
+		v, ok := r.Fields[*field]
+		if !ok {
+			return
+		}
+		d, ok := parseDuration(v, *unit)
+		if !ok {
+			return
+		}
+		slow = append(slow, entry{d, r})
+	})
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(slow, func(i, j int) bool { return slow[i].d > slow[j].d })
+	t := newTable(*field, "time", "level", "message")
+	t.RightAlign = []int{0}
+	t.MaxWidth = 80
+	for _, e := range slow[:min(*n, len(slow))] {
+		t.AddRow(helpers.Duration(e.d), formatTime(e.rec.Time), e.rec.Level, e.rec.Msg)
+	}
+	return t.Render(os.Stdout)
+}
+
+// validateCmd reports the lines that don't match the entry schema, and
+// fails if there are any.
+func validateCmd(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	fs.Parse(args)
+	bad, total := 0, 0
+	check := func(name string, r io.Reader) error {
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 64*1024), 1024*1024)
+		for n := 1; sc.Scan(); n++ {
+			if strings.TrimSpace(sc.Text()) == "" {
+				continue
+			}
+			total++
+			if err := logfile.Validate(sc.Text()); err != nil {
+				bad++
+				fmt.Printf("%s:%d: %v\n", name, n, err)
+			}
+		}
+		return sc.Err()
+	}
+	if fs.NArg() == 0 {
+		if err := check("stdin", os.Stdin); err != nil {
+			return err
+		}
+	}
+	for _, name := range fs.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		err = check(name, f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("read %s: %w", name, err)
+		}
+	}
+	if bad > 0 {
+		return fmt.Errorf("%d of %d lines are not valid entries", bad, total)
+	}
+	return nil
+}
+
+func newTable(headers ...string) *helpers.Table {
+	t := helpers.NewTable(headers...)
+	t.Color = helpers.IsTerminal(os.Stdout)
+	return t
+}
+
+// scan calls fn with every entry in files, or stdin when there are none.
+func scan(files []string, fn func(logfile.Record)) error {
+	read := func(r io.Reader) error {
+		br := bufio.NewReader(r)
+		if b, err := br.Peek(1); err == nil && logfile.IsCBOR(b[0]) {
+			return logfile.ReadCBOR(br, fn)
+		}
+		sc := bufio.NewScanner(br)
+		sc.Buffer(make([]byte, 64*1024), 1024*1024)
+		for sc.Scan() {
+			if rec, ok := logfile.Parse(sc.Text()); ok {
+				fn(rec)
+			}
+		}
+		return sc.Err()
+	}
+	if len(files) == 0 {
+		return read(os.Stdin)
+	}
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		err = read(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("read %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// parseDuration reads a duration logged as a string like "1.5s", or as a
+// number of unit.
+func parseDuration(v any, unit time.Duration) (time.Duration, bool) {
+	switch t := v.(type) {
+	case json.Number:
+		f, err := t.Float64()
+		return time.Duration(f * float64(unit)), err == nil
+	case string:
+		if d, err := time.ParseDuration(t); err == nil {
+			return d, true
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+		return time.Duration(f * float64(unit)), err == nil
+	}
+	return 0, false
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
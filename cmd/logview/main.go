@@ -0,0 +1,216 @@
+// Command logview prints log files written by the logger, colorized and
+// filtered, and can follow them across rotation like `tail -F`.
+//
+//	logview [-f] [-level warning] [-fields user,path] [-since 1h] [-time relative] [file ...]
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jeanhaley32/logger/colors"
+	"github.com/jeanhaley32/logger/helpers"
+	"github.com/jeanhaley32/logger/internal/logfile"
+)
+
+type options struct {
+	follow   bool
+	minLevel int
+	fields   map[string]bool // nil shows every field
+	since    time.Time
+	until    time.Time
+	color    bool
+	raw      bool
+	time     string // layout or helpers.FormatTime name of the timestamps
+}
+
+func main() {
+	var (
+		o         options
+		level     = flag.String("level", "debug", "minimum level to show")
+		fields    = flag.String("fields", "", "comma separated fields to show, \"-\" for none (default all)")
+		since     = flag.String("since", "", "only entries at or after this time (RFC3339, or a duration ago like 1h)")
+		until     = flag.String("until", "", "only entries before this time (RFC3339, or a duration ago)")
+		colorMode = flag.String("color", "auto", "colorize output: auto, always or never")
+	)
+	flag.BoolVar(&o.follow, "f", false, "follow the files, across rotation and truncation")
+	flag.BoolVar(&o.raw, "raw", true, "print lines that aren't log entries as they are")
+	flag.StringVar(&o.time, "time", "2006-01-02 15:04:05.000", "timestamp format: a Go layout, or iso8601, rfc3339nano, isoweek or relative")
+	flag.Parse()
+
+	var err error
+	if o.minLevel = logfile.Severity(*level); o.minLevel < 0 {
+		fatal(fmt.Errorf("unknown level %q", *level))
+	}
+	if *fields != "" {
+		o.fields = map[string]bool{}
+		for _, f := range strings.Split(*fields, ",") {
+			o.fields[strings.TrimSpace(f)] = true
+		}
+	}
+	if o.since, err = parseWhen(*since); err != nil {
+		fatal(err)
+	}
+	if o.until, err = parseWhen(*until); err != nil {
+		fatal(err)
+	}
+	switch *colorMode {
+	case "auto":
+		o.color = helpers.IsTerminal(os.Stdout)
+	case "always":
+		o.color = true
+	case "never":
+	default:
+		fatal(fmt.Errorf("unknown color mode %q", *colorMode))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	if err := run(ctx, w, flag.Args(), o); err != nil {
+		w.Flush()
+		fatal(err)
+	}
+}
+
+// run prints the files, or stdin when there are none, then follows them if asked.
+func run(ctx context.Context, w *bufio.Writer, files []string, o options) error {
+	if len(files) == 0 {
+		return printAll(w, os.Stdin, o)
+	}
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		err = printAll(w, f, o)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("read %s: %w", name, err)
+		}
+	}
+	if !o.follow {
+		return nil
+	}
+	var tails []<-chan string
+	for _, name := range files {
+		lines, err := helpers.TailFile(ctx, name)
+		if err != nil {
+			return err
+		}
+		tails = append(tails, lines)
+	}
+	w.Flush()
+	for line := range helpers.Merge(ctx, tails...) {
+		printLine(w, line, o)
+		w.Flush()
+	}
+	return nil
+}
+
+func printAll(w *bufio.Writer, r io.Reader, o options) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		printLine(w, sc.Text(), o)
+	}
+	return sc.Err()
+}
+
+func printLine(w io.Writer, line string, o options) {
+	rec, ok := logfile.Parse(line)
+	if !ok {
+		if o.raw && o.minLevel == 0 && o.since.IsZero() && o.until.IsZero() {
+			fmt.Fprintln(w, line)
+		}
+		return
+	}
+	if logfile.Severity(rec.Level) < o.minLevel {
+		return
+	}
+	if !rec.Time.IsZero() {
+		if !o.since.IsZero() && rec.Time.Before(o.since) {
+			return
+		}
+		if !o.until.IsZero() && !rec.Time.Before(o.until) {
+			return
+		}
+	}
+	fmt.Fprintln(w, render(rec, o))
+}
+
+// render formats a record as "time LEVEL message key=value ...".
+func render(rec logfile.Record, o options) string {
+	paint := func(c colors.Color, s string) string {
+		if !o.color {
+			return s
+		}
+		return colors.Wrap(c, s)
+	}
+	var sb strings.Builder
+	if !rec.Time.IsZero() {
+		sb.WriteString(paint(colors.GRAY, helpers.FormatTime(rec.Time, o.time)))
+		sb.WriteByte(' ')
+	}
+	sb.WriteString(paint(levelColor(rec.Level), fmt.Sprintf("%-8s", strings.ToUpper(rec.Level))))
+	sb.WriteByte(' ')
+	sb.WriteString(rec.Msg)
+	for _, k := range rec.Keys() {
+		if o.fields != nil && !o.fields[k] {
+			continue
+		}
+		sb.WriteString(" " + paint(colors.GREEN, k+"=") + quote(logfile.FieldString(rec.Fields[k])))
+	}
+	return sb.String()
+}
+
+// levelColor matches the logger's default level colors.
+func levelColor(level string) colors.Color {
+	switch level {
+	case "debug":
+		return colors.BLUE
+	case "warning":
+		return colors.YELLOW
+	case "error":
+		return colors.RED
+	case "critical":
+		return colors.PURPLE
+	}
+	return colors.WHITE
+}
+
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
+
+// parseWhen parses an absolute time, or a duration before now.
+func parseWhen(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	t, err := logfile.ParseTime(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("bad time %q: want RFC3339 or a duration", s)
+	}
+	return t, nil
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "logview:", err)
+	os.Exit(1)
+}
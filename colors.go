@@ -1,40 +1,26 @@
 package logger
 
-type Color int64
+import "github.com/jeanhaley32/logger/colors"
+
+// Color is defined in the colors package so the helpers can use it too.
+type Color = colors.Color
 
 const (
-	RED Color = iota
-	GREEN
-	GRAY
-	WHITE
-	YELLOW
-	PURPLE
-	BLUE
+	RED    = colors.RED
+	GREEN  = colors.GREEN
+	GRAY   = colors.GRAY
+	WHITE  = colors.WHITE
+	YELLOW = colors.YELLOW
+	PURPLE = colors.PURPLE
+	BLUE   = colors.BLUE
 )
 
-// Returns color as a string
-func (c Color) Color() string {
-	switch c {
-	case RED:
-		return "\033[31m"
-	case GREEN:
-		return "\033[32m"
-	case GRAY:
-		return "\033[37m"
-	case WHITE:
-		return "\033[97m"
-	case YELLOW:
-		return "\033[33m"
-	case PURPLE:
-		return "\033[35m"
-	case BLUE:
-		return "\033[34m"
-	}
-	return ""
+// ParseColor returns the Color with the given name, ignoring case.
+func ParseColor(s string) (Color, error) {
+	return colors.ParseColor(s)
 }
 
 // colorWrap wraps a string in a color
 func colorWrap(c Color, m string) string {
-	const Reset = "\033[0m"
-	return c.Color() + m + Reset
+	return colors.Wrap(c, m)
 }
@@ -0,0 +1,79 @@
+// Package colors holds the ANSI colors used by the logger and the helpers.
+package colors
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Color int64
+
+const (
+	RED Color = iota
+	GREEN
+	GRAY
+	WHITE
+	YELLOW
+	PURPLE
+	BLUE
+)
+
+// Reset is the escape sequence that ends a colored section.
+const Reset = "\033[0m"
+
+// Returns color as a string
+func (c Color) Color() string {
+	switch c {
+	case RED:
+		return "\033[31m"
+	case GREEN:
+		return "\033[32m"
+	case GRAY:
+		return "\033[37m"
+	case WHITE:
+		return "\033[97m"
+	case YELLOW:
+		return "\033[33m"
+	case PURPLE:
+		return "\033[35m"
+	case BLUE:
+		return "\033[34m"
+	}
+	return ""
+}
+
+// Returns the name of the color
+func (c Color) String() string {
+	switch c {
+	case RED:
+		return "red"
+	case GREEN:
+		return "green"
+	case GRAY:
+		return "gray"
+	case WHITE:
+		return "white"
+	case YELLOW:
+		return "yellow"
+	case PURPLE:
+		return "purple"
+	case BLUE:
+		return "blue"
+	}
+	return "none"
+}
+
+// ParseColor returns the Color with the given name, ignoring case.
+func ParseColor(s string) (Color, error) {
+	for c := RED; c <= BLUE; c++ {
+		if strings.EqualFold(s, c.String()) {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown color %q", s)
+}
+
+// Wrap wraps a string in a color
+func Wrap(c Color, m string) string {
+	return c.Color() + m + Reset
+}
@@ -0,0 +1,320 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// Config holds the logger settings that can be loaded from a config file,
+// typically embedded in the application's own config and read with
+// helpers.LoadConfig:
+//
+//	type AppConfig struct {
+//		Logger logger.Config `json:"logger" yaml:"logger" toml:"logger"`
+//	}
+type Config struct {
+	// Minimum level logged: debug, info, warning, error or critical.
+	Level string `json:"level" yaml:"level" toml:"level"`
+	// Where logs are written: stdout, stderr or a file path. Empty keeps the current output.
+	Output string `json:"output" yaml:"output" toml:"output"`
+	// Go time layout used for timestamps, or one of iso8601, rfc3339nano,
+	// isoweek and relative. Empty keeps the current format.
+	TimeFormat string `json:"time_format" yaml:"time_format" toml:"time_format"`
+	// Format of the main output: text, json, json-v1, logfmt, docker, cbor, or one added with RegisterEncoder. Empty keeps the current format.
+	Format string `json:"format" yaml:"format" toml:"format"`
+	// Rules that change the level of matching entries, see AddRule. They
+	// replace the rules of the previous config.
+	Rules []RuleConfig `json:"rules" yaml:"rules" toml:"rules"`
+	// Color per level name, e.g. {"error": "red", "debug": "blue"}.
+	Colors map[string]string `json:"colors" yaml:"colors" toml:"colors"`
+}
+
+// ApplyConfig applies c to a running logger. Nothing is changed if any part
+// of c is invalid. Every setting it changes is logged, see SetLevel.
+func (l *Mylogger) ApplyConfig(c Config) error {
+	return l.applyConfig(c, caller())
+}
+
+// applyConfig is ApplyConfig, with the changes put down to by.
+func (l *Mylogger) applyConfig(c Config, by string) error {
+	level := l.Level()
+	if c.Level != "" {
+		lv, err := ParseLevel(c.Level)
+		if err != nil {
+			return fmt.Errorf("apply config: %w", err)
+		}
+		level = lv
+	}
+	colors := map[Level]Color{}
+	for name, cname := range c.Colors {
+		lv, err := ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("apply config: colors: %w", err)
+		}
+		col, err := ParseColor(cname)
+		if err != nil {
+			return fmt.Errorf("apply config: colors: %w", err)
+		}
+		colors[lv] = col
+	}
+	if c.TimeFormat != "" {
+		if err := checkTimeFormat(c.TimeFormat); err != nil {
+			return fmt.Errorf("apply config: %w", err)
+		}
+	}
+	relevel, err := parseRules(c.Rules)
+	if err != nil {
+		return fmt.Errorf("apply config: %w", err)
+	}
+	var enc Encoder
+	if c.Format != "" {
+		e, err := encoderByName(c.Format)
+		if err != nil {
+			return fmt.Errorf("apply config: %w", err)
+		}
+		enc = e
+	}
+	var out io.Writer
+	switch strings.ToLower(c.Output) {
+	case "":
+	case "stdout":
+		out = os.Stdout
+	case "stderr":
+		out = os.Stderr
+	default:
+		f, err := os.OpenFile(c.Output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("apply config: %w", err)
+		}
+		out = f
+	}
+
+	type change struct {
+		setting  string
+		old, new any
+	}
+	var changes []change
+	if out != nil {
+		new := c.Output
+		if std := stdName(out); std != "" {
+			new = std
+		}
+		if old := l.out.name(); old != new {
+			changes = append(changes, change{"output", old, new})
+		}
+	}
+	if enc != nil {
+		if old, new := encoderName(l.sinks[0].enc.Load().(encoderBox).Encoder), encoderName(enc); old != new {
+			changes = append(changes, change{"format", old, new})
+		}
+	}
+
+	l.setLevel(level, by)
+	if out != nil {
+		// one output again, as after WithOutput: WithStdStreams' split ends.
+		l.sinks[0].errw.Store(nil)
+		l.out.Set(out)
+	}
+	if enc != nil {
+		l.sinks[0].setEncoder(enc)
+	}
+	l.rules.config.Store(&relevel)
+	var styled []change
+	l.setStyle(func(s *style) {
+		styled = styled[:0]
+		for lv, col := range colors {
+			if old := s.color(lv); old != col {
+				styled = append(styled, change{"color." + lv.name(), old, col})
+			}
+			s.colors[lv.severity()] = col
+		}
+		if c.TimeFormat != "" && c.TimeFormat != s.timeFormat {
+			styled = append(styled, change{"time_format", s.timeFormat, c.TimeFormat})
+			s.timeFormat = c.TimeFormat
+		}
+	})
+	changes = append(changes, styled...)
+	for _, ch := range changes {
+		l.audit(by, ch.setting, ch.old, ch.new)
+	}
+	return nil
+}
+
+// fileConfig is the layout WatchConfig expects: the logger settings under a
+// top-level "logger" key, next to whatever else the application keeps there.
+type fileConfig struct {
+	Logger Config `json:"logger" yaml:"logger" toml:"logger"`
+}
+
+// WatchConfig applies the "logger" section of the config file at path, and
+// re-applies it whenever the file changes, so levels, output and colors can be
+// changed without a restart. Watching stops when ctx is done.
+func (l *Mylogger) WatchConfig(ctx context.Context, path string) error {
+	by := "config file " + path
+	// the changes are logged by applyConfig, with who made them.
+	cv, err := helpers.WatchConfigQuiet(ctx, path, func(_, c *fileConfig) {
+		if err := l.applyConfig(c.Logger, by); err != nil {
+			l.Error(err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return l.applyConfig(cv.Get().Logger, by)
+}
+
+// SetLevel sets the minimum level logged. DEBUG enables verbose output.
+// A change is logged at INFO, whatever the level, with the old and new
+// levels and the file and line it was made from.
+func (l *Mylogger) SetLevel(e Level) {
+	l.setLevel(e, caller())
+}
+
+// setLevel sets the level, logging the change as made by by, unless by is
+// empty.
+func (l *Mylogger) setLevel(e Level, by string) {
+	if old := Level(l.level.Swap(int64(e))); old != e && by != "" {
+		l.audit(by, "level", old.name(), e.name())
+	}
+}
+
+// Level returns the minimum level logged.
+func (l *Mylogger) Level() Level {
+	return Level(l.level.Load())
+}
+
+// enabled reports whether entries of level e are currently logged.
+func (l *Mylogger) enabled(e Level) bool {
+	return e.severity() >= l.Level().severity()
+}
+
+// Enabled reports whether entries at e are logged, so callers can skip the
+// work of building entries that would be dropped.
+func (l *Mylogger) Enabled(e Level) bool {
+	return l.enabled(e)
+}
+
+// checkTimeFormat rejects layouts without any time fields, which would stamp
+// every entry with the same text. The names helpers.FormatTime knows, like
+// "iso8601", are accepted too.
+func checkTimeFormat(layout string) error {
+	if helpers.IsTimeFormatName(layout) {
+		return nil
+	}
+	if layout == "" || time.Unix(0, 0).UTC().Format(layout) == time.Unix(1e9+123, 0).UTC().Format(layout) {
+		return fmt.Errorf("time format %q has no time fields", layout)
+	}
+	return nil
+}
+
+// ParseLevel returns the level with the given name, ignoring case.
+// "warn" is accepted for WARNING.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return DEBUG, nil
+	case "info":
+		return INFO, nil
+	case "warning", "warn":
+		return WARNING, nil
+	case "error":
+		return ERROR, nil
+	case "critical":
+		return CRITICAL, nil
+	}
+	return 0, fmt.Errorf("unknown level %q", s)
+}
+
+// swapWriter is an io.Writer whose destination can be replaced while the
+// logger is running. Files opened by ApplyConfig are closed when swapped out.
+type swapWriter struct {
+	mu       sync.Mutex
+	w        io.Writer
+	owned    bool             // true if w was opened by the logger and should be closed on swap.
+	terminal bool             // true if w is a terminal, checked when it's set.
+	std      string           // "stdout" or "stderr" if w is one of them.
+	written  *helpers.Counter // bytes written over the logger's lifetime, shared by every output.
+	own      atomic.Int64     // bytes written to this output.
+	failures atomic.Int64     // writes that failed, see Mylogger.write
+	failing  atomic.Bool      // the last write failed, see Mylogger.write
+}
+
+func newSwapWriter(w io.Writer, written *helpers.Counter) *swapWriter {
+	return &swapWriter{w: w, terminal: helpers.IsTerminal(w), std: stdName(w), written: written}
+}
+
+// stdName returns the name of the standard stream w is, if it's one.
+func stdName(w io.Writer) string {
+	switch w {
+	case os.Stdout:
+		return "stdout"
+	case os.Stderr:
+		return "stderr"
+	}
+	return ""
+}
+
+func (s *swapWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, err := s.w.Write(p)
+	s.written.Add(int64(n))
+	s.own.Add(int64(n))
+	return n, err
+}
+
+// isTerminal reports whether the current destination is a terminal.
+func (s *swapWriter) isTerminal() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.terminal
+}
+
+// stream returns "stdout" or "stderr" if the destination is one of them.
+func (s *swapWriter) stream() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.std
+}
+
+// Written returns the number of bytes written so far.
+func (s *swapWriter) Written() int64 {
+	return s.written.Load()
+}
+
+// Set replaces the destination writer.
+func (s *swapWriter) Set(w io.Writer) {
+	_, isFile := w.(*os.File)
+	s.setOwned(w, isFile && w != os.Stdout && w != os.Stderr)
+}
+
+// setOwned replaces the destination writer; owned writers are closed when
+// they're replaced.
+func (s *swapWriter) setOwned(w io.Writer, owned bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeLocked()
+	s.w, s.owned, s.terminal, s.std = w, owned, helpers.IsTerminal(w), stdName(w)
+}
+
+// close closes the destination if the logger opened it.
+func (s *swapWriter) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeLocked()
+}
+
+func (s *swapWriter) closeLocked() {
+	if c, ok := s.w.(io.Closer); ok && s.owned {
+		c.Close()
+	}
+	s.owned = false
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+func TestConfigOutputEndsStdStreams(t *testing.T) {
+	l, err := StartLogger(WithSyncMode(), WithStdStreams(), WithEncoder(TextEncoder{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Shutdown(nil)
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := l.ApplyConfig(Config{Output: path}); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("info")
+	l.Warning("warning")
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"INFO: info\n", "WARNING: warning\n"} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("the config's output lacks %q:\n%s", want, b)
+		}
+	}
+}
+
+func TestWatchConfigLogsEachChangeOnce(t *testing.T) {
+	var out syncBuffer
+	l, err := StartLogger(WithSyncMode(), WithOutput(&out), WithEncoder(TextEncoder{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Shutdown(nil)
+	helpers.SetLogger(l) // where the helpers' own diff log would go
+	defer helpers.SetLogger(nil)
+
+	path := filepath.Join(t.TempDir(), "app.json")
+	if err := os.WriteFile(path, []byte(`{"logger": {"level": "info"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := l.WatchConfig(ctx, path); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := l.Watch("Logger level changed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"logger": {"level": "warning"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the reload wasn't applied:\n%s", out.String())
+	}
+	if n := strings.Count(out.String(), "warning"); n != 2 {
+		// once as the new value in the message, once as the new field.
+		t.Errorf("the change is logged %d times:\n%s", n/2, out.String())
+	}
+	if strings.Contains(out.String(), "config app.json:") {
+		t.Errorf("the helper's diff was logged too:\n%s", out.String())
+	}
+}
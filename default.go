@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+var (
+	defaultLogger atomic.Pointer[Mylogger]
+	defaultMu     sync.Mutex // serializes creating the default logger
+)
+
+// Default returns the logger used by the package level functions. Unless one
+// was set with SetDefault, it's created on first use, writing to stderr in
+// sync mode so nothing is lost when a small program exits without Shutdown.
+func Default() *Mylogger {
+	if l := defaultLogger.Load(); l != nil {
+		return l
+	}
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if l := defaultLogger.Load(); l != nil {
+		return l
+	}
+	l := helpers.Must(StartLogger(WithSyncMode()))
+	defaultLogger.Store(l)
+	return l
+}
+
+// SetDefault makes l the logger used by the package level functions, and the
+// one the helpers package logs through.
+func SetDefault(l *Mylogger) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultLogger.Store(l)
+	helpers.SetLogger(l)
+}
+
+// Log Critical Error with the default logger and exit
+func Critical(a any) {
+	Default().Critical(a)
+}
+
+// Log Critical Error with a format with the default logger and exit
+func Criticalf(format string, args ...any) {
+	Default().Criticalf(format, args...)
+}
+
+// Log Error with the default logger
+func Error(a any) {
+	Default().Error(a)
+}
+
+// Log Error with a format, like fmt.Printf, with the default logger
+func Errorf(format string, args ...any) {
+	Default().Errorf(format, args...)
+}
+
+// Log err at its severity with the default logger, see Mylogger.LogError
+func LogError(err error) {
+	Default().LogError(err)
+}
+
+// Check an invariant with the default logger, see Mylogger.Assert
+func Assert(cond bool, msg string) bool {
+	return Default().Assert(cond, msg)
+}
+
+// Check that err is nil with the default logger, see Mylogger.AssertErr
+func AssertErr(err error) bool {
+	return Default().AssertErr(err)
+}
+
+// Warn that the calling function is deprecated, once per call site, with the
+// default logger, see Mylogger.Deprecated
+func Deprecated(msg string) {
+	Default().deprecated(msg)
+}
+
+// Log Warning with the default logger
+func Warning(a any) {
+	Default().Warning(a)
+}
+
+// Log Warning with a format, like fmt.Printf, with the default logger
+func Warningf(format string, args ...any) {
+	Default().Warningf(format, args...)
+}
+
+// Log Information with the default logger
+func Info(a any) {
+	Default().Info(a)
+}
+
+// Log Information with a format, like fmt.Printf, with the default logger
+func Infof(format string, args ...any) {
+	Default().Infof(format, args...)
+}
+
+// Log Debug Message with the default logger
+func Debug(a any) {
+	Default().Debug(a)
+}
+
+// Log Debug Message with a format, like fmt.Printf, with the default logger
+func Debugf(format string, args ...any) {
+	Default().Debugf(format, args...)
+}
+
+// Begin an operation with the default logger, see Mylogger.Begin
+func Begin(name string, attrs ...Attr) *Operation {
+	return Default().Begin(name, attrs...)
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+)
+
+// Deprecated logs at WARNING that the function calling it is deprecated,
+// once per place that function is called from, so library authors can flag
+// deprecated APIs without flooding their users' logs.
+// Example:
+//
+//	func OldFetch() {
+//		l.Deprecated("use Fetch instead") // OldFetch is deprecated, called from main.go:12: use Fetch instead
+//		...
+//	}
+func (l *Mylogger) Deprecated(msg string) {
+	l.deprecated(msg)
+}
+
+// deprecated is Deprecated, called through exactly one exported function.
+func (l *Mylogger) deprecated(msg string) {
+	if !l.enabled(WARNING) {
+		return
+	}
+	// the deprecated function and where it was called from, skipping
+	// runtime.Callers, deprecated and the exported Deprecated.
+	var pcs [2]uintptr
+	if runtime.Callers(3, pcs[:]) < 2 {
+		return
+	}
+	if _, seen := l.deprecations.LoadOrStore(pcs[1], struct{}{}); seen {
+		return
+	}
+	frames := runtime.CallersFrames(pcs[:])
+	fn, _ := frames.Next()
+	site, _ := frames.Next()
+	at := fmt.Sprintf("%s:%d", path.Base(site.File), site.Line)
+	en := entry{level: WARNING, value: fmt.Sprintf("%s is deprecated, called from %s: %s", path.Base(fn.Function), at, msg)}
+	if l.caller {
+		en.caller = at
+	}
+	l.send(en)
+}
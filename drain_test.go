@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// entryRe matches the messages logged by TestShutdownLosesNoEntries.
+var entryRe = regexp.MustCompile(`entry (p\d+-\d+)\.`)
+
+func TestShutdownLosesNoEntries(t *testing.T) {
+	old := tuneEvery
+	tuneEvery = 5 * time.Millisecond
+	defer func() { tuneEvery = old }()
+
+	tests := []struct {
+		name string
+		opts func(t *testing.T) []Option
+	}{
+		{"small queue", func(*testing.T) []Option { return []Option{WithQueueSize(4)} }},
+		{"spill", func(t *testing.T) []Option { return []Option{WithQueueSize(4), WithSpill(t.TempDir())} }},
+		{"adaptive queue", func(*testing.T) []Option { return []Option{WithAdaptiveQueue(2, 1024)} }},
+		{"adaptive queue and spill", func(t *testing.T) []Option { return []Option{WithAdaptiveQueue(2, 1024), WithSpill(t.TempDir())} }},
+	}
+	const producers, perProducer = 16, 2000
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &syncBuffer{}
+			l, err := StartLogger(append([]Option{WithOutput(out), WithLevel(DEBUG)}, tt.opts(t)...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var wg sync.WaitGroup
+			for p := 0; p < producers; p++ {
+				wg.Add(1)
+				go func(p int) {
+					defer wg.Done()
+					for i := 0; i < perProducer; i++ {
+						l.Infof("entry p%d-%d.", p, i)
+						if i%500 == 0 {
+							time.Sleep(15 * time.Millisecond) // let the adaptive queue shrink and grow
+						}
+					}
+				}(p)
+			}
+			wg.Wait()
+			l.Shutdown(nil)
+			// make sure the case exercised what it's named after.
+			if l.spill != nil && l.spill.count.Load() == 0 {
+				t.Error("nothing was spilled")
+			}
+			if l.tune != nil && !strings.Contains(out.String(), "Queue resized") {
+				t.Error("the queue was never resized")
+			}
+
+			seen := map[string]int{}
+			for _, m := range entryRe.FindAllStringSubmatch(out.String(), -1) {
+				seen[m[1]]++
+			}
+			var missing int
+			for p := 0; p < producers; p++ {
+				for i := 0; i < perProducer; i++ {
+					key := fmt.Sprintf("p%d-%d", p, i)
+					switch seen[key] {
+					case 1:
+					case 0:
+						missing++
+					default:
+						t.Errorf("%s written %d times", key, seen[key])
+					}
+				}
+			}
+			if missing > 0 {
+				t.Errorf("%d of %d entries missing after Shutdown", missing, producers*perProducer)
+			}
+		})
+	}
+}
+
+// critical flushes the queue from the caller's goroutine while the mediator
+// may be resizing it; run with -race.
+func TestFlushWhileResizing(t *testing.T) {
+	old := tuneEvery
+	tuneEvery = time.Millisecond
+	defer func() { tuneEvery = old }()
+
+	var out syncBuffer
+	l, err := StartLogger(WithOutput(&out), WithAdaptiveQueue(2, 1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for p := 0; p < 4; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				l.Info("entry")
+				if i%200 == 0 {
+					time.Sleep(3 * time.Millisecond)
+				}
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		l.flush()
+		time.Sleep(time.Millisecond)
+	}
+	wg.Wait()
+	l.Shutdown(nil)
+	if n := strings.Count(out.String(), "entry"); n != 8000 {
+		t.Errorf("%d entries written, want 8000", n)
+	}
+}
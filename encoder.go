@@ -0,0 +1,372 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// Record is a log entry as handed to an Encoder.
+type Record struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Caller  string // "file.go:12" of the call that logged it, set with WithCaller
+	Attrs   []Attr // fields added with With, groups nested as []Attr values
+
+	jsonAt int    // where the JSON of InfoJSON and DebugJSON begins in Message, or 0
+	style  *style // of the logger writing it; nil is defaultStyle
+}
+
+// Encoder writes records to a sink in some format. color is true when the
+// sink is a terminal; formats meant for machines ignore it.
+type Encoder interface {
+	Encode(buf *bytes.Buffer, r Record, color bool)
+}
+
+// TextEncoder writes "time:LEVEL:caller: message" lines, with the level, and
+// the JSON of InfoJSON and DebugJSON, in color on terminals. It's the default.
+type TextEncoder struct{}
+
+func (TextEncoder) Encode(buf *bytes.Buffer, r Record, color bool) {
+	st := r.style
+	if st == nil {
+		st = defaultStyle
+	}
+	buf.WriteString(helpers.FormatTime(r.Time, st.timeFormat))
+	buf.WriteByte(':')
+	level := r.Level.String() + ":"
+	if color {
+		level = colorWrap(st.color(r.Level), level)
+	}
+	buf.WriteString(level)
+	if r.Caller != "" {
+		buf.WriteString(r.Caller + ":")
+	}
+	buf.WriteByte(' ')
+	if color && r.jsonAt > 0 {
+		buf.WriteString(r.Message[:r.jsonAt])
+		buf.WriteString(helpers.HighlightJSON(r.Message[r.jsonAt:]))
+	} else {
+		buf.WriteString(r.Message)
+	}
+	walkAttrs("", r.Attrs, func(key string, v any) {
+		buf.WriteString(" " + key + "=")
+		writeLogfmtValue(buf, attrString(v))
+	})
+	buf.WriteByte('\n')
+}
+
+// JSONEncoder writes one JSON object per line, as described by
+// EntryJSONSchema:
+// {"v":2,"time":"2006-01-02T15:04:05.999999999Z07:00","level":"info","msg":"..."}
+type JSONEncoder struct {
+	// Schema is the entry format version written; zero means
+	// EntrySchemaVersion. 1 leaves "v" out, for parsers that predate it.
+	Schema int
+}
+
+func (e JSONEncoder) Encode(buf *bytes.Buffer, r Record, _ bool) {
+	writeSchemaVersion(buf, e.Schema)
+	buf.WriteString(`"time":"`)
+	buf.WriteString(r.Time.Format(time.RFC3339Nano))
+	buf.WriteString(`","level":"`)
+	buf.WriteString(r.Level.name())
+	buf.WriteString(`","msg":`)
+	writeJSONString(buf, r.Message)
+	if r.Caller != "" {
+		buf.WriteString(`,"caller":`)
+		writeJSONString(buf, r.Caller)
+	}
+	for _, a := range r.Attrs {
+		writeJSONAttr(buf, a)
+	}
+	buf.WriteString("}\n")
+}
+
+// DockerEncoder writes JSON lines the way log collectors reading Docker's
+// json-file logs expect them: one escaped object per line, times in UTC, and
+// the stream the line was written to.
+// {"v":2,"time":"2006-01-02T15:04:05.999999999Z","stream":"stderr","level":"info","msg":"..."}
+type DockerEncoder struct {
+	Stream string // "stdout" or "stderr"; worked out from the output if empty
+	Schema int    // as for JSONEncoder
+}
+
+func (d DockerEncoder) Encode(buf *bytes.Buffer, r Record, _ bool) {
+	writeSchemaVersion(buf, d.Schema)
+	buf.WriteString(`"time":"`)
+	buf.WriteString(r.Time.UTC().Format(time.RFC3339Nano))
+	buf.WriteByte('"')
+	if d.Stream != "" {
+		buf.WriteString(`,"stream":`)
+		writeJSONString(buf, d.Stream)
+	}
+	buf.WriteString(`,"level":"`)
+	buf.WriteString(r.Level.name())
+	buf.WriteString(`","msg":`)
+	writeJSONString(buf, r.Message)
+	if r.Caller != "" {
+		buf.WriteString(`,"caller":`)
+		writeJSONString(buf, r.Caller)
+	}
+	for _, a := range r.Attrs {
+		writeJSONAttr(buf, a)
+	}
+	buf.WriteString("}\n")
+}
+
+// writeSchemaVersion opens a JSON entry, with its "v" field unless schema
+// is 1.
+func writeSchemaVersion(buf *bytes.Buffer, schema int) {
+	if schema == 1 {
+		buf.WriteByte('{')
+		return
+	}
+	buf.WriteString(`{"v":`)
+	buf.WriteString(strconv.Itoa(EntrySchemaVersion))
+	buf.WriteByte(',')
+}
+
+// writeJSONAttr writes ,"key":value, with groups as nested objects. Empty
+// groups are left out.
+func writeJSONAttr(buf *bytes.Buffer, a Attr) {
+	if g, ok := a.Value.([]Attr); ok && len(g) == 0 {
+		return
+	}
+	buf.WriteByte(',')
+	writeJSONString(buf, a.Key)
+	buf.WriteByte(':')
+	switch v := a.Value.(type) {
+	case []Attr:
+		buf.WriteByte('{')
+		n := buf.Len()
+		for _, a := range v {
+			writeJSONAttr(buf, a)
+		}
+		// drop the comma before the group's first field.
+		if buf.Len() > n {
+			b := buf.Bytes()
+			copy(b[n:], b[n+1:])
+			buf.Truncate(buf.Len() - 1)
+		}
+		buf.WriteByte('}')
+	case json.Number:
+		if json.Valid([]byte(v)) && v != "" && (v[0] == '-' || v[0] >= '0' && v[0] <= '9') {
+			buf.WriteString(string(v))
+			return
+		}
+		writeJSONString(buf, string(v))
+	case error, fmt.Stringer, time.Time:
+		writeJSONString(buf, attrString(v))
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			writeJSONString(buf, attrString(v))
+			return
+		}
+		buf.Write(b)
+	}
+}
+
+// LogfmtEncoder writes key=value lines: time=... level=info msg="..."
+type LogfmtEncoder struct{}
+
+func (LogfmtEncoder) Encode(buf *bytes.Buffer, r Record, _ bool) {
+	buf.WriteString("time=")
+	buf.WriteString(r.Time.Format(time.RFC3339Nano))
+	buf.WriteString(" level=")
+	buf.WriteString(r.Level.name())
+	buf.WriteString(" msg=")
+	writeLogfmtValue(buf, r.Message)
+	if r.Caller != "" {
+		buf.WriteString(" caller=")
+		writeLogfmtValue(buf, r.Caller)
+	}
+	walkAttrs("", r.Attrs, func(key string, v any) {
+		buf.WriteString(" " + key + "=")
+		writeLogfmtValue(buf, attrString(v))
+	})
+	buf.WriteByte('\n')
+}
+
+// walkAttrs calls fn for every field in attrs, with the keys of groups
+// joined by dots: billing.plan.
+func walkAttrs(prefix string, attrs []Attr, fn func(key string, v any)) {
+	for _, a := range attrs {
+		if g, ok := a.Value.([]Attr); ok {
+			walkAttrs(prefix+a.Key+".", g, fn)
+			continue
+		}
+		fn(prefix+a.Key, a.Value)
+	}
+}
+
+// attrString formats a field value as text.
+func attrString(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case error:
+		return t.Error()
+	case time.Time:
+		return t.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+// writeJSONString writes s as a JSON string, leaving <, > and & as they are.
+func writeJSONString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	buf.Truncate(buf.Len() - 1) // Encode adds a newline
+}
+
+// writeLogfmtValue writes s, quoted if it's empty or has spaces, quotes,
+// equals signs or control characters.
+func writeLogfmtValue(buf *bytes.Buffer, s string) {
+	if s != "" && !strings.ContainsFunc(s, logfmtSpecial) {
+		buf.WriteString(s)
+		return
+	}
+	buf.WriteString(strconv.Quote(s))
+}
+
+// logfmtSpecial reports whether r needs a logfmt value quoted.
+func logfmtSpecial(r rune) bool {
+	return r <= ' ' || r == '"' || r == '=' || r == 0x7f
+}
+
+// sink is one output of the logger and the format written to it. The
+// format can be changed while the logger runs, see ApplyConfig.
+type sink struct {
+	w    *swapWriter
+	errw atomic.Pointer[swapWriter] // if set, WARNING and above are written here instead of w
+	enc  atomic.Value               // encoderBox
+}
+
+// encoderBox gives every stored encoder the same type, as atomic.Value needs.
+type encoderBox struct{ Encoder }
+
+func newSink(w *swapWriter, enc Encoder) *sink {
+	s := &sink{w: w}
+	s.setEncoder(enc)
+	return s
+}
+
+// writer returns where entries of level lv go.
+func (s *sink) writer(lv Level) *swapWriter {
+	if errw := s.errw.Load(); errw != nil && lv.severity() >= WARNING.severity() {
+		return errw
+	}
+	return s.w
+}
+
+// encoder returns the encoder for entries written to w.
+func (s *sink) encoder(w *swapWriter) Encoder {
+	enc := s.enc.Load().(encoderBox).Encoder
+	if d, ok := enc.(DockerEncoder); ok && d.Stream == "" {
+		d.Stream = w.stream()
+		return d
+	}
+	return enc
+}
+
+func (s *sink) setEncoder(enc Encoder) {
+	s.enc.Store(encoderBox{enc})
+}
+
+// encoders are the formats selectable by name, see RegisterEncoder.
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]func() Encoder{
+		"text":    func() Encoder { return TextEncoder{} },
+		"console": func() Encoder { return TextEncoder{} },
+		"json":    func() Encoder { return JSONEncoder{} },
+		"json-v1": func() Encoder { return JSONEncoder{Schema: 1} },
+		"logfmt":  func() Encoder { return LogfmtEncoder{} },
+		"docker":  func() Encoder { return DockerEncoder{} },
+		"cbor":    func() Encoder { return CBOREncoder{} },
+	}
+	encoderTypes = map[reflect.Type]string{} // names of registered encoders, for the audit log
+)
+
+// RegisterEncoder makes the encoders returned by newEncoder selectable as
+// format name, ignoring case, in WithFormat and the config file. It's for
+// formats of your own, registered before the logger starts, typically in
+// an init function:
+//
+//	func init() {
+//		logger.RegisterEncoder("acme", func() logger.Encoder { return acmeEncoder{} })
+//	}
+//
+// It returns an error if name is empty or already taken, built-in formats
+// included.
+func RegisterEncoder(name string, newEncoder func() Encoder) error {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if key == "" {
+		return errors.New("register encoder: empty name")
+	}
+	if newEncoder == nil {
+		return fmt.Errorf("register encoder %q: constructor is nil", name)
+	}
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	if _, ok := encoders[key]; ok {
+		return fmt.Errorf("register encoder %q: format already exists", name)
+	}
+	encoders[key] = newEncoder
+	if enc := newEncoder(); enc != nil {
+		encoderTypes[reflect.TypeOf(enc)] = key
+	}
+	return nil
+}
+
+// encoderByName returns the encoder for a format name: text, json, logfmt,
+// docker, cbor or one added with RegisterEncoder. json-v1 is JSON in the
+// format before versioning.
+func encoderByName(name string) (Encoder, error) {
+	encodersMu.RLock()
+	newEncoder, ok := encoders[strings.ToLower(strings.TrimSpace(name))]
+	encodersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q", name)
+	}
+	enc := newEncoder()
+	if enc == nil {
+		return nil, fmt.Errorf("format %q: encoder is nil", name)
+	}
+	return enc, nil
+}
+
+// caller returns "file.go:line" of the first call on the stack from outside
+// this package.
+func caller() string {
+	var pcs [16]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, pkgPath+".") {
+			return fmt.Sprintf("%s:%d", path.Base(f.File), f.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// pkgPath is this package's import path, for telling its frames apart.
+const pkgPath = "github.com/jeanhaley32/logger"
@@ -0,0 +1,117 @@
+package logger
+
+import "errors"
+
+// CodedError is an error with a code and fields, made with E. When an error is
+// logged, the code and fields of every CodedError in its chain are added to the
+// entry, so the place that builds the error decides what gets recorded.
+// CodedErrors compare equal with errors.Is when their codes are equal.
+// Example:
+// var ErrNoQuota = E("no_quota", "quota exceeded")
+// return ErrNoQuota.With(Field("tenant", id)).Wrap(err)
+// ...
+// l.Error(err) // quota exceeded: ... code=no_quota tenant=t1
+// errors.Is(err, ErrNoQuota) // true
+type CodedError struct {
+	Code     string
+	Msg      string
+	Severity Level  // how bad it is, used by LogError; E sets ERROR, a literal must set it or it's DEBUG
+	Fields   []Attr // added to the entry when it's logged
+	Err      error  // the cause, if any
+}
+
+// E returns a CodedError with the given code, message and fields, and
+// Severity ERROR.
+func E(code, msg string, fields ...Attr) *CodedError {
+	return &CodedError{Code: code, Msg: msg, Severity: ERROR, Fields: fields}
+}
+
+func (e *CodedError) Error() string {
+	if e.Err == nil {
+		return e.Msg
+	}
+	return e.Msg + ": " + e.Err.Error()
+}
+
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is a CodedError with the same code.
+func (e *CodedError) Is(target error) bool {
+	t, ok := target.(*CodedError)
+	return ok && t.Code == e.Code
+}
+
+// Wrap returns a copy of e caused by err.
+func (e *CodedError) Wrap(err error) *CodedError {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// With returns a copy of e with fields added.
+func (e *CodedError) With(fields ...Attr) *CodedError {
+	c := *e
+	c.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], fields...)
+	return &c
+}
+
+// WithSeverity returns a copy of e with the given severity.
+func (e *CodedError) WithSeverity(lv Level) *CodedError {
+	c := *e
+	c.Severity = lv
+	return &c
+}
+
+// severityOf returns the Severity of the first CodedError in err's chain, or
+// ERROR.
+func severityOf(err error) Level {
+	var e *CodedError
+	if errors.As(err, &e) && e.Severity.severity() >= 0 {
+		return e.Severity
+	}
+	return ERROR
+}
+
+// errorAttrs returns attrs with the code and fields of the CodedErrors in v's
+// chain added, if v is an error that has any.
+func errorAttrs(v any, attrs []Attr) []Attr {
+	err, ok := v.(error)
+	if !ok {
+		return attrs
+	}
+	var e *CodedError
+	if !errors.As(err, &e) {
+		return attrs
+	}
+	out := append(attrs[:len(attrs):len(attrs)], Field("code", e.Code))
+	for {
+		out = append(out, e.Fields...)
+		if !errors.As(e.Err, &e) {
+			return out
+		}
+	}
+}
+
+// LogError logs err at its severity: that of the first CodedError in its chain,
+// or ERROR. CRITICAL errors are logged at ERROR, since only Critical shuts
+// down.
+func (l *Mylogger) LogError(err error) {
+	lv := severityOf(err)
+	if lv == CRITICAL {
+		lv = ERROR
+	}
+	if l.enabled(lv) {
+		l.send(entry{level: lv, value: err})
+	}
+}
+
+// LogError logs err at its severity, see Mylogger.LogError.
+func (s *Scope) LogError(err error) {
+	lv := severityOf(err)
+	if lv == CRITICAL {
+		lv = ERROR
+	}
+	s.log(entry{level: lv, value: err})
+}
@@ -0,0 +1,30 @@
+// Command basic shows the logger in a short lived program: start it, log at
+// each level, time some work, and shut down.
+package main
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jeanhaley32/logger"
+)
+
+func main() {
+	l, err := logger.StartLogger(logger.WithVerbose())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer l.Shutdown(nil)
+
+	l.Debug("debug details")
+	l.Info("starting up")
+	l.Warning("disk is 80% full")
+	l.Error(errors.New("could not reach the cache, continuing without it"))
+
+	t := l.Timer("work", 50*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	t.Stop() // logged at WARNING, it took longer than 50ms
+
+	l.InfoJSON("config", map[string]any{"workers": 4, "debug": true})
+}
@@ -0,0 +1,54 @@
+// Command server shows the logger in a long running program: an HTTP server
+// tracked as a named routine, a heartbeat with metrics, and a graceful
+// shutdown on SIGINT or SIGTERM.
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/jeanhaley32/logger"
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+func main() {
+	l, err := logger.StartLogger()
+	if err != nil {
+		log.Fatal(err)
+	}
+	l.SetShutdownWatchdog(10 * time.Second)
+
+	requests := helpers.NewCounter("requests_total", helpers.Labels{"route": "/"})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		requests.Inc()
+		w.Write([]byte("hello\n"))
+	})
+	mux.Handle("/metrics", helpers.DefaultRegistry.PrometheusHandler())
+
+	ln, err := helpers.ListenWithRetry("localhost:8080")
+	if err != nil {
+		l.Critical(err)
+	}
+	srv := &http.Server{Handler: mux}
+	l.Go("http", func() {
+		if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+			l.Error(err)
+		}
+	})
+	// stop the server once the logger starts shutting down.
+	l.Go("http shutdown", func() {
+		<-l.Stopping()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	})
+	go l.Heartbeat(context.Background(), time.Minute, logger.WithMemStats())
+
+	// the logger shuts down on SIGINT or SIGTERM; wait for it to finish.
+	<-l.Stopping()
+	l.Shutdown(nil)
+}
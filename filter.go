@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"sync/atomic"
+
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// filter drops entries at or below level whose record matches.
+type filter struct {
+	level Level
+	match func(Record) bool
+}
+
+// filters is the list of filters, replaced as a whole on change so the
+// mediator reads it without locking.
+type filters struct {
+	list atomic.Pointer[[]filter]
+}
+
+// AddFilter drops entries at level or less severe that match, e.g. the
+// access log lines of health checks:
+//
+//	l.AddFilter(INFO, `GET /healthz`)
+//
+// match is a regular expression, as a string or *regexp.Regexp, tested
+// against the message, or a func(Record) bool. Filters run when the entry
+// is written, not in the caller. CRITICAL entries are never dropped. A func
+// that panics is reported to the error handler and counts as no match; the
+// same goes for rules, alerts and watches.
+func (l *Mylogger) AddFilter(level Level, match any) error {
+	if level.severity() < 0 {
+		return fmt.Errorf("add filter: unknown level %v", level)
+	}
+	fn, err := matcher(match)
+	if err != nil {
+		return fmt.Errorf("add filter: %w", err)
+	}
+	for {
+		old := l.filters.list.Load()
+		var next []filter
+		if old != nil {
+			next = append(next, *old...)
+		}
+		next = append(next, filter{level: level, match: fn})
+		if l.filters.list.CompareAndSwap(old, &next) {
+			return nil
+		}
+	}
+}
+
+// matcher turns a regular expression, as a string or *regexp.Regexp, or a
+// func(Record) bool into a test of records.
+func matcher(match any) (func(Record) bool, error) {
+	switch m := match.(type) {
+	case string:
+		re, err := regexp.Compile(m)
+		if err != nil {
+			return nil, err
+		}
+		return func(r Record) bool { return re.MatchString(r.Message) }, nil
+	case *regexp.Regexp:
+		return func(r Record) bool { return m.MatchString(r.Message) }, nil
+	case func(Record) bool:
+		return m, nil
+	}
+	return nil, fmt.Errorf("match is a %T, want a regexp or func(Record) bool", match)
+}
+
+// matches runs match on r for a filter, rule, alert or watch, named by what.
+// A match that panics is reported and taken as no match, so a buggy
+// func(Record) bool costs its own check rather than the mediator.
+func (l *Mylogger) matches(what string, match func(Record) bool, r Record) bool {
+	var ok bool
+	if err := helpers.Safe(func() error {
+		ok = match(r)
+		return nil
+	}); err != nil {
+		l.reportError(fmt.Errorf("%s match: %w", what, err))
+		return false
+	}
+	return ok
+}
+
+// ClearFilters removes every filter added with AddFilter.
+func (l *Mylogger) ClearFilters() {
+	l.filters.list.Store(nil)
+}
+
+// dropped reports whether a filter drops r.
+func (f *filters) dropped(l *Mylogger, r Record) bool {
+	list := f.list.Load()
+	if list == nil || r.Level == CRITICAL {
+		return false
+	}
+	for _, flt := range *list {
+		if r.Level.severity() <= flt.level.severity() && l.matches("filter", flt.match, r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPanickingMatchersAreReported(t *testing.T) {
+	var out bytes.Buffer
+	l, err := StartLogger(WithOutput(&out), WithEncoder(TextEncoder{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var reported []string
+	l.SetErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, err.Error())
+	})
+	boom := func(Record) bool { panic("boom") }
+	if err := l.AddFilter(INFO, boom); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.AddRule(INFO, boom, DEBUG); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.AddAlert(AlertRule{Name: "db", Match: boom, Level: ERROR, Threshold: 1, Window: time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Watch(boom); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("still written")
+	l.Error("also written")
+	l.Shutdown(nil)
+
+	for _, msg := range []string{"still written", "also written"} {
+		if !strings.Contains(out.String(), msg) {
+			t.Errorf("output lacks %q:\n%s", msg, out.String())
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, what := range []string{"filter match", "rule match", "alert db match", "watch match"} {
+		found := false
+		for _, r := range reported {
+			found = found || (strings.HasPrefix(r, what) && strings.Contains(r, "boom"))
+		}
+		if !found {
+			t.Errorf("no %q panic reported in %q", what, reported)
+		}
+	}
+}
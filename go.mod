@@ -1,3 +1,23 @@
 module github.com/jeanhaley32/logger
 
-go 1.21.5
+go 1.22
+
+require (
+	github.com/BurntSushi/toml v1.3.2
+	github.com/eclipse/paho.mqtt.golang v1.4.3
+	github.com/fxamacker/cbor/v2 v2.7.0
+	github.com/klauspost/compress v1.18.0
+	github.com/nats-io/nats.go v1.37.0
+	golang.org/x/sys v0.16.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/gorilla/websocket v1.5.1 // indirect
+	github.com/nats-io/nkeys v0.4.7 // indirect
+	github.com/nats-io/nuid v1.0.1 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	golang.org/x/crypto v0.18.0 // indirect
+	golang.org/x/net v0.20.0 // indirect
+	golang.org/x/sync v0.6.0 // indirect
+)
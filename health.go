@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// healthState is what the logger keeps track of for Health.
+type healthState struct {
+	lastCritical atomic.Int64 // unix nanoseconds of the last CRITICAL entry written
+	fullSince    atomic.Int64 // unix nanoseconds the queue has been backed up since, or 0
+}
+
+// Health turns the logger's state into health checks for an orchestrator,
+// see Mylogger.Health.
+type Health struct {
+	l *Mylogger
+	// CriticalWindow is how long a CRITICAL entry makes the program
+	// unhealthy for.
+	CriticalWindow time.Duration
+	// MaxBacklog is how long the queue may stay backed up, from the first
+	// time a log call found it full until the mediator empties it, before
+	// the program isn't ready.
+	MaxBacklog time.Duration
+}
+
+// Health returns health checks based on the logger's state, with a
+// CriticalWindow of 5 minutes and a MaxBacklog of 10 seconds.
+// Example:
+// h := l.Health()
+// mux.Handle("/healthz", h.Liveness())
+// mux.Handle("/readyz", h.Readiness())
+func (l *Mylogger) Health() *Health {
+	return &Health{l: l, CriticalWindow: 5 * time.Minute, MaxBacklog: 10 * time.Second}
+}
+
+// Live returns why the program isn't live: a CRITICAL entry was written
+// within CriticalWindow. It's empty if it's live.
+func (h *Health) Live() []string {
+	var problems []string
+	if at := h.l.health.lastCritical.Load(); at != 0 {
+		if ago := time.Since(time.Unix(0, at)); ago < h.CriticalWindow {
+			problems = append(problems, fmt.Sprintf("CRITICAL logged %s ago", helpers.Duration(ago)))
+		}
+	}
+	return problems
+}
+
+// Ready returns why the program isn't ready: it isn't live, an output is
+// failing, or the queue has been backed up longer than MaxBacklog. It's
+// empty if it's ready.
+func (h *Health) Ready() []string {
+	problems := h.Live()
+	for i, s := range h.l.sinks {
+		for _, w := range []*swapWriter{s.w, s.errw.Load()} {
+			if w != nil && w.failing.Load() {
+				problems = append(problems, fmt.Sprintf("output %d (%s) is failing", i, w.name()))
+			}
+		}
+	}
+	if since := h.l.health.fullSince.Load(); since != 0 {
+		if d := time.Since(time.Unix(0, since)); d > h.MaxBacklog {
+			problems = append(problems, fmt.Sprintf("log queue backed up for %s", helpers.Duration(d)))
+		}
+	}
+	return problems
+}
+
+// Liveness returns a handler for /healthz: 200 if Live finds nothing wrong,
+// 503 with the problems one per line otherwise.
+func (h *Health) Liveness() http.Handler {
+	return healthHandler(h.Live)
+}
+
+// Readiness returns a handler for /readyz, like Liveness but with Ready.
+func (h *Health) Readiness() http.Handler {
+	return healthHandler(h.Ready)
+}
+
+func healthHandler(check func() []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		if problems := check(); len(problems) > 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintln(w, strings.Join(problems, "\n"))
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+}
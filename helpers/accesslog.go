@@ -0,0 +1,54 @@
+package helpers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// AccessLog wraps next so every request is logged once it's been served,
+// with its method, path, status, response size and duration:
+//
+//	GET /static/app.js 200 12.5 KiB in 350µs
+//
+// Responses below 500 are logged at lv and server errors at LevelError. A
+// nil l logs to the registered logger.
+func AccessLog(l Logger, lv Level, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lg := l
+		if lg == nil {
+			lg = log()
+		}
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		at := lv
+		if rec.status >= 500 {
+			at = LevelError
+		}
+		at.of(lg)(fmt.Sprintf("%s %s %d %s in %s", r.Method, r.URL.RequestURI(), rec.status, Bytes(rec.size), Duration(time.Since(start))))
+	})
+}
+
+// statusRecorder remembers the status and size of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(p []byte) (int, error) {
+	n, err := s.ResponseWriter.Write(p)
+	s.size += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
@@ -0,0 +1,115 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// BackoffKind is how a Backoff grows between attempts.
+type BackoffKind int
+
+const (
+	Exponential BackoffKind = iota // Initial, Initial*Multiplier, Initial*Multiplier², ...
+	Linear                         // Initial, 2*Initial, 3*Initial, ...
+	Constant                       // Initial every time
+)
+
+// Stop is returned by Backoff.Next once MaxElapsed has passed or MaxAttempts
+// waits have been given.
+const Stop time.Duration = -1
+
+// Backoff produces the waits between retries. It's an iterator: call Next
+// before each retry and Reset after a success. It is not safe for concurrent
+// use; give every retry loop its own.
+type Backoff struct {
+	Kind        BackoffKind
+	Initial     time.Duration // first wait
+	Max         time.Duration // cap on a single wait, 0 for none
+	Multiplier  float64       // growth factor for Exponential, 2 if unset
+	Jitter      float64       // fraction of each wait randomly taken off, 0 to 1
+	MaxElapsed  time.Duration // Next returns Stop after this long, 0 for never
+	MaxAttempts int           // Next returns Stop after this many waits, 0 for no limit
+
+	attempt int
+	start   time.Time
+}
+
+// NewBackoff returns an exponential backoff from initial up to max, with
+// half of each wait randomized.
+func NewBackoff(initial, max time.Duration) *Backoff {
+	return &Backoff{Kind: Exponential, Initial: initial, Max: max, Jitter: 0.5}
+}
+
+// Next returns how long to wait before the next attempt, or Stop if
+// MaxElapsed has passed since the first call or MaxAttempts waits have
+// been returned.
+func (b *Backoff) Next() time.Duration {
+	if b.start.IsZero() {
+		b.start = time.Now()
+	}
+	if b.MaxElapsed > 0 && time.Since(b.start) >= b.MaxElapsed {
+		return Stop
+	}
+	if b.MaxAttempts > 0 && b.attempt >= b.MaxAttempts {
+		return Stop
+	}
+	var d time.Duration
+	switch b.Kind {
+	case Constant:
+		d = b.Initial
+	case Linear:
+		d = b.Initial * time.Duration(b.attempt+1)
+	default:
+		mult := b.Multiplier
+		if mult <= 1 {
+			mult = 2
+		}
+		f := float64(b.Initial)
+		for i := 0; i < b.attempt && (b.Max <= 0 || f < float64(b.Max)); i++ {
+			f *= mult
+		}
+		d = time.Duration(f)
+	}
+	b.attempt++
+	if b.Max > 0 && d > b.Max {
+		d = b.Max
+	}
+	if j := min(max(b.Jitter, 0), 1); j > 0 && d > 0 {
+		d -= time.Duration(rand.Int63n(int64(float64(d)*j) + 1))
+	}
+	return d
+}
+
+// Reset starts the sequence over.
+func (b *Backoff) Reset() {
+	b.attempt = 0
+	b.start = time.Time{}
+}
+
+// Attempts returns how many times Next has been called since the last Reset.
+func (b *Backoff) Attempts() int {
+	return b.attempt
+}
+
+// Retry calls fn until it succeeds, waiting between attempts as b says. It
+// gives up when ctx is done or b returns Stop, returning fn's last error.
+func Retry(ctx context.Context, b *Backoff, fn func(context.Context) error) error {
+	b.Reset()
+	for calls := 1; ; calls++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		wait := b.Next()
+		if wait == Stop {
+			return fmt.Errorf("gave up after %d attempts: %w", calls, err)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-time.After(wait):
+		}
+	}
+}
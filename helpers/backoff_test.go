@@ -0,0 +1,134 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoffSequence(t *testing.T) {
+	tests := []struct {
+		name string
+		b    Backoff
+		want []time.Duration
+	}{
+		{"exponential", Backoff{Kind: Exponential, Initial: 10 * time.Millisecond}, []time.Duration{10, 20, 40, 80}},
+		{"multiplier", Backoff{Kind: Exponential, Initial: 10 * time.Millisecond, Multiplier: 3}, []time.Duration{10, 30, 90, 270}},
+		{"linear", Backoff{Kind: Linear, Initial: 10 * time.Millisecond}, []time.Duration{10, 20, 30, 40}},
+		{"constant", Backoff{Kind: Constant, Initial: 10 * time.Millisecond}, []time.Duration{10, 10, 10, 10}},
+		{"capped", Backoff{Kind: Exponential, Initial: 10 * time.Millisecond, Max: 50 * time.Millisecond}, []time.Duration{10, 20, 40, 50, 50}},
+		{"linear capped", Backoff{Kind: Linear, Initial: 20 * time.Millisecond, Max: 50 * time.Millisecond}, []time.Duration{20, 40, 50, 50}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, want := range tt.want {
+				if got := tt.b.Next(); got != want*time.Millisecond {
+					t.Fatalf("wait %d = %s, want %s", i+1, got, want*time.Millisecond)
+				}
+			}
+			tt.b.Reset()
+			if got := tt.b.Next(); got != tt.want[0]*time.Millisecond {
+				t.Errorf("first wait after Reset = %s, want %s", got, tt.want[0]*time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestBackoffMaxCapsLongSequences(t *testing.T) {
+	b := Backoff{Kind: Exponential, Initial: time.Second, Max: time.Minute}
+	for i := 0; i < 200; i++ {
+		if d := b.Next(); d <= 0 || d > time.Minute {
+			t.Fatalf("wait %d = %s, want within (0, 1m]", i+1, d)
+		}
+	}
+}
+
+func TestBackoffJitterBounds(t *testing.T) {
+	for _, jitter := range []float64{0.25, 0.5, 1, 2} {
+		b := Backoff{Kind: Constant, Initial: 100 * time.Millisecond, Jitter: jitter}
+		lo := time.Duration(float64(100*time.Millisecond) * (1 - min(jitter, 1)))
+		varied := false
+		for i := 0; i < 1000; i++ {
+			d := b.Next()
+			if d < lo || d > 100*time.Millisecond {
+				t.Fatalf("jitter %v: wait %s outside [%s, 100ms]", jitter, d, lo)
+			}
+			varied = varied || d != 100*time.Millisecond
+		}
+		if !varied {
+			t.Errorf("jitter %v: every wait was the full 100ms", jitter)
+		}
+	}
+}
+
+func TestBackoffMaxAttempts(t *testing.T) {
+	b := Backoff{Kind: Constant, Initial: time.Millisecond, MaxAttempts: 3}
+	for i := 0; i < 3; i++ {
+		if d := b.Next(); d == Stop {
+			t.Fatalf("wait %d = Stop, want a wait", i+1)
+		}
+	}
+	if d := b.Next(); d != Stop {
+		t.Fatalf("wait 4 = %s, want Stop", d)
+	}
+	if b.Attempts() != 3 {
+		t.Errorf("Attempts() = %d, want 3", b.Attempts())
+	}
+}
+
+func TestBackoffMaxElapsed(t *testing.T) {
+	b := Backoff{Kind: Constant, Initial: time.Millisecond, MaxElapsed: 20 * time.Millisecond}
+	if d := b.Next(); d == Stop {
+		t.Fatal("first wait = Stop")
+	}
+	time.Sleep(25 * time.Millisecond)
+	if d := b.Next(); d != Stop {
+		t.Fatalf("wait after MaxElapsed = %s, want Stop", d)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	errFail := errors.New("fail")
+	t.Run("succeeds", func(t *testing.T) {
+		calls := 0
+		err := Retry(context.Background(), &Backoff{Kind: Constant, Initial: time.Millisecond}, func(context.Context) error {
+			if calls++; calls < 3 {
+				return errFail
+			}
+			return nil
+		})
+		if err != nil || calls != 3 {
+			t.Fatalf("Retry = %v after %d calls, want nil after 3", err, calls)
+		}
+	})
+	t.Run("attempt limit", func(t *testing.T) {
+		calls := 0
+		err := Retry(context.Background(), &Backoff{Kind: Constant, Initial: time.Millisecond, MaxAttempts: 2}, func(context.Context) error {
+			calls++
+			return errFail
+		})
+		if !errors.Is(err, errFail) || calls != 3 {
+			t.Fatalf("Retry = %v after %d calls, want fail after 3", err, calls)
+		}
+	})
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		start := time.Now()
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+		}()
+		err := Retry(ctx, &Backoff{Kind: Constant, Initial: time.Hour}, func(context.Context) error {
+			calls++
+			return errFail
+		})
+		if !errors.Is(err, context.Canceled) || calls != 1 {
+			t.Fatalf("Retry = %v after %d calls, want context.Canceled after 1", err, calls)
+		}
+		if d := time.Since(start); d > time.Second {
+			t.Errorf("Retry took %s to notice the cancellation", d)
+		}
+	})
+}
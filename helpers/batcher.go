@@ -0,0 +1,99 @@
+package helpers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Batcher collects items and hands them to a flush function in batches,
+// whenever maxSize items have been added or maxDelay has passed since the
+// first item of the batch, whichever comes first. Flushes happen on a single
+// goroutine, one at a time. Close flushes whatever is left.
+type Batcher[T any] struct {
+	maxSize  int
+	maxDelay time.Duration
+	flush    func([]T) error
+	in       chan T
+	mu       sync.RWMutex // guards closed against Add racing Close
+	closed   bool
+	done     chan struct{}
+}
+
+// NewBatcher starts a Batcher. A maxDelay of 0 or less means no waiting:
+// each item is flushed on its own as soon as it's added. Errors returned by
+// flush are logged through the registered logger; the batch is not retried.
+func NewBatcher[T any](maxSize int, maxDelay time.Duration, flush func([]T) error) *Batcher[T] {
+	if maxSize < 1 || maxDelay <= 0 {
+		maxSize = 1
+	}
+	b := &Batcher[T]{
+		maxSize:  maxSize,
+		maxDelay: maxDelay,
+		flush:    flush,
+		in:       make(chan T, maxSize),
+		done:     make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// Add queues v for the next batch, blocking while a full batch is being
+// flushed. It returns false if the Batcher is closed.
+func (b *Batcher[T]) Add(v T) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return false
+	}
+	b.in <- v
+	return true
+}
+
+// Close stops accepting items, flushes what's pending and waits for the
+// flush to return. It's safe to call more than once.
+func (b *Batcher[T]) Close() {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.in)
+	}
+	b.mu.Unlock()
+	<-b.done
+}
+
+func (b *Batcher[T]) run() {
+	defer close(b.done)
+	batch := make([]T, 0, b.maxSize)
+	timer := time.NewTimer(b.maxDelay)
+	timer.Stop()
+	send := func() {
+		timer.Stop()
+		if len(batch) == 0 {
+			return
+		}
+		err := Safe(func() error { return b.flush(batch) })
+		if err != nil {
+			log().Error(fmt.Sprintf("batcher: flush of %d items failed: %v", len(batch), err))
+		}
+		batch = make([]T, 0, b.maxSize)
+	}
+	for {
+		select {
+		case v, ok := <-b.in:
+			if !ok {
+				send()
+				return
+			}
+			batch = append(batch, v)
+			if len(batch) == 1 && b.maxDelay > 0 {
+				timer.Reset(b.maxDelay)
+			}
+			if len(batch) >= b.maxSize {
+				send()
+			}
+		case <-timer.C:
+			send()
+		}
+	}
+}
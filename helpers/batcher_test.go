@@ -0,0 +1,26 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBatcherZeroDelayFlushesEachItem(t *testing.T) {
+	flushed := make(chan []int, 3)
+	b := NewBatcher(10, 0, func(batch []int) error {
+		flushed <- batch
+		return nil
+	})
+	defer b.Close()
+	for i := 1; i <= 3; i++ {
+		b.Add(i)
+		select {
+		case got := <-flushed:
+			if len(got) != 1 || got[0] != i {
+				t.Errorf("flushed %v, want [%d]", got, i)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("item %d wasn't flushed", i)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package helpers
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/jeanhaley32/logger/colors"
+	"github.com/jeanhaley32/logger/helpers/strs"
+)
+
+// Bind fills the struct pointed to by cfg from, in order of precedence,
+// command line flags, environment variables, a config file and `default`
+// tags. It uses the same tags as LoadEnv and LoadConfig, plus `flag` and
+// `usage` for the command line:
+//
+//	type Config struct {
+//		Port int `json:"port" env:"PORT" flag:"port" default:"8080" usage:"HTTP port"`
+//	}
+//
+// A -config flag selecting the config file is always defined. Fields tagged
+// required must end up non-zero from some source. If cfg implements
+// Validator it is validated last. On -h or -help a colorized usage message is
+// written to stderr and flag.ErrHelp is returned.
+func Bind(name string, cfg any, args []string) error {
+	return bind(name, cfg, args, os.Stderr)
+}
+
+// flagValue records a flag's raw value so it can be applied after the file
+// and environment have been loaded.
+type flagValue struct {
+	field reflect.Value
+	size  bool
+	raw   *string
+}
+
+func (f *flagValue) String() string {
+	if f.raw == nil {
+		return ""
+	}
+	return *f.raw
+}
+
+func (f *flagValue) Set(s string) error {
+	// parse into a scratch value now, so bad input fails at parse time.
+	scratch := reflect.New(f.field.Type()).Elem()
+	if err := setValue(scratch, s, f.size); err != nil {
+		return err
+	}
+	f.raw = &s
+	return nil
+}
+
+func (f *flagValue) IsBoolFlag() bool {
+	return f.field.Kind() == reflect.Bool
+}
+
+// flagHelp is one line of the generated usage message.
+type flagHelp struct {
+	name, typ, usage, env, def string
+}
+
+func bind(name string, cfg any, args []string, out io.Writer) error {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind: expected a pointer to a struct, got %T", cfg)
+	}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configPath := fs.String("config", "", "path to a JSON, YAML or TOML config file")
+	help := []flagHelp{{name: "config", typ: "path", usage: "path to a JSON, YAML or TOML config file"}}
+	var flags []*flagValue
+	walkFields(v.Elem(), func(f reflect.StructField, fv reflect.Value) {
+		fname := f.Tag.Get("flag")
+		if fname == "" {
+			return
+		}
+		env, opts := parseTag(f.Tag.Get("env"))
+		fl := &flagValue{field: fv, size: opts.has("size")}
+		flags = append(flags, fl)
+		fs.Var(fl, fname, f.Tag.Get("usage"))
+		help = append(help, flagHelp{
+			name:  fname,
+			typ:   typeName(fv, fl.size),
+			usage: f.Tag.Get("usage"),
+			env:   env,
+			def:   f.Tag.Get("default"),
+		})
+	})
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			printHelp(out, name, help)
+			return err
+		}
+		return fmt.Errorf("bind: %w", err)
+	}
+
+	if *configPath != "" {
+		data, err := os.ReadFile(*configPath)
+		if err != nil {
+			return fmt.Errorf("bind: %w", err)
+		}
+		if err := decodeConfig(*configPath, data, cfg); err != nil {
+			return fmt.Errorf("bind: config %s: %w", *configPath, err)
+		}
+	} else if err := applyDefaults(v.Elem()); err != nil {
+		return fmt.Errorf("bind: %w", err)
+	}
+	if err := loadEnv(v.Elem(), false); err != nil {
+		return fmt.Errorf("bind: %w", err)
+	}
+	for _, fl := range flags {
+		if fl.raw != nil {
+			if err := setValue(fl.field, *fl.raw, fl.size); err != nil {
+				return fmt.Errorf("bind: %w", err)
+			}
+		}
+	}
+
+	var errs []error
+	walkFields(v.Elem(), func(f reflect.StructField, fv reflect.Value) {
+		if f.Tag.Get("required") == "true" && fv.IsZero() {
+			errs = append(errs, fmt.Errorf("%s is required", describeSources(f)))
+		}
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("bind: %w", errors.Join(errs...))
+	}
+	if err := validate(cfg); err != nil {
+		return fmt.Errorf("bind: %w", err)
+	}
+	return nil
+}
+
+// describeSources names the ways a field can be set, for error messages.
+func describeSources(f reflect.StructField) string {
+	var src []string
+	if fl := f.Tag.Get("flag"); fl != "" {
+		src = append(src, "-"+fl)
+	}
+	if env, _ := parseTag(f.Tag.Get("env")); env != "" {
+		src = append(src, "$"+env)
+	}
+	if len(src) == 0 {
+		return f.Name
+	}
+	return f.Name + " (" + strings.Join(src, " or ") + ")"
+}
+
+// typeName is the placeholder shown for a flag's value in the usage message.
+func typeName(v reflect.Value, size bool) string {
+	if size {
+		return "size"
+	}
+	if v.Type().String() == "time.Duration" {
+		return "duration"
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return ""
+	case reflect.Slice:
+		return "list"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "uint"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	}
+	return "string"
+}
+
+// printHelp writes the colorized usage message.
+func printHelp(w io.Writer, name string, help []flagHelp) {
+	fmt.Fprintf(w, "%s %s [flags]\n\n", colors.Wrap(colors.BLUE, "Usage:"), name)
+	flagCol := make([]string, len(help))
+	width := 0
+	for i, h := range help {
+		flagCol[i] = "  " + colors.Wrap(colors.GREEN, "-"+h.name)
+		if h.typ != "" {
+			flagCol[i] += " " + colors.Wrap(colors.GRAY, h.typ)
+		}
+		width = max(width, strs.VisibleLen(flagCol[i]))
+	}
+	for i, h := range help {
+		line := strs.PadRight(flagCol[i], width+2) + h.usage
+		if h.env != "" {
+			line += " " + colors.Wrap(colors.YELLOW, "[$"+h.env+"]")
+		}
+		if h.def != "" {
+			line += " " + colors.Wrap(colors.GRAY, "(default "+h.def+")")
+		}
+		fmt.Fprintln(w, line)
+	}
+}
@@ -0,0 +1,206 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrBusClosed is returned by Publish once the bus is closed.
+var ErrBusClosed = errors.New("bus closed")
+
+// DropPolicy is what Publish does when a subscriber's buffer is full.
+type DropPolicy int
+
+const (
+	DropNewest DropPolicy = iota // the subscriber misses the new event
+	DropOldest                   // the oldest buffered event is discarded to make room
+	Block                        // Publish waits for room, or for its ctx
+)
+
+// Bus is a typed publish/subscribe event bus, the logger's fan-out applied to
+// application events. Publishers send events to a topic; every subscriber of
+// that topic gets its own buffered copy, so a slow subscriber only affects
+// others under the Block policy. A Bus is safe for concurrent use.
+type Bus[T any] struct {
+	mu        sync.RWMutex
+	subs      map[string][]*Subscription[T]
+	closed    bool
+	done      chan struct{} // closed by Close, releasing blocked publishers
+	closeOnce sync.Once
+}
+
+// Subscription receives a topic's events on C, see Bus.Subscribe.
+type Subscription[T any] struct {
+	C <-chan T
+
+	ch      chan T
+	bus     *Bus[T]
+	topic   string
+	policy  DropPolicy
+	mu      sync.Mutex    // serializes DropOldest, which takes from ch to make room
+	chMu    sync.RWMutex  // held for reading while sending on ch, for writing to close it
+	closed  bool          // ch is closed
+	done    chan struct{} // closed by Unsubscribe, releasing blocked publishers
+	once    sync.Once
+	dropped atomic.Int64
+}
+
+// NewBus returns an empty bus.
+func NewBus[T any]() *Bus[T] {
+	return &Bus[T]{subs: map[string][]*Subscription[T]{}, done: make(chan struct{})}
+}
+
+// Subscribe returns a subscription to topic with room for buffer events.
+// The empty topic receives the events of every topic. C is closed by
+// Unsubscribe or when the bus is closed, after which the events still
+// buffered can be read. A DropOldest subscription has room for at least one
+// event, since it has to hold the newest.
+func (b *Bus[T]) Subscribe(topic string, buffer int, policy DropPolicy) *Subscription[T] {
+	if policy == DropOldest {
+		buffer = max(buffer, 1)
+	}
+	ch := make(chan T, max(buffer, 0))
+	s := &Subscription[T]{C: ch, ch: ch, bus: b, topic: topic, policy: policy, done: make(chan struct{})}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		s.closed = true
+		close(ch)
+		return s
+	}
+	b.subs[topic] = append(b.subs[topic], s)
+	return s
+}
+
+// Publish sends v to the subscribers of topic, and to those of every topic.
+// It returns ErrBusClosed if the bus is closed, or ctx's error if ctx is done
+// while waiting on a Block subscriber; subscribers that didn't get v by then
+// count it as dropped.
+func (b *Bus[T]) Publish(ctx context.Context, topic string, v T) error {
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return ErrBusClosed
+	}
+	// deliver outside the lock, so a blocked publisher doesn't hold up
+	// Subscribe, Unsubscribe and Close.
+	subs := append([]*Subscription[T](nil), b.subs[topic]...)
+	if topic != "" {
+		subs = append(subs, b.subs[""]...)
+	}
+	b.mu.RUnlock()
+	var err error
+	for _, s := range subs {
+		if err != nil {
+			s.dropped.Add(1)
+			continue
+		}
+		err = s.deliver(ctx, v)
+	}
+	return err
+}
+
+// deliver sends v to s according to its policy.
+func (s *Subscription[T]) deliver(ctx context.Context, v T) error {
+	s.chMu.RLock()
+	defer s.chMu.RUnlock()
+	if s.closed {
+		return nil
+	}
+	select {
+	case s.ch <- v:
+		return nil
+	default:
+	}
+	switch s.policy {
+	case DropOldest:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for {
+			select {
+			case s.ch <- v:
+				return nil
+			default:
+			}
+			select {
+			case <-s.ch:
+				s.dropped.Add(1)
+			default:
+			}
+		}
+	case Block:
+		select {
+		case s.ch <- v:
+			return nil
+		case <-s.done:
+		case <-s.bus.done:
+		case <-ctx.Done():
+			s.dropped.Add(1)
+			return ctx.Err()
+		}
+		return nil
+	}
+	s.dropped.Add(1)
+	return nil
+}
+
+// Dropped returns how many events s missed because its buffer was full.
+func (s *Subscription[T]) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Topic returns the topic s is subscribed to.
+func (s *Subscription[T]) Topic() string {
+	return s.topic
+}
+
+// Unsubscribe stops delivery to s and closes C. It's safe to call more than
+// once, and after the bus is closed.
+func (s *Subscription[T]) Unsubscribe() {
+	s.once.Do(func() {
+		// release a publisher blocked on s, which holds chMu.
+		close(s.done)
+		b := s.bus
+		b.mu.Lock()
+		subs := b.subs[s.topic]
+		for i, sub := range subs {
+			if sub == s {
+				b.subs[s.topic] = append(subs[:i:i], subs[i+1:]...)
+				break
+			}
+		}
+		if len(b.subs[s.topic]) == 0 {
+			delete(b.subs, s.topic)
+		}
+		b.mu.Unlock()
+		s.close()
+	})
+}
+
+// close closes ch once no publisher is sending on it.
+func (s *Subscription[T]) close() {
+	s.chMu.Lock()
+	defer s.chMu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+// Close closes the bus: Publish returns ErrBusClosed from then on, blocked
+// publishers are released, and every subscription's C is closed once it's
+// received what was already buffered. It's safe to call more than once.
+func (b *Bus[T]) Close() {
+	b.closeOnce.Do(func() { close(b.done) })
+	b.mu.Lock()
+	subs := b.subs
+	b.closed, b.subs = true, nil
+	b.mu.Unlock()
+	for _, topic := range subs {
+		for _, s := range topic {
+			s.close()
+		}
+	}
+}
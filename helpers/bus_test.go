@@ -0,0 +1,47 @@
+package helpers
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBusWildcardGetsEachEventOnce(t *testing.T) {
+	b := NewBus[int]()
+	defer b.Close()
+	all := b.Subscribe("", 8, DropNewest)
+	orders := b.Subscribe("orders", 8, DropNewest)
+	b.Publish(context.Background(), "", 1)
+	b.Publish(context.Background(), "orders", 2)
+	if n := len(all.C); n != 2 {
+		t.Errorf("wildcard subscriber got %d events, want 2", n)
+	}
+	if n := len(orders.C); n != 1 {
+		t.Errorf("topic subscriber got %d events, want 1", n)
+	}
+}
+
+func TestBusDropOldestUnbuffered(t *testing.T) {
+	b := NewBus[int]()
+	s := b.Subscribe("t", 0, DropOldest)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 1; i <= 3; i++ {
+			b.Publish(context.Background(), "t", i)
+		}
+		s.Unsubscribe()
+		b.Close()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Publish to an unbuffered DropOldest subscriber doesn't return")
+	}
+	if v := <-s.C; v != 3 {
+		t.Errorf("got %d, want the newest event, 3", v)
+	}
+	if n := s.Dropped(); n != 2 {
+		t.Errorf("dropped %d, want 2", n)
+	}
+}
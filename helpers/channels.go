@@ -0,0 +1,140 @@
+package helpers
+
+import (
+	"context"
+	"sync"
+)
+
+// OrDone relays values from in until in is closed or ctx is done, so a range
+// over the result never outlives ctx.
+func OrDone[T any](ctx context.Context, in <-chan T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// Merge fans in: values from every channel in chs are sent on the result,
+// which is closed once all of them are closed or ctx is done.
+func Merge[T any](ctx context.Context, chs ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	wg.Add(len(chs))
+	for _, c := range chs {
+		go func(c <-chan T) {
+			defer wg.Done()
+			for v := range OrDone(ctx, c) {
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(c)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// SplitPolicy decides which output of Split receives a value.
+type SplitPolicy int
+
+const (
+	RoundRobin SplitPolicy = iota // each output in turn
+	FirstFree                     // whichever output is ready first
+	Broadcast                     // every output gets every value
+)
+
+// Split fans out in to n channels according to policy. The outputs are
+// closed once in is closed or ctx is done. A slow reader holds up the others
+// under RoundRobin and Broadcast.
+func Split[T any](ctx context.Context, in <-chan T, n int, policy SplitPolicy) []<-chan T {
+	if n < 1 {
+		n = 1
+	}
+	outs := make([]chan T, n)
+	result := make([]<-chan T, n)
+	for i := range outs {
+		outs[i] = make(chan T)
+		result[i] = outs[i]
+	}
+	// with FirstFree every output reads from the same relay.
+	if policy == FirstFree {
+		src := OrDone(ctx, in)
+		for _, o := range outs {
+			go func(o chan T) {
+				defer close(o)
+				for v := range src {
+					select {
+					case o <- v:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}(o)
+		}
+		return result
+	}
+	go func() {
+		defer func() {
+			for _, o := range outs {
+				close(o)
+			}
+		}()
+		next := 0
+		for v := range OrDone(ctx, in) {
+			targets := outs[next : next+1]
+			if policy == Broadcast {
+				targets = outs
+			} else {
+				next = (next + 1) % n
+			}
+			for _, o := range targets {
+				select {
+				case o <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return result
+}
+
+// Bridge flattens a channel of channels into a single channel, reading each
+// inner channel to the end before moving on to the next.
+func Bridge[T any](ctx context.Context, chans <-chan (<-chan T)) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for c := range OrDone(ctx, chans) {
+			for v := range OrDone(ctx, c) {
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,107 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Validator is implemented by config structs that want to check themselves
+// after LoadConfig has filled them in.
+type Validator interface {
+	Validate() error
+}
+
+// LoadConfig reads the file at path into the struct pointed to by cfg. The
+// format is picked from the extension: .json, .yaml/.yml or .toml.
+//
+// Before parsing, ${VAR} and ${VAR:-fallback} references in the file are
+// replaced with values from the environment. After parsing, fields that are
+// still zero are set from their `default` tag, and if cfg implements
+// Validator its Validate method is called.
+func LoadConfig(path string, cfg any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("load config %s: %w", path, err)
+	}
+	if err := decodeConfig(path, data, cfg); err != nil {
+		return fmt.Errorf("load config %s: %w", path, err)
+	}
+	if err := validate(cfg); err != nil {
+		return fmt.Errorf("load config %s: %w", path, err)
+	}
+	return nil
+}
+
+// validate calls cfg's Validate method if it has one.
+func validate(cfg any) error {
+	if val, ok := cfg.(Validator); ok {
+		if err := val.Validate(); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+	}
+	return nil
+}
+
+// decodeConfig parses data read from path into cfg and applies defaults.
+func decodeConfig(path string, data []byte, cfg any) error {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expected a pointer to a struct, got %T", cfg)
+	}
+	data = interpolateEnv(data)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return err
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return err
+		}
+	case ".toml":
+		if err := toml.Unmarshal(data, cfg); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported config format %q", ext)
+	}
+	return applyDefaults(v.Elem())
+}
+
+// matches ${VAR} and ${VAR:-fallback}
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// interpolateEnv replaces environment variable references in data.
+func interpolateEnv(data []byte) []byte {
+	return envRef.ReplaceAllFunc(data, func(m []byte) []byte {
+		sub := envRef.FindSubmatch(m)
+		if val, ok := os.LookupEnv(string(sub[1])); ok && val != "" {
+			return []byte(val)
+		}
+		return sub[2]
+	})
+}
+
+// applyDefaults sets zero valued fields of v from their `default` tag.
+func applyDefaults(v reflect.Value) error {
+	var err error
+	walkFields(v, func(f reflect.StructField, fv reflect.Value) {
+		def, ok := f.Tag.Lookup("default")
+		if !ok || !fv.IsZero() || err != nil {
+			return
+		}
+		_, opts := parseTag(f.Tag.Get("env"))
+		if serr := setValue(fv, def, opts.has("size")); serr != nil {
+			err = fmt.Errorf("%s: invalid default %q: %w", f.Name, def, serr)
+		}
+	})
+	return err
+}
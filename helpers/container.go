@@ -0,0 +1,45 @@
+package helpers
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	containerOnce sync.Once
+	inContainer   bool
+)
+
+// InContainer reports whether the process runs in a container (Docker,
+// Podman, containerd or Kubernetes), judging by the marker files runtimes
+// leave, their environment variables and the cgroup of PID 1. The answer is
+// worked out once.
+func InContainer() bool {
+	containerOnce.Do(func() {
+		inContainer = detectContainer()
+	})
+	return inContainer
+}
+
+func detectContainer() bool {
+	for _, marker := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(marker); err == nil {
+			return true
+		}
+	}
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" || os.Getenv("container") != "" {
+		return true
+	}
+	b, err := os.ReadFile("/proc/1/cgroup")
+	if err != nil {
+		return false
+	}
+	cg := string(b)
+	for _, runtime := range []string{"docker", "kubepods", "containerd", "libpod", "lxc"} {
+		if strings.Contains(cg, runtime) {
+			return true
+		}
+	}
+	return false
+}
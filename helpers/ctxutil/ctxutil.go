@@ -0,0 +1,100 @@
+// Package ctxutil holds context helpers: timeouts with causes, combining and
+// detaching contexts, and typed keys for values such as request and trace IDs.
+package ctxutil
+
+import (
+	"context"
+	"time"
+)
+
+// WithTimeoutCause is context.WithTimeout, except that context.Cause reports
+// cause instead of context.DeadlineExceeded once the timeout passes.
+func WithTimeoutCause(parent context.Context, d time.Duration, cause error) (context.Context, context.CancelFunc) {
+	return context.WithTimeoutCause(parent, d, cause)
+}
+
+// FirstDone returns a context that is done as soon as any of ctxs is. Its
+// values come from the first context, and its cause is the cause of whichever
+// context finished first. The cancel function must be called to release the
+// watchers.
+func FirstDone(ctxs ...context.Context) (context.Context, context.CancelFunc) {
+	if len(ctxs) == 0 {
+		return context.WithCancel(context.Background())
+	}
+	ctx, cancel := context.WithCancelCause(ctxs[0])
+	stops := make([]func() bool, 0, len(ctxs)-1)
+	for _, other := range ctxs[1:] {
+		other := other
+		stops = append(stops, context.AfterFunc(other, func() {
+			cancel(context.Cause(other))
+		}))
+	}
+	return ctx, func() {
+		for _, stop := range stops {
+			stop()
+		}
+		cancel(context.Canceled)
+	}
+}
+
+// Detach returns a context with ctx's values but none of its cancellation or
+// deadline, for work that must outlive the request that started it, such as
+// flushing logs or writing an audit record.
+func Detach(ctx context.Context) context.Context {
+	return context.WithoutCancel(ctx)
+}
+
+// Key is a typed context key. Using one Key value for both storing and
+// reading avoids collisions and type assertions at every call site.
+type Key[T any] struct {
+	name string
+}
+
+// NewKey returns a key; name is only used for debugging.
+func NewKey[T any](name string) *Key[T] {
+	return &Key[T]{name: name}
+}
+
+// With returns a copy of ctx carrying v under k.
+func (k *Key[T]) With(ctx context.Context, v T) context.Context {
+	return context.WithValue(ctx, k, v)
+}
+
+// Get returns the value stored under k, if any.
+func (k *Key[T]) Get(ctx context.Context) (T, bool) {
+	v, ok := ctx.Value(k).(T)
+	return v, ok
+}
+
+func (k *Key[T]) String() string {
+	return "ctxutil.Key(" + k.name + ")"
+}
+
+var (
+	// RequestIDKey holds the ID of the request being handled.
+	RequestIDKey = NewKey[string]("request-id")
+	// TraceIDKey holds the ID of the distributed trace the work belongs to.
+	TraceIDKey = NewKey[string]("trace-id")
+)
+
+// WithRequestID returns a copy of ctx carrying the request ID id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return RequestIDKey.With(ctx, id)
+}
+
+// RequestID returns the request ID stored in ctx, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := RequestIDKey.Get(ctx)
+	return id
+}
+
+// WithTraceID returns a copy of ctx carrying the trace ID id.
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return TraceIDKey.With(ctx, id)
+}
+
+// TraceID returns the trace ID stored in ctx, or "".
+func TraceID(ctx context.Context) string {
+	id, _ := TraceIDKey.Get(ctx)
+	return id
+}
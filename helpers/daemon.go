@@ -0,0 +1,35 @@
+package helpers
+
+// environment variable marking the re-executed daemon process, holding the
+// fd of the pipe it reports its setup on.
+const daemonEnv = "DAEMON_READY_FD"
+
+// DaemonOptions configures Daemonize.
+type DaemonOptions struct {
+	// LogFile receives the daemon's stdout and stderr, appended. Point the
+	// logger's output at the same file so panics and stray prints end up next
+	// to the log entries. Empty discards them.
+	LogFile string
+	// Dir is the working directory of the daemon, "/" if empty, so it doesn't
+	// keep the directory it was started from busy.
+	Dir string
+	// Umask is the file mode creation mask of the daemon, 022 if zero.
+	Umask int
+	// PIDFile, if set, is locked with LockPIDFile by the daemon and removed
+	// through the exit hooks.
+	PIDFile string
+}
+
+// Daemonize moves the program into the background. Go can't fork a running
+// process, so the program is started again as a new session leader with its
+// stdin on /dev/null and stdout and stderr on opts.LogFile; the original
+// process waits for the copy to set itself up, then exits with status 0.
+//
+// In the daemon, Daemonize sets the umask and working directory, locks the
+// PID file and returns nil. Call it first thing in main, before starting the
+// logger or any goroutines, since everything before it runs twice. It
+// returns an error, in the original process, if the daemon couldn't be
+// started or failed to set up.
+func Daemonize(opts DaemonOptions) error {
+	return daemonize(opts)
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package helpers
+
+import "errors"
+
+func daemonize(DaemonOptions) error {
+	return errors.New("daemonize: not supported on this platform")
+}
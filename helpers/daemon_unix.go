@@ -0,0 +1,117 @@
+//go:build unix
+
+package helpers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// written by the daemon once it's set up.
+const daemonOK = "ok"
+
+// how long the daemon has to report back before Daemonize gives up on it.
+var daemonReadyTimeout = 30 * time.Second
+
+func daemonize(opts DaemonOptions) error {
+	if fd, err := strconv.Atoi(os.Getenv(daemonEnv)); err == nil {
+		os.Unsetenv(daemonEnv)
+		ready := os.NewFile(uintptr(fd), "daemon-ready")
+		defer ready.Close()
+		if err := daemonSetup(opts); err != nil {
+			ready.WriteString(err.Error())
+			os.Exit(1)
+		}
+		ready.WriteString(daemonOK)
+		return nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("daemonize: %w", err)
+	}
+	null, err := os.Open(os.DevNull)
+	if err != nil {
+		return fmt.Errorf("daemonize: %w", err)
+	}
+	defer null.Close()
+	out := null
+	if opts.LogFile != "" {
+		out, err = os.OpenFile(opts.LogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("daemonize: %w", err)
+		}
+		defer out.Close()
+	}
+	readR, readW, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("daemonize: %w", err)
+	}
+	defer readR.Close()
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = null, out, out
+	cmd.ExtraFiles = []*os.File{readW}
+	cmd.Env = append(os.Environ(), daemonEnv+"="+strconv.Itoa(listenFdsStart))
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		readW.Close()
+		return fmt.Errorf("daemonize: start %s: %w", exe, err)
+	}
+	readW.Close()
+
+	// the daemon writes daemonOK once it's set up, or why it failed.
+	done := make(chan string, 1)
+	go func() {
+		msg, _ := io.ReadAll(readR)
+		done <- string(msg)
+	}()
+	select {
+	case msg := <-done:
+		if msg != daemonOK {
+			cmd.Wait()
+			if msg == "" {
+				return fmt.Errorf("daemonize: pid %d exited before it was set up", cmd.Process.Pid)
+			}
+			return fmt.Errorf("daemonize: %s", msg)
+		}
+	case <-time.After(daemonReadyTimeout):
+		cmd.Process.Kill()
+		cmd.Wait()
+		return fmt.Errorf("daemonize: pid %d not set up after %s, killed it", cmd.Process.Pid, daemonReadyTimeout)
+	}
+	os.Exit(0)
+	return nil
+}
+
+// daemonSetup runs in the daemon: umask, working directory and PID file.
+func daemonSetup(opts DaemonOptions) error {
+	umask := opts.Umask
+	if umask == 0 {
+		umask = 0o022
+	}
+	syscall.Umask(umask)
+	// the PID file path is relative to the directory the program was started in.
+	pidFile, err := filepath.Abs(opts.PIDFile)
+	if err != nil {
+		return err
+	}
+	dir := opts.Dir
+	if dir == "" {
+		dir = "/"
+	}
+	if err := os.Chdir(dir); err != nil {
+		return err
+	}
+	if opts.PIDFile != "" {
+		if _, err := LockPIDFile(pidFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package helpers
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Change is one field that differs between two values compared by Diff.
+type Change struct {
+	Path string // dotted field path, map keys in brackets: "Logger.Colors[error]"
+	Old  any    // nil if the field was added
+	New  any    // nil if the field was removed
+}
+
+func (c Change) String() string {
+	switch {
+	case c.Old == nil:
+		return fmt.Sprintf("%s added %v", c.Path, c.New)
+	case c.New == nil:
+		return fmt.Sprintf("%s removed (was %v)", c.Path, c.Old)
+	}
+	return fmt.Sprintf("%s changed %v→%v", c.Path, c.Old, c.New)
+}
+
+// Changes is the result of Diff.
+type Changes []Change
+
+// String lists the changes, separated by "; ".
+func (cs Changes) String() string {
+	parts := make([]string, len(cs))
+	for i, c := range cs {
+		parts[i] = c.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Diff compares old and new field by field and returns what changed. Structs
+// and maps are walked, pointers followed, and anything else (including
+// slices) compared as a whole. Unexported fields are ignored. old and new are
+// expected to be of the same type; if they aren't, a single change for the
+// whole value is returned.
+func Diff(old, new any) Changes {
+	a, b := reflect.ValueOf(old), reflect.ValueOf(new)
+	if !a.IsValid() || !b.IsValid() || a.Type() != b.Type() {
+		if reflect.DeepEqual(old, new) {
+			return nil
+		}
+		return Changes{{Path: "", Old: old, New: new}}
+	}
+	return diffValues("", a, b)
+}
+
+func diffValues(path string, a, b reflect.Value) Changes {
+	switch a.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			break
+		}
+		return diffValues(path, a.Elem(), b.Elem())
+	case reflect.Struct:
+		if a.Type().String() == "time.Time" {
+			break
+		}
+		var out Changes
+		for i := 0; i < a.NumField(); i++ {
+			f := a.Type().Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			out = append(out, diffValues(joinPath(path, f.Name), a.Field(i), b.Field(i))...)
+		}
+		return out
+	case reflect.Map:
+		var out Changes
+		keys := map[string]reflect.Value{}
+		for _, k := range a.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		for _, k := range b.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		names := make([]string, 0, len(keys))
+		for n := range keys {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			k := keys[n]
+			av, bv := a.MapIndex(k), b.MapIndex(k)
+			p := path + "[" + n + "]"
+			switch {
+			case !av.IsValid():
+				out = append(out, Change{Path: p, New: bv.Interface()})
+			case !bv.IsValid():
+				out = append(out, Change{Path: p, Old: av.Interface()})
+			default:
+				out = append(out, diffValues(p, av, bv)...)
+			}
+		}
+		return out
+	}
+	if reflect.DeepEqual(a.Interface(), b.Interface()) {
+		return nil
+	}
+	return Changes{{Path: path, Old: a.Interface(), New: b.Interface()}}
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
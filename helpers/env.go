@@ -0,0 +1,257 @@
+package helpers
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// LoadEnv populates the struct pointed to by cfg from environment variables.
+// Fields are described with struct tags:
+//
+//	type Config struct {
+//		Port    int           `env:"PORT" default:"8080"`
+//		Timeout time.Duration `env:"TIMEOUT" default:"5s"`
+//		MaxBody int64         `env:"MAX_BODY,size" default:"1MiB"`
+//		Hosts   []string      `env:"HOSTS" required:"true"`
+//	}
+//
+// Durations, sizes (with the ",size" option), comma separated slices,
+// booleans, numbers, strings and encoding.TextUnmarshaler types are supported.
+// Nested structs are walked recursively. Every problem found is logged at
+// ERROR through the registered logger, one entry per variable, and reported
+// in the returned error, not just the first one.
+func LoadEnv(cfg any) error {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("load env: expected a pointer to a struct, got %T", cfg)
+	}
+	if err := loadEnv(v.Elem(), true); err != nil {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range joined.Unwrap() {
+				log().Error(fmt.Sprintf("load env: %v", e))
+			}
+		}
+		return fmt.Errorf("load env: %w", err)
+	}
+	return nil
+}
+
+// loadEnv sets the fields of v from the environment. Without useDefaults,
+// unset variables leave fields untouched and required isn't enforced, so
+// values already loaded from elsewhere are kept.
+func loadEnv(v reflect.Value, useDefaults bool) error {
+	var errs []error
+	walkFields(v, func(f reflect.StructField, fv reflect.Value) {
+		name, opts := parseTag(f.Tag.Get("env"))
+		if name == "" {
+			return
+		}
+		// an empty variable counts as unset.
+		raw := os.Getenv(name)
+		ok := raw != ""
+		if !ok && useDefaults {
+			if def, hasDef := f.Tag.Lookup("default"); hasDef {
+				raw, ok = def, true
+			}
+		}
+		if !ok {
+			if f.Tag.Get("required") == "true" && useDefaults {
+				errs = append(errs, fmt.Errorf("%s: required variable %s is not set", f.Name, name))
+			}
+			return
+		}
+		if err := setValue(fv, raw, opts.has("size")); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid value %q for %s: %w", f.Name, raw, name, err))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// walkFields calls fn for each settable field of the struct v, descending
+// into nested structs that are not themselves parseable values.
+func walkFields(v reflect.Value, fn func(reflect.StructField, reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fv := v.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if fv.Kind() == reflect.Struct && !isLeafType(fv) {
+			walkFields(fv, fn)
+			continue
+		}
+		fn(f, fv)
+	}
+}
+
+// isLeafType reports whether v should be parsed as a single value rather than
+// walked as a struct.
+func isLeafType(v reflect.Value) bool {
+	if v.Type() == reflect.TypeOf(time.Time{}) {
+		return true
+	}
+	_, ok := v.Addr().Interface().(encoding.TextUnmarshaler)
+	return ok
+}
+
+// tagOptions are the comma separated options following a tag name.
+type tagOptions []string
+
+func (o tagOptions) has(opt string) bool {
+	for _, s := range o {
+		if s == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTag splits a tag like `NAME,size` into its name and options.
+func parseTag(tag string) (string, tagOptions) {
+	parts := strings.Split(tag, ",")
+	return strings.TrimSpace(parts[0]), tagOptions(parts[1:])
+}
+
+// setValue parses raw into v according to v's type.
+func setValue(v reflect.Value, raw string, size bool) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+	switch v.Interface().(type) {
+	case time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	case time.Time:
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		var err error
+		if size {
+			n, err = ParseSize(raw)
+		} else {
+			n, err = strconv.ParseInt(raw, 0, v.Type().Bits())
+		}
+		if err != nil {
+			return err
+		}
+		if v.OverflowInt(n) {
+			return fmt.Errorf("value out of range")
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if size {
+			s, err := ParseSize(raw)
+			if err != nil {
+				return err
+			}
+			if s < 0 {
+				return fmt.Errorf("negative size")
+			}
+			n = uint64(s)
+		} else {
+			var err error
+			if n, err = strconv.ParseUint(raw, 0, v.Type().Bits()); err != nil {
+				return err
+			}
+		}
+		if v.OverflowUint(n) {
+			return fmt.Errorf("value out of range")
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var parts []string
+		if strings.TrimSpace(raw) != "" {
+			parts = strings.Split(raw, ",")
+		}
+		s := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, p := range parts {
+			if err := setValue(s.Index(i), strings.TrimSpace(p), size); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Pointer:
+		p := reflect.New(v.Type().Elem())
+		if err := setValue(p.Elem(), raw, size); err != nil {
+			return err
+		}
+		v.Set(p)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// size units accepted by ParseSize.
+var sizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kb":  1000,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mb":  1000 * 1000,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gb":  1000 * 1000 * 1000,
+	"gib": 1 << 30,
+	"t":   1 << 40,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"tib": 1 << 40,
+}
+
+// ParseSize parses a human readable byte size such as "512", "10KB" or
+// "1.5GiB". Single letter units (K, M, G, T) are binary.
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.'
+	})
+	num, unit := s, ""
+	if i >= 0 {
+		num, unit = s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+	}
+	mult, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit %q", unit)
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(f * float64(mult)), nil
+}
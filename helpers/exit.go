@@ -0,0 +1,33 @@
+package helpers
+
+import "sync"
+
+var (
+	exitMu    sync.Mutex
+	exitHooks []func()
+)
+
+// OnExit registers fn to run when the process exits through the logger's
+// shutdown path (Shutdown, Critical) or through Check and Must. Hooks run
+// once, most recently registered first.
+func OnExit(fn func()) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+	exitHooks = append(exitHooks, fn)
+}
+
+// RunExitHooks runs and clears the registered exit hooks. It's called by the
+// logger before exiting; applications that exit some other way can call it
+// themselves.
+func RunExitHooks() {
+	exitMu.Lock()
+	hooks := exitHooks
+	exitHooks = nil
+	exitMu.Unlock()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		Safe(func() error {
+			hooks[i]()
+			return nil
+		})
+	}
+}
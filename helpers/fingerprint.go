@@ -0,0 +1,17 @@
+package helpers
+
+import "regexp"
+
+var (
+	fpQuoted = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+	fpHexID  = regexp.MustCompile(`\b[0-9a-fA-F]{8,}(-[0-9a-fA-F]{4,})*\b`)
+	fpNumber = regexp.MustCompile(`\d+(\.\d+)?`)
+)
+
+// Fingerprint reduces a message to its shape, so errors that differ only in
+// ids, numbers or quoted values group together.
+func Fingerprint(msg string) string {
+	msg = fpQuoted.ReplaceAllString(msg, `"*"`)
+	msg = fpHexID.ReplaceAllString(msg, "<id>")
+	return fpNumber.ReplaceAllString(msg, "<n>")
+}
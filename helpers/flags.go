@@ -0,0 +1,153 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Flags holds feature flags, read from environment variables and optionally
+// a config file. A variable named after the flag with the prefix, uppercased
+// and with anything but letters and digits turned into '_' (new-checkout
+// with prefix "FLAG_" is FLAG_NEW_CHECKOUT), overrides the config file. Every
+// evaluation is logged at DEBUG with the value and where it came from, so
+// differences in behavior between environments can be traced to a flag.
+type Flags struct {
+	prefix string
+	path   string // config file, if any
+	file   atomic.Pointer[map[string]any]
+
+	mu       sync.Mutex
+	watchers map[string][]func(old, new string)
+}
+
+// flagFile is the layout LoadFlags expects: the flags under a top-level
+// "flags" key, next to whatever else the application keeps there.
+type flagFile struct {
+	Flags map[string]any `json:"flags" yaml:"flags" toml:"flags"`
+}
+
+// NewFlags returns flags read from environment variables with the given prefix.
+func NewFlags(prefix string) *Flags {
+	f := &Flags{prefix: prefix, watchers: map[string][]func(old, new string){}}
+	f.file.Store(&map[string]any{})
+	return f
+}
+
+// LoadFlags returns flags read from environment variables with the given
+// prefix and from the "flags" section of the config file at path, which is
+// reloaded when it changes, see WatchConfig and OnChange.
+func LoadFlags(ctx context.Context, path, prefix string) (*Flags, error) {
+	f := NewFlags(prefix)
+	f.path = path
+	cv, err := WatchConfig(ctx, path, func(old, new *flagFile) {
+		f.file.Store(&new.Flags)
+		f.notify(old.Flags, new.Flags)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load flags: %w", err)
+	}
+	f.file.Store(&cv.Get().Flags)
+	return f, nil
+}
+
+// OnChange registers fn to be called when the config file value of flag name
+// changes on a reload. old or new is empty if the flag was added or removed.
+func (f *Flags) OnChange(name string, fn func(old, new string)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.watchers[name] = append(f.watchers[name], fn)
+}
+
+// notify calls the OnChange callbacks of the flags that differ between old and new.
+func (f *Flags) notify(old, new map[string]any) {
+	f.mu.Lock()
+	var calls []func()
+	for name, fns := range f.watchers {
+		o, n := flagString(old, name), flagString(new, name)
+		if o == n {
+			continue
+		}
+		for _, fn := range fns {
+			calls = append(calls, func() { fn(o, n) })
+		}
+	}
+	f.mu.Unlock()
+	for _, call := range calls {
+		Safe(func() error {
+			call()
+			return nil
+		})
+	}
+}
+
+// lookup returns the raw value of flag name and where it was found.
+func (f *Flags) lookup(name string) (value, source string, ok bool) {
+	env := f.envName(name)
+	if v, ok := os.LookupEnv(env); ok {
+		return v, "env " + env, true
+	}
+	if m := *f.file.Load(); m != nil {
+		if _, ok := m[name]; ok {
+			return flagString(m, name), "config " + f.path, true
+		}
+	}
+	return "", "", false
+}
+
+// envName returns the environment variable for flag name.
+func (f *Flags) envName(name string) string {
+	return f.prefix + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// Bool returns the value of flag name, or def if it isn't set or isn't a boolean.
+func (f *Flags) Bool(name string, def bool) bool {
+	return evalFlag(f, name, def, strconv.ParseBool)
+}
+
+// Int returns the value of flag name, or def if it isn't set or isn't an integer.
+func (f *Flags) Int(name string, def int) int {
+	return evalFlag(f, name, def, strconv.Atoi)
+}
+
+// String returns the value of flag name, or def if it isn't set.
+func (f *Flags) String(name string, def string) string {
+	return evalFlag(f, name, def, func(s string) (string, error) { return s, nil })
+}
+
+// evalFlag looks up flag name, parses it and logs the evaluation.
+func evalFlag[T any](f *Flags, name string, def T, parse func(string) (T, error)) T {
+	raw, source, ok := f.lookup(name)
+	if !ok {
+		log().Debug(fmt.Sprintf("flag %s = %v (default)", name, def))
+		return def
+	}
+	v, err := parse(raw)
+	if err != nil {
+		log().Warning(fmt.Sprintf("flag %s: invalid value %q from %s, using default %v", name, raw, source, def))
+		return def
+	}
+	log().Debug(fmt.Sprintf("flag %s = %v (%s)", name, v, source))
+	return v
+}
+
+// flagString returns the value of name in m as a string, empty if it isn't there.
+func flagString(m map[string]any, name string) string {
+	v, ok := m[name]
+	if !ok || v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
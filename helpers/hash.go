@@ -0,0 +1,144 @@
+package helpers
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HashAlgo names a checksum algorithm supported by HashFile and HashReader.
+type HashAlgo string
+
+const (
+	SHA256 HashAlgo = "sha256"
+	SHA512 HashAlgo = "sha512"
+	CRC32  HashAlgo = "crc32"
+)
+
+func (a HashAlgo) new() (hash.Hash, error) {
+	switch a {
+	case SHA256:
+		return sha256.New(), nil
+	case SHA512:
+		return sha512.New(), nil
+	case CRC32:
+		return crc32.NewIEEE(), nil
+	}
+	return nil, fmt.Errorf("unknown hash algorithm %q", a)
+}
+
+// HashReader streams r through algo and returns the hex encoded sum.
+func HashReader(r io.Reader, algo HashAlgo) (string, error) {
+	h, err := algo.new()
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashFile returns the hex encoded algo sum of the file at path.
+func HashFile(path string, algo HashAlgo) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("hash %s: %w", path, err)
+	}
+	defer f.Close()
+	sum, err := HashReader(f, algo)
+	if err != nil {
+		return "", fmt.Errorf("hash %s: %w", path, err)
+	}
+	return sum, nil
+}
+
+// algoForSum guesses the algorithm from the length of a hex encoded sum.
+func algoForSum(sum string) (HashAlgo, error) {
+	switch len(sum) {
+	case 8:
+		return CRC32, nil
+	case 64:
+		return SHA256, nil
+	case 128:
+		return SHA512, nil
+	}
+	return "", fmt.Errorf("can't tell the algorithm of a %d character sum", len(sum))
+}
+
+// VerifyChecksumFile checks files against sumsFile, which uses the format of
+// sha256sum and friends ("<hex sum>  <name>" per line, names relative to the
+// sums file). If path is a directory every file listed is checked; otherwise
+// only path's entry. The algorithm is picked from the length of each sum.
+// Each result is logged, and an error is returned if any file failed.
+func VerifyChecksumFile(path, sumsFile string) error {
+	f, err := os.Open(sumsFile)
+	if err != nil {
+		return fmt.Errorf("verify %s: %w", sumsFile, err)
+	}
+	defer f.Close()
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("verify %s: %w", path, err)
+	}
+	base := filepath.Dir(sumsFile)
+	var errs []error
+	checked := 0
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		sum, name, ok := strings.Cut(line, " ")
+		if !ok {
+			errs = append(errs, fmt.Errorf("malformed line %q", line))
+			continue
+		}
+		// a leading '*' marks binary mode in the coreutils format.
+		name = strings.TrimPrefix(strings.TrimSpace(name), "*")
+		file := filepath.Join(base, name)
+		if info.IsDir() {
+			file = filepath.Join(path, name)
+		} else if filepath.Clean(file) != filepath.Clean(path) {
+			continue
+		}
+		checked++
+		algo, err := algoForSum(sum)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			log().Error(fmt.Sprintf("checksum %s: %v", name, err))
+			continue
+		}
+		got, err := HashFile(file, algo)
+		switch {
+		case err != nil:
+			errs = append(errs, err)
+			log().Error(fmt.Sprintf("checksum %s: %v", name, err))
+		case !strings.EqualFold(got, sum):
+			errs = append(errs, fmt.Errorf("%s: %s mismatch", name, algo))
+			log().Error(fmt.Sprintf("checksum %s: FAILED (%s mismatch)", name, algo))
+		default:
+			log().Info(fmt.Sprintf("checksum %s: OK", name))
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("verify %s: %w", sumsFile, err)
+	}
+	if checked == 0 {
+		return fmt.Errorf("verify %s: no entry for %s", sumsFile, path)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("verify %s: %w", sumsFile, errors.Join(errs...))
+	}
+	return nil
+}
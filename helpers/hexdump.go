@@ -0,0 +1,83 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jeanhaley32/logger/colors"
+)
+
+// Hexdump formats b like `xxd`: an offset, sixteen bytes in groups of two,
+// and the printable ASCII. It's plain text, fit for any output; see
+// HexdumpColor for a terminal.
+//
+//	00000000: 4865 6c6c 6f2c 2077 6f72 6c64 210a       Hello, world!.
+func Hexdump(b []byte) string {
+	return hexdump(b, false)
+}
+
+// HexdumpColor is Hexdump with the offset and ASCII columns colored, for
+// printing to a terminal.
+func HexdumpColor(b []byte) string {
+	return hexdump(b, true)
+}
+
+func hexdump(b []byte, color bool) string {
+	wrap := func(c colors.Color, s string) string {
+		if !color {
+			return s
+		}
+		return colors.Wrap(c, s)
+	}
+	var sb strings.Builder
+	for off := 0; off < len(b); off += 16 {
+		line := b[off:min(off+16, len(b))]
+		sb.WriteString(wrap(colors.BLUE, fmt.Sprintf("%08x:", off)))
+		for i := 0; i < 16; i++ {
+			if i%2 == 0 {
+				sb.WriteByte(' ')
+			}
+			if i < len(line) {
+				fmt.Fprintf(&sb, "%02x", line[i])
+			} else {
+				sb.WriteString("  ")
+			}
+		}
+		sb.WriteString("  ")
+		// color each run of printable or unprintable bytes once.
+		for i := 0; i < len(line); {
+			printable := isPrintable(line[i])
+			j := i
+			var run strings.Builder
+			for ; j < len(line) && isPrintable(line[j]) == printable; j++ {
+				if printable {
+					run.WriteByte(line[j])
+				} else {
+					run.WriteByte('.')
+				}
+			}
+			if printable {
+				sb.WriteString(wrap(colors.GREEN, run.String()))
+			} else {
+				sb.WriteString(wrap(colors.GRAY, run.String()))
+			}
+			i = j
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// isPrintable reports whether c is shown as itself in the ASCII column.
+func isPrintable(c byte) bool {
+	return c >= 0x20 && c < 0x7f
+}
+
+// HexdumpN is Hexdump limited to the first max bytes of b, noting how many
+// were left out. Handy for logging packet or file headers.
+func HexdumpN(b []byte, max int) string {
+	if max < 0 || len(b) <= max {
+		return Hexdump(b)
+	}
+	return Hexdump(b[:max]) + fmt.Sprintf("... %d more bytes\n", len(b)-max)
+}
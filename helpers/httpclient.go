@@ -0,0 +1,137 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// HTTPError is returned by HTTPClient.Do for error responses when body
+// capture is enabled.
+type HTTPError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       []byte // up to CaptureBody bytes of the response body
+}
+
+func (e *HTTPError) Error() string {
+	if len(e.Body) == 0 {
+		return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("%s %s: %d %s: %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+}
+
+// HTTPClient wraps http.Client with timeouts, retries and request logging.
+// Requests that fail to connect or get a 5xx response are retried with
+// exponential backoff, as long as their body can be replayed. Every attempt
+// is logged at DEBUG with its method, URL, status and duration.
+type HTTPClient struct {
+	Client      *http.Client
+	Retries     int           // retries after the first attempt
+	MinBackoff  time.Duration // wait before the first retry, doubled after each one
+	MaxBackoff  time.Duration
+	CaptureBody int // if > 0, responses >= 400 are returned as *HTTPError with this much of the body
+	Logger      Logger
+}
+
+// NewHTTPClient returns an HTTPClient with a 30 second timeout and 3 retries,
+// logging to l, or to the registered logger if l is nil.
+func NewHTTPClient(l Logger) *HTTPClient {
+	return &HTTPClient{
+		Client: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				Proxy:                 http.ProxyFromEnvironment,
+				DialContext:           (&net.Dialer{Timeout: 5 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
+				TLSHandshakeTimeout:   5 * time.Second,
+				ResponseHeaderTimeout: 15 * time.Second,
+				IdleConnTimeout:       90 * time.Second,
+				MaxIdleConnsPerHost:   10,
+			},
+		},
+		Retries:    3,
+		MinBackoff: 200 * time.Millisecond,
+		MaxBackoff: 5 * time.Second,
+		Logger:     l,
+	}
+}
+
+// Get issues a GET request to url.
+func (c *HTTPClient) Get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// Do sends req, retrying as described on HTTPClient.
+func (c *HTTPClient) Do(req *http.Request) (*http.Response, error) {
+	l := c.Logger
+	if l == nil {
+		l = log()
+	}
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	canReplay := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	backoff := NewBackoff(c.MinBackoff, c.MaxBackoff)
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		start := time.Now()
+		resp, err := client.Do(req)
+		elapsed := time.Since(start)
+		if err != nil {
+			l.Debug(fmt.Sprintf("%s %s failed after %s: %v", req.Method, req.URL, elapsed, err))
+		} else {
+			l.Debug(fmt.Sprintf("%s %s %d in %s", req.Method, req.URL, resp.StatusCode, elapsed))
+		}
+
+		retry := err != nil && !errors.Is(err, context.Canceled) || err == nil && resp.StatusCode >= 500
+		if !retry || !canReplay || attempt >= c.Retries {
+			if err != nil {
+				return nil, err
+			}
+			return c.checkStatus(req, resp)
+		}
+		if resp != nil {
+			// drain so the connection can be reused.
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		wait := backoff.Next()
+		l.Debug(fmt.Sprintf("%s %s: retrying in %s (attempt %d of %d)", req.Method, req.URL, wait, attempt+1, c.Retries))
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// checkStatus turns error responses into *HTTPError when body capture is on.
+func (c *HTTPClient) checkStatus(req *http.Request, resp *http.Response) (*http.Response, error) {
+	if c.CaptureBody <= 0 || resp.StatusCode < 400 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, int64(c.CaptureBody)))
+	return nil, &HTTPError{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Body:       body,
+	}
+}
@@ -0,0 +1,133 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bytes formats n as a binary size: 1536 becomes "1.5 KiB".
+func Bytes(n int64) string {
+	const unit = 1024
+	if n < unit && n > -unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	f := float64(n)
+	i := -1
+	for (f >= unit || f <= -unit) && i < 5 {
+		f /= unit
+		i++
+	}
+	// round before settling on the unit: 1048575 is "1 MiB", not "1024 KiB".
+	if r := roundTo(f, 1); (r >= unit || r <= -unit) && i < 5 {
+		f /= unit
+		i++
+	}
+	return trimZero(strconv.FormatFloat(f, 'f', 1, 64)) + " " + string("KMGTPE"[i]) + "iB"
+}
+
+// Count formats n with a metric suffix and three significant digits:
+// 1234567 becomes "1.23M".
+func Count(n int64) string {
+	if n < 1000 && n > -1000 {
+		return strconv.FormatInt(n, 10)
+	}
+	// negated as a float, since -math.MinInt64 overflows.
+	sign, f := "", float64(n)
+	if f < 0 {
+		sign, f = "-", -f
+	}
+	suffixes := []string{"K", "M", "B", "T", "Q"}
+	i := -1
+	for f >= 1000 && i < len(suffixes)-1 {
+		f /= 1000
+		i++
+	}
+	// round before settling on the suffix: 999950 is "1M", not "1000K".
+	if roundTo(f, countPrec(f)) >= 1000 && i < len(suffixes)-1 {
+		f /= 1000
+		i++
+	}
+	return sign + trimZero(strconv.FormatFloat(f, 'f', countPrec(f), 64)) + suffixes[i]
+}
+
+// countPrec returns the decimals that give f three significant digits.
+func countPrec(f float64) int {
+	switch {
+	case f >= 100:
+		return 0
+	case f >= 10:
+		return 1
+	}
+	return 2
+}
+
+// roundTo rounds f to prec decimals, as FormatFloat does.
+func roundTo(f float64, prec int) float64 {
+	r, _ := strconv.ParseFloat(strconv.FormatFloat(f, 'f', prec, 64), 64)
+	return r
+}
+
+// Duration formats d with at most its two largest units: 90*time.Second
+// becomes "1m30s", 26 hours becomes "1d2h". Durations under a minute keep one
+// decimal ("1.5s"), and under a second are shown in milliseconds.
+func Duration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+		if d < 0 {
+			// -math.MinInt64 overflows; a nanosecond less doesn't show.
+			d = math.MaxInt64
+		}
+	}
+	// round before settling on the unit: 59.96s is "1m", not "60s".
+	if d < time.Millisecond {
+		return sign + d.String()
+	}
+	if r := d.Round(time.Millisecond); r < time.Second {
+		return sign + r.String()
+	}
+	if r := d.Round(100 * time.Millisecond); r < time.Minute {
+		return sign + trimZero(strconv.FormatFloat(r.Seconds(), 'f', 1, 64)) + "s"
+	}
+	d = d.Round(time.Second)
+	units := []struct {
+		d    time.Duration
+		name string
+	}{
+		{24 * time.Hour, "d"},
+		{time.Hour, "h"},
+		{time.Minute, "m"},
+		{time.Second, "s"},
+	}
+	var sb strings.Builder
+	sb.WriteString(sign)
+	shown := 0
+	for _, u := range units {
+		if shown == 2 {
+			break
+		}
+		n := d / u.d
+		if n == 0 {
+			if shown > 0 {
+				break
+			}
+			continue
+		}
+		fmt.Fprintf(&sb, "%d%s", n, u.name)
+		d -= n * u.d
+		shown++
+	}
+	return sb.String()
+}
+
+// trimZero drops a trailing ".0", ".00" from a formatted number.
+func trimZero(s string) string {
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	return s
+}
@@ -0,0 +1,56 @@
+package helpers
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBytes(t *testing.T) {
+	for n, want := range map[int64]string{
+		0:             "0 B",
+		1023:          "1023 B",
+		1536:          "1.5 KiB",
+		1048575:       "1 MiB",
+		-1536:         "-1.5 KiB",
+		math.MaxInt64: "8 EiB",
+		math.MinInt64: "-8 EiB",
+	} {
+		if got := Bytes(n); got != want {
+			t.Errorf("Bytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestCount(t *testing.T) {
+	for n, want := range map[int64]string{
+		999:           "999",
+		1234567:       "1.23M",
+		999950:        "1M",
+		-1500:         "-1.5K",
+		math.MaxInt64: "9223Q",
+		math.MinInt64: "-9223Q",
+	} {
+		if got := Count(n); got != want {
+			t.Errorf("Count(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		1500 * time.Nanosecond:       "1.5µs",
+		999600 * time.Microsecond:    "1s",
+		1500 * time.Millisecond:      "1.5s",
+		59960 * time.Millisecond:     "1m",
+		90 * time.Second:             "1m30s",
+		time.Hour - time.Millisecond: "1h",
+		26 * time.Hour:               "1d2h",
+		-90 * time.Second:            "-1m30s",
+		time.Duration(math.MinInt64): "-106751d23h",
+	} {
+		if got := Duration(d); got != want {
+			t.Errorf("Duration(%d) = %q, want %q", int64(d), got, want)
+		}
+	}
+}
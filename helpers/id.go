@@ -0,0 +1,90 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// UUIDv4 returns a random (version 4) UUID in its canonical string form.
+func UUIDv4() string {
+	var u [16]byte
+	mustRead(u[:])
+	u[6] = u[6]&0x0f | 0x40 // version 4
+	u[8] = u[8]&0x3f | 0x80 // RFC 4122 variant
+	return formatUUID(u)
+}
+
+var (
+	// v7 state, so UUIDs made in the same millisecond still sort in order.
+	v7Mu     sync.Mutex
+	v7LastMs int64
+	v7Seq    uint16
+)
+
+// UUIDv7 returns a time ordered (version 7) UUID: the first 48 bits are the
+// unix time in milliseconds, so IDs sort by creation time. IDs created in the
+// same millisecond use an incrementing counter to keep that order.
+func UUIDv7() string {
+	var u [16]byte
+	mustRead(u[6:])
+	ms := time.Now().UnixMilli()
+	v7Mu.Lock()
+	if ms <= v7LastMs {
+		ms = v7LastMs
+		v7Seq++
+		if v7Seq > 0x0fff {
+			// counter exhausted, borrow from the next millisecond.
+			ms++
+			v7Seq = 0
+		}
+	} else {
+		v7Seq = binary.BigEndian.Uint16(u[6:8]) & 0x07ff // random start, leaving room to count
+	}
+	v7LastMs = ms
+	seq := v7Seq
+	v7Mu.Unlock()
+
+	u[0] = byte(ms >> 40)
+	u[1] = byte(ms >> 32)
+	u[2] = byte(ms >> 24)
+	u[3] = byte(ms >> 16)
+	u[4] = byte(ms >> 8)
+	u[5] = byte(ms)
+	u[6] = 0x70 | byte(seq>>8) // version 7 and the top of the counter
+	u[7] = byte(seq)
+	u[8] = u[8]&0x3f | 0x80
+	return formatUUID(u)
+}
+
+func formatUUID(u [16]byte) string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// ShortID returns an n character random ID drawn from [0-9A-Za-z] with
+// crypto/rand. Eleven characters carry about 65 bits of randomness.
+func ShortID(n int) string {
+	return RandString(n, base62)
+}
+
+// mustRead fills b from crypto/rand. It only fails if the OS has no source
+// of randomness, which nothing can recover from.
+func mustRead(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic("helpers: crypto/rand failed: " + err.Error())
+	}
+}
@@ -0,0 +1,105 @@
+package helpers
+
+import (
+	"regexp"
+	"sync"
+	"testing"
+)
+
+func TestIDsAreUnique(t *testing.T) {
+	const goroutines, perGoroutine = 8, 5000
+	gens := map[string]func() string{
+		"UUIDv4":  UUIDv4,
+		"UUIDv7":  UUIDv7,
+		"ShortID": func() string { return ShortID(11) },
+	}
+	for name, gen := range gens {
+		t.Run(name, func(t *testing.T) {
+			ids := make(chan string, goroutines*perGoroutine)
+			var wg sync.WaitGroup
+			for g := 0; g < goroutines; g++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < perGoroutine; i++ {
+						ids <- gen()
+					}
+				}()
+			}
+			wg.Wait()
+			close(ids)
+			seen := make(map[string]bool, goroutines*perGoroutine)
+			for id := range ids {
+				if seen[id] {
+					t.Fatalf("duplicate ID %s", id)
+				}
+				seen[id] = true
+			}
+		})
+	}
+}
+
+func TestUUIDFormat(t *testing.T) {
+	v4 := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	v7 := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	for i := 0; i < 100; i++ {
+		if id := UUIDv4(); !v4.MatchString(id) {
+			t.Fatalf("UUIDv4() = %s, not a version 4 UUID", id)
+		}
+		if id := UUIDv7(); !v7.MatchString(id) {
+			t.Fatalf("UUIDv7() = %s, not a version 7 UUID", id)
+		}
+	}
+}
+
+func TestUUIDv7IsMonotonic(t *testing.T) {
+	prev := UUIDv7()
+	for i := 0; i < 100000; i++ {
+		id := UUIDv7()
+		if id <= prev {
+			t.Fatalf("UUIDv7 went backwards: %s after %s", id, prev)
+		}
+		prev = id
+	}
+}
+
+func TestRandString(t *testing.T) {
+	tests := []struct {
+		n        int
+		alphabet string
+		wantLen  int
+	}{
+		{10, base62, 10},
+		{0, base62, 0},
+		{-1, base62, 0},
+		{5, "", 0},
+		{3, "é", 3},
+	}
+	for _, tt := range tests {
+		got := []rune(RandString(tt.n, tt.alphabet))
+		if len(got) != tt.wantLen {
+			t.Errorf("RandString(%d, %q) has %d characters, want %d", tt.n, tt.alphabet, len(got), tt.wantLen)
+		}
+	}
+	if id := ShortID(32); !regexp.MustCompile(`^[0-9A-Za-z]{32}$`).MatchString(id) {
+		t.Errorf("ShortID(32) = %q, want 32 base62 characters", id)
+	}
+}
+
+func BenchmarkUUIDv4(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		UUIDv4()
+	}
+}
+
+func BenchmarkUUIDv7(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		UUIDv7()
+	}
+}
+
+func BenchmarkShortID(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		ShortID(11)
+	}
+}
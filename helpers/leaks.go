@@ -0,0 +1,112 @@
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// TB is the part of testing.TB VerifyNoLeaks needs.
+type TB interface {
+	Helper()
+	Cleanup(func())
+	Errorf(format string, args ...any)
+}
+
+var (
+	// how long VerifyNoLeaks waits for goroutines to wind down.
+	leakGracePeriod = 2 * time.Second
+)
+
+// goroutines that belong to the runtime or the test framework.
+var leakIgnore = []string{
+	"testing.RunTests",
+	"testing.(*T).Run",
+	"testing.(*T).Parallel",
+	"testing.tRunner",
+	"testing.runFuzzing",
+	"runtime.goexit",
+	"created by runtime.gc",
+	"runtime.MHeap_Scavenger",
+	"signal.signal_recv",
+	"signal.loop",
+	"os/signal.watchSignalLoop",
+	"runtime.ensureSigM",
+}
+
+// VerifyNoLeaks snapshots the running goroutines and, when the test ends,
+// fails it if goroutines started since are still running after a short grace
+// period. Goroutines whose stack contains any of the allow substrings are
+// ignored.
+//
+//	func TestServer(t *testing.T) {
+//		helpers.VerifyNoLeaks(t)
+//		...
+//	}
+func VerifyNoLeaks(t TB, allow ...string) {
+	t.Helper()
+	before := map[string]bool{}
+	for id := range goroutineStacks() {
+		before[id] = true
+	}
+	t.Cleanup(func() {
+		t.Helper()
+		deadline := time.Now().Add(leakGracePeriod)
+		var leaked []string
+		for {
+			leaked = leaked[:0]
+			for id, stack := range goroutineStacks() {
+				if before[id] || ignoredStack(stack, allow) {
+					continue
+				}
+				leaked = append(leaked, stack)
+			}
+			if len(leaked) == 0 || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		if len(leaked) > 0 {
+			t.Errorf("%d goroutines leaked:\n\n%s", len(leaked), strings.Join(leaked, "\n\n"))
+		}
+	})
+}
+
+func ignoredStack(stack string, allow []string) bool {
+	for _, s := range append(allow, leakIgnore...) {
+		if strings.Contains(stack, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// goroutineStacks returns the stack of every goroutine except the caller's,
+// keyed by goroutine ID.
+func goroutineStacks() map[string]string {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	stacks := map[string]string{}
+	for i, g := range bytes.Split(buf, []byte("\n\n")) {
+		// the first stack is always the calling goroutine.
+		if i == 0 {
+			continue
+		}
+		header, _, _ := strings.Cut(string(g), "\n")
+		var id int
+		if _, err := fmt.Sscanf(header, "goroutine %d ", &id); err != nil {
+			continue
+		}
+		stacks[fmt.Sprint(id)] = string(g)
+	}
+	return stacks
+}
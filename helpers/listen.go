@@ -0,0 +1,101 @@
+package helpers
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+var (
+	// how many times ListenWithRetry tries before giving up, and the wait between tries.
+	listenAttempts = 10
+	listenBackoff  = 500 * time.Millisecond
+)
+
+// first file descriptor passed by systemd socket activation.
+const listenFdsStart = 3
+
+// FreePort asks the kernel for a free TCP port on localhost. The port can be
+// taken by someone else before it's used, so it's meant for tests.
+func FreePort() (int, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("free port: %w", err)
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port, nil
+}
+
+// ListenWithRetry listens on the TCP address addr, retrying for a few seconds
+// while the address is still in use (e.g. by a previous instance shutting
+// down). The address actually bound is logged.
+func ListenWithRetry(addr string) (net.Listener, error) {
+	var err error
+	for attempt := 1; attempt <= listenAttempts; attempt++ {
+		var ln net.Listener
+		ln, err = net.Listen("tcp", addr)
+		if err == nil {
+			log().Info(fmt.Sprintf("listening on %s", ln.Addr()))
+			return ln, nil
+		}
+		if !addrInUse(err) {
+			break
+		}
+		log().Warning(fmt.Sprintf("%s in use, retrying in %s (attempt %d of %d)", addr, listenBackoff, attempt, listenAttempts))
+		time.Sleep(listenBackoff)
+	}
+	return nil, fmt.Errorf("listen %s: %w", addr, err)
+}
+
+// ListenersFromEnv returns the listeners passed in by systemd socket
+// activation (LISTEN_PID and LISTEN_FDS). It returns nil if the process
+// wasn't started that way. The environment variables are cleared so child
+// processes don't inherit them.
+func ListenersFromEnv() ([]net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, nil
+	}
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+	listeners := make([]net.Listener, 0, n)
+	for fd := listenFdsStart; fd < listenFdsStart+n; fd++ {
+		f := os.NewFile(uintptr(fd), "listen-fd-"+strconv.Itoa(fd))
+		ln, err := net.FileListener(f)
+		f.Close() // FileListener dups the descriptor.
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("listener from fd %d: %w", fd, err)
+		}
+		log().Info(fmt.Sprintf("listening on %s (inherited fd %d)", ln.Addr(), fd))
+		listeners = append(listeners, ln)
+	}
+	return listeners, nil
+}
+
+// ListenerFromEnv returns the first socket activated listener, or nil if
+// there is none, in which case the caller falls back to listening itself:
+//
+//	ln, err := helpers.ListenerFromEnv()
+//	if ln == nil && err == nil {
+//		ln, err = helpers.ListenWithRetry(":8080")
+//	}
+func ListenerFromEnv() (net.Listener, error) {
+	listeners, err := ListenersFromEnv()
+	if err != nil || len(listeners) == 0 {
+		return nil, err
+	}
+	for _, l := range listeners[1:] {
+		l.Close()
+	}
+	return listeners[0], nil
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package helpers
+
+import "strings"
+
+// addrInUse reports whether err is from listening on an address in use.
+// There's no portable errno to check here, so it goes by the message:
+// Windows' WSAEADDRINUSE, or the plan9 and wasm wording.
+func addrInUse(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "address already in use") || strings.Contains(msg, "only one usage of each socket address")
+}
@@ -0,0 +1,13 @@
+//go:build unix
+
+package helpers
+
+import (
+	"errors"
+	"syscall"
+)
+
+// addrInUse reports whether err is from listening on an address in use.
+func addrInUse(err error) bool {
+	return errors.Is(err, syscall.EADDRINUSE)
+}
@@ -0,0 +1,94 @@
+package helpers
+
+import "sync"
+
+// Logger is the subset of *logger.Mylogger the helpers log through. It's an
+// interface so this package doesn't import the logger, which itself uses the
+// helpers.
+type Logger interface {
+	Debug(a any)
+	Info(a any)
+	Warning(a any)
+	Error(a any)
+	Critical(a any)
+}
+
+var (
+	defaultLogger Logger = nopLogger{} // logger used by helpers that aren't given one.
+	defaultMu     sync.RWMutex
+	defaultIsSet  bool
+)
+
+// SetLogger registers l as the logger used by helpers that log. The logger
+// package registers the first logger started automatically.
+func SetLogger(l Logger) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if l == nil {
+		defaultLogger, defaultIsSet = nopLogger{}, false
+		return
+	}
+	defaultLogger, defaultIsSet = l, true
+}
+
+// SetLoggerIfUnset registers l only if no logger has been registered yet.
+func SetLoggerIfUnset(l Logger) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if !defaultIsSet && l != nil {
+		defaultLogger, defaultIsSet = l, true
+	}
+}
+
+// UnsetLogger unregisters l if it's the registered logger, so the next
+// logger started takes its place. The logger package calls it on shutdown.
+func UnsetLogger(l Logger) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultIsSet && defaultLogger == l {
+		defaultLogger, defaultIsSet = nopLogger{}, false
+	}
+}
+
+// log returns the registered logger.
+func log() Logger {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultLogger
+}
+
+// Discard is a Logger that discards everything, for helpers whose logging
+// isn't wanted, such as an HTTPClient used from inside a log sink.
+var Discard Logger = nopLogger{}
+
+// nopLogger discards everything, used until a logger is registered.
+type nopLogger struct{}
+
+func (nopLogger) Debug(any)    {}
+func (nopLogger) Info(any)     {}
+func (nopLogger) Warning(any)  {}
+func (nopLogger) Error(any)    {}
+func (nopLogger) Critical(any) {}
+
+// Level picks which Logger method a helper logs through.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarning
+	LevelError
+)
+
+// of returns the method of l that logs at lv.
+func (lv Level) of(l Logger) func(any) {
+	switch lv {
+	case LevelInfo:
+		return l.Info
+	case LevelWarning:
+		return l.Warning
+	case LevelError:
+		return l.Error
+	}
+	return l.Debug
+}
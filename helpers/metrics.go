@@ -0,0 +1,216 @@
+package helpers
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Labels qualify a metric, e.g. Labels{"route": "/users"}.
+type Labels map[string]string
+
+// String formats labels the way Prometheus does: {a="1",b="2"}, sorted by name.
+func (l Labels) String() string {
+	if len(l) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(l))
+	for n := range l {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, n := range names {
+		parts[i] = n + "=" + strconv.Quote(l[n])
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// Metric is a Counter or a Gauge.
+type Metric interface {
+	Name() string
+	Labels() Labels
+	Value() float64
+	kind() string
+}
+
+// Counter is a monotonically increasing count, safe for concurrent use.
+type Counter struct {
+	name   string
+	labels Labels
+	v      atomic.Int64
+}
+
+// NewCounter creates a counter and registers it with DefaultRegistry. If a
+// counter with that name and labels is already registered, it's returned
+// instead, so both callers count in the same place. It panics if the name
+// and labels belong to a gauge.
+func NewCounter(name string, labels Labels) *Counter {
+	m := DefaultRegistry.getOrRegister(&Counter{name: name, labels: labels})
+	c, ok := m.(*Counter)
+	if !ok {
+		panic(fmt.Sprintf("helpers: metric %s%s is already registered as a %s", name, labels, m.kind()))
+	}
+	return c
+}
+
+// Inc adds one to the counter.
+func (c *Counter) Inc() { c.v.Add(1) }
+
+// Add adds n, which must not be negative, to the counter.
+func (c *Counter) Add(n int64) {
+	if n > 0 {
+		c.v.Add(n)
+	}
+}
+
+// Load returns the current count.
+func (c *Counter) Load() int64 { return c.v.Load() }
+
+func (c *Counter) Name() string   { return c.name }
+func (c *Counter) Labels() Labels { return c.labels }
+func (c *Counter) Value() float64 { return float64(c.v.Load()) }
+func (c *Counter) kind() string   { return "counter" }
+
+// Gauge is a value that can go up and down, safe for concurrent use.
+type Gauge struct {
+	name   string
+	labels Labels
+	bits   atomic.Uint64
+}
+
+// NewGauge creates a gauge and registers it with DefaultRegistry. As with
+// NewCounter, a gauge already registered with that name and labels is
+// returned instead, and it panics if they belong to a counter.
+func NewGauge(name string, labels Labels) *Gauge {
+	m := DefaultRegistry.getOrRegister(&Gauge{name: name, labels: labels})
+	g, ok := m.(*Gauge)
+	if !ok {
+		panic(fmt.Sprintf("helpers: metric %s%s is already registered as a %s", name, labels, m.kind()))
+	}
+	return g
+}
+
+// Set sets the gauge to v.
+func (g *Gauge) Set(v float64) { g.bits.Store(math.Float64bits(v)) }
+
+// Add adds d, which may be negative, to the gauge.
+func (g *Gauge) Add(d float64) {
+	for {
+		old := g.bits.Load()
+		if g.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+d)) {
+			return
+		}
+	}
+}
+
+func (g *Gauge) Name() string   { return g.name }
+func (g *Gauge) Labels() Labels { return g.labels }
+func (g *Gauge) Value() float64 { return math.Float64frombits(g.bits.Load()) }
+func (g *Gauge) kind() string   { return "gauge" }
+
+// Registry is a set of metrics reported together, by the logger's heartbeat
+// entry and by the Prometheus handler.
+type Registry struct {
+	mu      sync.RWMutex
+	metrics map[string]Metric
+}
+
+// DefaultRegistry is where NewCounter and NewGauge register their metrics.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{metrics: map[string]Metric{}}
+}
+
+// Register adds m to the registry. It returns an error if another metric
+// with the same name and labels is registered; registering m again does
+// nothing.
+func (r *Registry) Register(m Metric) error {
+	if got := r.getOrRegister(m); got != m {
+		return fmt.Errorf("metric %s%s is already registered", m.Name(), m.Labels())
+	}
+	return nil
+}
+
+// getOrRegister returns the metric registered with m's name and labels,
+// registering m if there's none.
+func (r *Registry) getOrRegister(m Metric) Metric {
+	key := m.Name() + m.Labels().String()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if got, ok := r.metrics[key]; ok {
+		return got
+	}
+	r.metrics[key] = m
+	return m
+}
+
+// Unregister removes m from the registry, if it's the metric registered
+// with its name and labels.
+func (r *Registry) Unregister(m Metric) {
+	key := m.Name() + m.Labels().String()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.metrics[key] == m {
+		delete(r.metrics, key)
+	}
+}
+
+// Metrics returns the registered metrics sorted by name and labels.
+func (r *Registry) Metrics() []Metric {
+	r.mu.RLock()
+	keys := make([]string, 0, len(r.metrics))
+	for k := range r.metrics {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]Metric, len(keys))
+	for i, k := range keys {
+		out[i] = r.metrics[k]
+	}
+	r.mu.RUnlock()
+	return out
+}
+
+// Summary formats every metric as name{labels}=value on one line, for log entries.
+func (r *Registry) Summary() string {
+	metrics := r.Metrics()
+	parts := make([]string, len(metrics))
+	for i, m := range metrics {
+		parts[i] = m.Name() + m.Labels().String() + "=" + strconv.FormatFloat(m.Value(), 'g', -1, 64)
+	}
+	return strings.Join(parts, " ")
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format.
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	typed := map[string]bool{}
+	for _, m := range r.Metrics() {
+		if !typed[m.Name()] {
+			typed[m.Name()] = true
+			if _, err := fmt.Fprintf(w, "# TYPE %s %s\n", m.Name(), m.kind()); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", m.Name(), m.Labels(), strconv.FormatFloat(m.Value(), 'g', -1, 64)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrometheusHandler serves the registry's metrics for Prometheus to scrape.
+func (r *Registry) PrometheusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		r.WritePrometheus(w)
+	})
+}
@@ -0,0 +1,37 @@
+package helpers
+
+import "testing"
+
+func TestRegistryRejectsDuplicates(t *testing.T) {
+	r := NewRegistry()
+	a := &Counter{name: "jobs_total", labels: Labels{"queue": "mail"}}
+	b := &Counter{name: "jobs_total", labels: Labels{"queue": "mail"}}
+	if err := r.Register(a); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register(a); err != nil {
+		t.Errorf("registering the same metric twice: %v", err)
+	}
+	if err := r.Register(b); err == nil {
+		t.Error("registering another metric with the same name and labels succeeded")
+	}
+	r.Unregister(b) // not the registered one, a stays
+	if got := r.Metrics(); len(got) != 1 || got[0] != Metric(a) {
+		t.Errorf("metrics after unregistering a duplicate = %v", got)
+	}
+}
+
+func TestNewCounterReturnsTheRegisteredOne(t *testing.T) {
+	a := NewCounter("test_merged_total", Labels{"k": "v"})
+	defer DefaultRegistry.Unregister(a)
+	b := NewCounter("test_merged_total", Labels{"k": "v"})
+	if a != b {
+		t.Fatal("NewCounter with the same name and labels returned a new counter")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("NewGauge with a counter's name and labels didn't panic")
+		}
+	}()
+	NewGauge("test_merged_total", Labels{"k": "v"})
+}
@@ -0,0 +1,29 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+)
+
+// Must returns v if err is nil. Otherwise it logs err as CRITICAL and exits,
+// which makes it handy for startup code:
+//
+//	cfg := helpers.Must(loadConfig())
+func Must[T any](v T, err error) T {
+	Check(err)
+	return v
+}
+
+// Check logs err as CRITICAL through the registered logger and exits if err
+// is not nil. The logger flushes queued entries before exiting.
+func Check(err error) {
+	if err == nil {
+		return
+	}
+	log().Critical(err)
+	// a *logger.Mylogger never returns from Critical; this covers the case
+	// where no logger is registered, or one that doesn't exit.
+	fmt.Fprintln(os.Stderr, "CRITICAL:", err)
+	RunExitHooks()
+	os.Exit(1)
+}
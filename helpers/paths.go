@@ -0,0 +1,126 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnsureDir creates path and any missing parents with the given permissions.
+// It is a no-op if the directory already exists.
+func EnsureDir(path string, perm os.FileMode) error {
+	if err := os.MkdirAll(path, perm); err != nil {
+		return fmt.Errorf("ensure dir %s: %w", path, err)
+	}
+	return nil
+}
+
+// IsEmptyDir reports whether the directory at path has no entries.
+func IsEmptyDir(path string) (bool, error) {
+	d, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("is empty dir %s: %w", path, err)
+	}
+	defer d.Close()
+	if _, err := d.Readdirnames(1); err != nil {
+		if errors.Is(err, io.EOF) {
+			return true, nil
+		}
+		return false, fmt.Errorf("is empty dir %s: %w", path, err)
+	}
+	return false, nil
+}
+
+// CopyFile copies the regular file src to dst, keeping src's permissions.
+// dst is written atomically.
+func CopyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("copy %s: %w", src, err)
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return fmt.Errorf("copy %s: %w", src, err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("copy %s: not a regular file", src)
+	}
+	if err := WriteReaderAtomic(dst, in, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("copy %s: %w", src, err)
+	}
+	return nil
+}
+
+// CopyDir recursively copies the directory src into dst, creating dst if needed.
+// Symlinks are recreated rather than followed.
+func CopyDir(src, dst string) error {
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		default:
+			return CopyFile(path, target)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("copy dir %s: %w", src, err)
+	}
+	return nil
+}
+
+// DiskUsage returns the total size in bytes of the regular files under path.
+func DiskUsage(path string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			total += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("disk usage %s: %w", path, err)
+	}
+	return total, nil
+}
+
+// ExpandHome replaces a leading "~" in path with the current user's home directory.
+func ExpandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("expand home %s: %w", path, err)
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
@@ -0,0 +1,68 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PIDLock is a held PID file, see LockPIDFile.
+type PIDLock struct {
+	path string
+	f    *os.File
+	once sync.Once
+}
+
+// LockPIDFile makes sure only one instance of the program runs at a time. It
+// creates path, locks it and writes the current PID into it. If another live
+// process holds the lock an error naming its PID is returned; a PID file left
+// behind by a process that died is reported and taken over. The lock is
+// released by Unlock or when the process exits through the exit hooks.
+func LockPIDFile(path string) (*PIDLock, error) {
+	f, err := lockFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if pid := readPID(f); pid > 0 && pid != os.Getpid() {
+		if processAlive(pid) {
+			log().Warning(fmt.Sprintf("pid file %s: taking over lock, pid %d is running but didn't hold it", path, pid))
+		} else {
+			log().Warning(fmt.Sprintf("pid file %s: removing stale lock left by pid %d", path, pid))
+		}
+	}
+	if err := f.Truncate(0); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("pid file %s: %w", path, err)
+	}
+	if _, err := f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("pid file %s: %w", path, err)
+	}
+	f.Sync()
+	p := &PIDLock{path: path, f: f}
+	OnExit(func() { p.Unlock() })
+	return p, nil
+}
+
+// Unlock removes the PID file and releases the lock. It's safe to call more than once.
+func (p *PIDLock) Unlock() error {
+	var err error
+	p.once.Do(func() {
+		os.Remove(p.path)
+		err = unlockFile(p.f)
+	})
+	return err
+}
+
+// readPID returns the PID stored in f, or 0.
+func readPID(f *os.File) int {
+	buf := make([]byte, 32)
+	n, _ := f.ReadAt(buf, 0)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(buf[:n])))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
@@ -0,0 +1,49 @@
+//go:build !unix
+
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// lockFile creates path exclusively. Without flock, an existing file is only
+// taken over if the PID in it is no longer running.
+func lockFile(path string) (*os.File, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("pid file %s: %w", path, err)
+		}
+		old, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("pid file %s: %w", path, err)
+		}
+		pid := readPID(old)
+		old.Close()
+		if pid > 0 && processAlive(pid) {
+			return nil, fmt.Errorf("pid file %s: already running as pid %d", path, pid)
+		}
+		log().Warning(fmt.Sprintf("pid file %s: removing stale lock left by pid %d", path, pid))
+		os.Remove(path)
+	}
+	return nil, fmt.Errorf("pid file %s: could not take over stale lock", path)
+}
+
+func unlockFile(f *os.File) error {
+	return f.Close()
+}
+
+// processAlive reports whether a process with the given PID exists.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
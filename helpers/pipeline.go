@@ -0,0 +1,97 @@
+package helpers
+
+import (
+	"context"
+	"sync"
+)
+
+// Source emits items on a channel that's closed once they're all sent or ctx
+// is done. It's the usual first step of a pipeline.
+func Source[T any](ctx context.Context, items ...T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for _, it := range items {
+			select {
+			case out <- it:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Stage is one step of a pipeline: workers goroutines read from in, apply fn,
+// and send the results on the returned output channel. Items for which fn
+// fails go to the returned error channel instead, which must be drained (or
+// ctx cancelled) for the stage to make progress. Output order is not
+// preserved when workers > 1. Both channels are closed once in is closed and
+// drained, or ctx is done; panics in fn are recovered and reported as errors.
+func Stage[In, Out any](ctx context.Context, in <-chan In, workers int, fn func(context.Context, In) (Out, error)) (<-chan Out, <-chan error) {
+	if workers < 1 {
+		workers = 1
+	}
+	out := make(chan Out)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				var item In
+				var ok bool
+				select {
+				case <-ctx.Done():
+					return
+				case item, ok = <-in:
+					if !ok {
+						return
+					}
+				}
+				var res Out
+				err := Safe(func() error {
+					var err error
+					res, err = fn(ctx, item)
+					return err
+				})
+				if err != nil {
+					select {
+					case errs <- err:
+					case <-ctx.Done():
+						return
+					}
+					continue
+				}
+				select {
+				case out <- res:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+		close(errs)
+	}()
+	return out, errs
+}
+
+// Collect reads in until it's closed or ctx is done and returns what it read.
+func Collect[T any](ctx context.Context, in <-chan T) []T {
+	var out []T
+	for {
+		select {
+		case v, ok := <-in:
+			if !ok {
+				return out
+			}
+			out = append(out, v)
+		case <-ctx.Done():
+			return out
+		}
+	}
+}
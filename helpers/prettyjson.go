@@ -0,0 +1,111 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jeanhaley32/logger/colors"
+)
+
+var (
+	// payloads longer than this are cut by PrettyJSON.
+	prettyJSONLimit = 16 << 10
+)
+
+// PrettyJSON marshals v as indented JSON with every object's keys sorted,
+// cut to 16 KiB. Values that can't be marshaled are described instead.
+func PrettyJSON(v any) string {
+	return PrettyJSONN(v, prettyJSONLimit)
+}
+
+// PrettyJSONN is PrettyJSON cut to at most max bytes, on a rune boundary, or
+// not at all if max < 0.
+func PrettyJSONN(v any, max int) string {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("<%T: %v>", v, err)
+	}
+	// round trip through a generic value, so struct fields get sorted like map keys.
+	var generic any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&generic); err != nil {
+		return fmt.Sprintf("<%T: %v>", v, err)
+	}
+	out, err := json.MarshalIndent(generic, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("<%T: %v>", v, err)
+	}
+	if max >= 0 && len(out) > max {
+		// cut before the rune that would be split.
+		n := max
+		for n > 0 && !utf8.RuneStart(out[n]) {
+			n--
+		}
+		return string(out[:n]) + fmt.Sprintf("\n... %s more", Bytes(int64(len(out)-n)))
+	}
+	return string(out)
+}
+
+// HighlightJSON colors JSON text for a terminal: keys blue, strings green,
+// numbers yellow, and true, false and null purple.
+func HighlightJSON(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j+1, len(s))
+			// a string followed by a colon is a key.
+			k := j
+			for k < len(s) && (s[k] == ' ' || s[k] == '\t') {
+				k++
+			}
+			if k < len(s) && s[k] == ':' {
+				sb.WriteString(colors.Wrap(colors.BLUE, s[i:j]))
+			} else {
+				sb.WriteString(colors.Wrap(colors.GREEN, s[i:j]))
+			}
+			i = j
+		case c == '-' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(s) && strings.IndexByte("0123456789.eE+-", s[j]) >= 0 {
+				j++
+			}
+			sb.WriteString(colors.Wrap(colors.YELLOW, s[i:j]))
+			i = j
+		case strings.HasPrefix(s[i:], "true"), strings.HasPrefix(s[i:], "null"):
+			sb.WriteString(colors.Wrap(colors.PURPLE, s[i:i+4]))
+			i += 4
+		case strings.HasPrefix(s[i:], "false"):
+			sb.WriteString(colors.Wrap(colors.PURPLE, s[i:i+5]))
+			i += 5
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+	return sb.String()
+}
+
+// IsTerminal reports whether w is a character device such as a terminal.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,18 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPrettyJSONNCutsOnRunes(t *testing.T) {
+	// "héllo" marshals to "h then the two bytes of é; 3 bytes would split é.
+	got := PrettyJSONN("héllo", 3)
+	if !utf8.ValidString(got) || !strings.HasPrefix(got, "\"h\n... ") {
+		t.Errorf("PrettyJSONN = %q, want it cut before é", got)
+	}
+	if got := PrettyJSONN("héllo", 4); !strings.HasPrefix(got, "\"hé\n... ") {
+		t.Errorf("PrettyJSONN = %q, want it cut after é", got)
+	}
+}
@@ -0,0 +1,33 @@
+package helpers
+
+// Ptr returns a pointer to a copy of v, for filling optional fields with
+// literals: Options{Timeout: helpers.Ptr(5 * time.Second)}.
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// Deref returns *p, or def if p is nil.
+func Deref[T any](p *T, def T) T {
+	if p == nil {
+		return def
+	}
+	return *p
+}
+
+// IsZero reports whether v is the zero value of its type.
+func IsZero[T comparable](v T) bool {
+	var zero T
+	return v == zero
+}
+
+// Coalesce returns the first of vals that isn't the zero value, or the zero
+// value if they all are.
+func Coalesce[T comparable](vals ...T) T {
+	var zero T
+	for _, v := range vals {
+		if v != zero {
+			return v
+		}
+	}
+	return zero
+}
@@ -0,0 +1,61 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// RandString returns n characters picked uniformly from alphabet with
+// crypto/rand. It returns "" if n isn't positive or alphabet is empty.
+func RandString(n int, alphabet string) string {
+	chars := []rune(alphabet)
+	if n <= 0 || len(chars) == 0 {
+		return ""
+	}
+	out := make([]rune, n)
+	max := big.NewInt(int64(len(chars)))
+	for i := range out {
+		v, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic("helpers: crypto/rand failed: " + err.Error())
+		}
+		out[i] = chars[v.Int64()]
+	}
+	return string(out)
+}
+
+// RandInt returns a uniformly random integer in [min, max] from crypto/rand.
+func RandInt(min, max int64) (int64, error) {
+	if max < min {
+		return 0, fmt.Errorf("rand int: max %d < min %d", max, min)
+	}
+	span := new(big.Int).Sub(big.NewInt(max), big.NewInt(min))
+	span.Add(span, big.NewInt(1))
+	v, err := rand.Int(rand.Reader, span)
+	if err != nil {
+		return 0, fmt.Errorf("rand int: %w", err)
+	}
+	return v.Int64() + min, nil
+}
+
+// SecureToken returns n random bytes encoded as unpadded URL safe base64,
+// suitable for session tokens, API keys and CSRF tokens.
+func SecureToken(n int) string {
+	b := make([]byte, n)
+	mustRead(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Equal compares a and b in constant time, so comparing secrets doesn't leak
+// how much of them matched.
+func Equal(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// EqualString is Equal for strings.
+func EqualString(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,136 @@
+package helpers
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// environment variables used to hand listeners to the restarted process.
+const (
+	restartFdsEnv   = "RESTART_LISTEN_FDS"   // number of inherited listeners
+	restartAddrsEnv = "RESTART_LISTEN_ADDRS" // their addresses, comma separated
+	restartReadyEnv = "RESTART_READY_FD"     // pipe to signal readiness on
+)
+
+// Restarter provides zero downtime restarts. Listeners created through it
+// are handed to a fresh copy of the program when the process receives
+// SIGUSR2; once the new process calls Ready, the old one stops accepting and
+// shuts down through the logger, draining in-flight work.
+type Restarter struct {
+	l         Logger
+	mu        sync.Mutex
+	listeners []restartListener
+	inherited map[string]net.Listener
+	ready     *os.File
+}
+
+// restartListener is a listener with the address it was asked for, which is
+// how the next process matches it up.
+type restartListener struct {
+	addr string
+	ln   net.Listener
+}
+
+// NewRestarter returns a Restarter logging to l, or to the registered logger
+// if l is nil. When the process was started by a restart, it picks up the
+// listeners handed over by the previous process.
+func NewRestarter(l Logger) (*Restarter, error) {
+	if l == nil {
+		l = log()
+	}
+	r := &Restarter{l: l, inherited: map[string]net.Listener{}}
+	n, err := strconv.Atoi(os.Getenv(restartFdsEnv))
+	if err != nil || n <= 0 {
+		return r, nil
+	}
+	addrs := strings.Split(os.Getenv(restartAddrsEnv), ",")
+	if len(addrs) != n {
+		return nil, fmt.Errorf("restart: %d listener fds but %d addresses", n, len(addrs))
+	}
+	for i := 0; i < n; i++ {
+		fd := listenFdsStart + i
+		f := os.NewFile(uintptr(fd), "restart-fd-"+strconv.Itoa(fd))
+		ln, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("restart: inherit fd %d: %w", fd, err)
+		}
+		r.inherited[addrs[i]] = ln
+		l.Info(fmt.Sprintf("restart: inherited listener %s (fd %d)", addrs[i], fd))
+	}
+	if fd, err := strconv.Atoi(os.Getenv(restartReadyEnv)); err == nil {
+		r.ready = os.NewFile(uintptr(fd), "restart-ready")
+	}
+	for _, env := range []string{restartFdsEnv, restartAddrsEnv, restartReadyEnv} {
+		os.Unsetenv(env)
+	}
+	return r, nil
+}
+
+// Listen returns the listener inherited for network and addr if there is
+// one, and otherwise listens with ListenWithRetry.
+func (r *Restarter) Listen(network, addr string) (net.Listener, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ln, ok := r.inherited[addr]
+	if ok {
+		delete(r.inherited, addr)
+	} else {
+		if network != "tcp" {
+			return nil, fmt.Errorf("restart: listen %s %s: only tcp is supported", network, addr)
+		}
+		var err error
+		if ln, err = ListenWithRetry(addr); err != nil {
+			return nil, err
+		}
+	}
+	r.listeners = append(r.listeners, restartListener{addr: addr, ln: ln})
+	return ln, nil
+}
+
+// Ready tells the previous process that this one is serving, so it can shut
+// down. Inherited listeners that weren't claimed with Listen are closed. It's
+// a no-op when the process wasn't started by a restart.
+func (r *Restarter) Ready() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for addr, ln := range r.inherited {
+		r.l.Warning(fmt.Sprintf("restart: closing unused inherited listener %s", addr))
+		ln.Close()
+		delete(r.inherited, addr)
+	}
+	if r.ready == nil {
+		return nil
+	}
+	_, err := r.ready.Write([]byte{1})
+	r.ready.Close()
+	r.ready = nil
+	if err != nil {
+		return fmt.Errorf("restart: signal ready: %w", err)
+	}
+	r.l.Info("restart: told previous process we're ready")
+	return nil
+}
+
+// shutdowner is implemented by *logger.Mylogger.
+type shutdowner interface {
+	Shutdown(error) bool
+}
+
+// handover stops accepting on the old process's listeners and shuts it down
+// through the logger, if the logger supports it.
+func (r *Restarter) handover() {
+	r.mu.Lock()
+	for _, rl := range r.listeners {
+		rl.ln.Close()
+	}
+	r.mu.Unlock()
+	r.l.Info("restart: handover complete, draining in-flight work")
+	if s, ok := r.l.(shutdowner); ok {
+		s.Shutdown(nil)
+	}
+}
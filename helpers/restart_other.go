@@ -0,0 +1,23 @@
+//go:build !unix
+
+package helpers
+
+import (
+	"context"
+	"errors"
+)
+
+// errRestartUnsupported is returned by Restart on platforms without SIGUSR2.
+var errRestartUnsupported = errors.New("restart: not supported on this platform")
+
+// Run is not supported on this platform; it waits for ctx and returns its error.
+func (r *Restarter) Run(ctx context.Context) error {
+	r.l.Warning(errRestartUnsupported.Error())
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// Restart is not supported on this platform.
+func (r *Restarter) Restart() error {
+	return errRestartUnsupported
+}
@@ -0,0 +1,114 @@
+//go:build unix
+
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// how long the new process has to call Ready before the restart is abandoned.
+var restartReadyTimeout = 30 * time.Second
+
+// Run waits for SIGUSR2 and restarts the program when it arrives. After a
+// successful handover it shuts the old process down and returns nil. A failed
+// restart is logged and the current process keeps serving. Run returns
+// ctx.Err() when ctx is done.
+func (r *Restarter) Run(ctx context.Context) error {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGUSR2)
+	defer signal.Stop(sigs)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-sigs:
+		}
+		r.l.Info("restart: received SIGUSR2, starting new process")
+		if err := r.Restart(); err != nil {
+			r.l.Error(err)
+			continue
+		}
+		r.handover()
+		return nil
+	}
+}
+
+// Restart starts a new copy of the program with the current listeners and
+// waits for it to call Ready. It doesn't stop the current process; Run does
+// that after a successful Restart.
+func (r *Restarter) Restart() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("restart: %w", err)
+	}
+	r.mu.Lock()
+	var files []*os.File
+	var addrs []string
+	for _, rl := range r.listeners {
+		fl, ok := rl.ln.(interface{ File() (*os.File, error) })
+		if !ok {
+			r.mu.Unlock()
+			return fmt.Errorf("restart: listener %s can't be passed on", rl.addr)
+		}
+		f, err := fl.File()
+		if err != nil {
+			r.mu.Unlock()
+			return fmt.Errorf("restart: listener %s: %w", rl.addr, err)
+		}
+		defer f.Close()
+		files = append(files, f)
+		addrs = append(addrs, rl.addr)
+	}
+	r.mu.Unlock()
+
+	readR, readW, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("restart: %w", err)
+	}
+	defer readR.Close()
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = append(files, readW)
+	cmd.Env = append(os.Environ(),
+		restartFdsEnv+"="+strconv.Itoa(len(files)),
+		restartAddrsEnv+"="+strings.Join(addrs, ","),
+		restartReadyEnv+"="+strconv.Itoa(listenFdsStart+len(files)),
+	)
+	if err := cmd.Start(); err != nil {
+		readW.Close()
+		return fmt.Errorf("restart: start %s: %w", exe, err)
+	}
+	readW.Close()
+	r.l.Info(fmt.Sprintf("restart: started pid %d with %d listeners, waiting for it to be ready", cmd.Process.Pid, len(files)))
+
+	ready := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		_, err := readR.Read(buf)
+		ready <- err
+	}()
+	select {
+	case err := <-ready:
+		if err != nil {
+			cmd.Process.Kill()
+			cmd.Wait()
+			return fmt.Errorf("restart: pid %d exited before it was ready", cmd.Process.Pid)
+		}
+	case <-time.After(restartReadyTimeout):
+		cmd.Process.Kill()
+		cmd.Wait()
+		return fmt.Errorf("restart: pid %d not ready after %s, killed it", cmd.Process.Pid, restartReadyTimeout)
+	}
+	// the new process outlives this one; don't leave it as a zombie in the meantime.
+	go cmd.Wait()
+	r.l.Info(fmt.Sprintf("restart: pid %d is ready", cmd.Process.Pid))
+	return nil
+}
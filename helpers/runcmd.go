@@ -0,0 +1,153 @@
+package helpers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CmdOptions configures RunCmd.
+type CmdOptions struct {
+	Logger    Logger        // defaults to the registered logger
+	Timeout   time.Duration // kill the command after this long, 0 for no limit
+	MaxOutput int64         // bytes logged per stream, 0 for no limit; the rest is counted and dropped
+	Prefix    string        // prepended to each logged line, defaults to the command name
+	WaitDelay time.Duration // how long output is read after the command exits or is killed, default 5s
+}
+
+// defaultWaitDelay is how long RunCmd waits for the output pipes to close
+// once the command is gone, e.g. when a grandchild still holds them.
+const defaultWaitDelay = 5 * time.Second
+
+// CmdResult describes a finished command.
+type CmdResult struct {
+	ExitCode  int // -1 if the command was killed or never started
+	Duration  time.Duration
+	Truncated bool // true if output went over MaxOutput
+}
+
+// RunCmd runs cmd, logging each line it writes to stdout at INFO and to stderr
+// at WARNING as it happens. cmd must not have Stdout or Stderr set. The
+// command is killed when ctx is done or the timeout passes. A non-zero exit
+// is reported both in the result and as an error. On unix the command runs
+// in its own process group, which is killed as a whole, so children it
+// started don't outlive it; output still open WaitDelay after that is
+// abandoned.
+func RunCmd(ctx context.Context, cmd *exec.Cmd, opts CmdOptions) (CmdResult, error) {
+	l := opts.Logger
+	if l == nil {
+		l = log()
+	}
+	prefix := opts.Prefix
+	if prefix == "" {
+		prefix = filepath.Base(cmd.Path)
+	}
+	res := CmdResult{ExitCode: -1}
+	if cmd.Stdout != nil || cmd.Stderr != nil {
+		return res, fmt.Errorf("run %s: Stdout or Stderr already set", prefix)
+	}
+	// exec copies the output into these, and stops after WaitDelay, so a
+	// grandchild holding the pipes open can't keep Wait from returning.
+	stdout, outw := io.Pipe()
+	stderr, errw := io.Pipe()
+	cmd.Stdout, cmd.Stderr = outw, errw
+	cmd.WaitDelay = opts.WaitDelay
+	if cmd.WaitDelay <= 0 {
+		cmd.WaitDelay = defaultWaitDelay
+	}
+	setProcessGroup(cmd)
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return res, fmt.Errorf("run %s: %w", prefix, err)
+	}
+	l.Debug(fmt.Sprintf("%s: started pid %d", prefix, cmd.Process.Pid))
+
+	// kill the process group if ctx ends before the command exits.
+	exited := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			killProcessGroup(cmd)
+		case <-exited:
+		}
+	}()
+
+	var wg sync.WaitGroup
+	var truncOut, truncErr bool
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		truncOut = streamLines(stdout, opts.MaxOutput, func(s string) { l.Info(prefix + ": " + s) })
+	}()
+	go func() {
+		defer wg.Done()
+		truncErr = streamLines(stderr, opts.MaxOutput, func(s string) { l.Warning(prefix + ": " + s) })
+	}()
+	err := cmd.Wait()
+	close(exited)
+	outw.Close()
+	errw.Close()
+	wg.Wait()
+	if errors.Is(err, exec.ErrWaitDelay) {
+		l.Warning(fmt.Sprintf("%s: output still open %s after exit, stopped reading it", prefix, cmd.WaitDelay))
+		err = nil
+	}
+	res.Duration = time.Since(start)
+	res.Truncated = truncOut || truncErr
+	if cmd.ProcessState != nil {
+		res.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	// a command that finished before ctx ended wasn't killed, whatever ctx
+	// says now.
+	if ps := cmd.ProcessState; ps != nil && !ps.Exited() {
+		if ctx.Err() != nil {
+			return res, fmt.Errorf("run %s: killed after %s: %w", prefix, res.Duration.Round(time.Millisecond), ctx.Err())
+		}
+		return res, fmt.Errorf("run %s: %s", prefix, ps)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return res, fmt.Errorf("run %s: exit code %d", prefix, res.ExitCode)
+	}
+	if err != nil {
+		return res, fmt.Errorf("run %s: %w", prefix, err)
+	}
+	l.Debug(fmt.Sprintf("%s: exited with code %d after %s", prefix, res.ExitCode, res.Duration.Round(time.Millisecond)))
+	return res, nil
+}
+
+// streamLines calls fn with each line read from r until a line would take it
+// over limit bytes, then discards the rest. It reports whether anything was
+// dropped.
+func streamLines(r io.Reader, limit int64, fn func(string)) bool {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	var seen, dropped int64
+	for sc.Scan() {
+		line := sc.Text()
+		if dropped > 0 || limit > 0 && seen+int64(len(line)) > limit {
+			dropped += int64(len(line)) + 1
+			continue
+		}
+		seen += int64(len(line)) + 1
+		fn(line)
+	}
+	// keep reading after a scan error so the command can't block on a full pipe.
+	n, _ := io.Copy(io.Discard, r)
+	dropped += n
+	if dropped > 0 {
+		fn(fmt.Sprintf("[%d bytes of output dropped]", dropped))
+	}
+	return dropped > 0
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package helpers
+
+import "os/exec"
+
+// setProcessGroup does nothing: there are no process groups to put cmd in.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills cmd; its children are left running. A killed
+// command looks like one that exited with code 1, so RunCmd reports it as
+// such.
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
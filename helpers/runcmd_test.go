@@ -0,0 +1,35 @@
+package helpers
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamLinesStopsAtLimit(t *testing.T) {
+	var got []string
+	dropped := streamLines(strings.NewReader("one\nthree33\ntwo\n"), 8, func(s string) { got = append(got, s) })
+	want := []string{"one", "[12 bytes of output dropped]"}
+	if !dropped || strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("logged %q (dropped %v), want %q", got, dropped, want)
+	}
+}
+
+func TestRunCmdKilled(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh")
+	}
+	_, err := RunCmd(context.Background(), exec.Command("sh", "-c", "sleep 5"), CmdOptions{Logger: Discard, Timeout: 50 * time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "killed after") {
+		t.Errorf("timed out command: err = %v, want killed", err)
+	}
+
+	// a command that exits before the timeout isn't killed, even though a
+	// child holding its output is.
+	_, err = RunCmd(context.Background(), exec.Command("sh", "-c", "sleep 5 & exit 3"), CmdOptions{Logger: Discard, Timeout: 100 * time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "exit code 3") {
+		t.Errorf("exit 3: err = %v, want exit code 3", err)
+	}
+}
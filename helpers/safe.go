@@ -0,0 +1,48 @@
+package helpers
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned by Safe when the function it ran panicked.
+type PanicError struct {
+	Value any    // the value passed to panic
+	Stack []byte // stack of the panicking goroutine
+}
+
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n%s", p.Value, p.Stack)
+}
+
+// Unwrap returns the panic value if it was an error.
+func (p *PanicError) Unwrap() error {
+	if err, ok := p.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// Safe runs fn and returns its error, converting a panic into a *PanicError
+// carrying the stack.
+func Safe(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn()
+}
+
+// SafeGo runs fn in a new goroutine through Safe, and logs its error or panic
+// to l. A nil l logs to the registered logger.
+func SafeGo(l Logger, fn func() error) {
+	if l == nil {
+		l = log()
+	}
+	go func() {
+		if err := Safe(fn); err != nil {
+			l.Error(err)
+		}
+	}()
+}
@@ -0,0 +1,203 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Overlap decides what happens when a job is due while its previous run is
+// still going.
+type Overlap int
+
+const (
+	Skip       Overlap = iota // drop the new run
+	Queue                     // run it once the current run finishes
+	Concurrent                // run it alongside the current run
+)
+
+func (o Overlap) String() string {
+	switch o {
+	case Skip:
+		return "skip"
+	case Queue:
+		return "queue"
+	case Concurrent:
+		return "concurrent"
+	}
+	return "unknown"
+}
+
+// JobOption configures a job added to a Scheduler.
+type JobOption func(*job)
+
+// WithOverlap sets the overlap policy of a job. The default is Skip.
+func WithOverlap(o Overlap) JobOption {
+	return func(j *job) { j.overlap = o }
+}
+
+// WithJitter delays each run of a job by a random duration up to max, so
+// jobs on many instances don't all fire at the same moment.
+func WithJitter(max time.Duration) JobOption {
+	return func(j *job) { j.jitter = max }
+}
+
+// maximum number of runs waiting behind a running Queue job.
+const maxQueuedRuns = 16
+
+// job is a function registered with a Scheduler.
+type job struct {
+	name     string
+	schedule Schedule
+	fn       func(context.Context) error
+	overlap  Overlap
+	jitter   time.Duration
+	running  atomic.Bool
+	queue    chan struct{}
+}
+
+// Scheduler runs jobs on cron schedules or fixed intervals, logging when each
+// run starts, finishes or fails. Panics in a job are recovered and logged as
+// errors.
+type Scheduler struct {
+	l    Logger
+	mu   sync.Mutex
+	jobs []*job
+	wg   sync.WaitGroup
+}
+
+// NewScheduler returns a Scheduler logging to l, or to the registered logger
+// if l is nil.
+func NewScheduler(l Logger) *Scheduler {
+	if l == nil {
+		l = log()
+	}
+	return &Scheduler{l: l}
+}
+
+// Cron adds a job run according to the cron expression expr, see ParseCron.
+func (s *Scheduler) Cron(name, expr string, fn func(context.Context) error, opts ...JobOption) error {
+	sched, err := ParseCron(expr)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", name, err)
+	}
+	s.add(name, sched, fn, opts)
+	return nil
+}
+
+// Every adds a job run every d.
+func (s *Scheduler) Every(name string, d time.Duration, fn func(context.Context) error, opts ...JobOption) error {
+	if d <= 0 {
+		return fmt.Errorf("job %s: interval must be positive", name)
+	}
+	s.add(name, everySchedule{d}, fn, opts)
+	return nil
+}
+
+func (s *Scheduler) add(name string, sched Schedule, fn func(context.Context) error, opts []JobOption) {
+	j := &job{name: name, schedule: sched, fn: fn, queue: make(chan struct{}, maxQueuedRuns)}
+	for _, o := range opts {
+		o(j)
+	}
+	s.mu.Lock()
+	s.jobs = append(s.jobs, j)
+	s.mu.Unlock()
+}
+
+// Run starts every job and blocks until ctx is done and all running jobs
+// have returned.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	jobs := append([]*job(nil), s.jobs...)
+	s.mu.Unlock()
+	for _, j := range jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+		if j.overlap == Queue {
+			s.wg.Add(1)
+			go s.worker(ctx, j)
+		}
+	}
+	<-ctx.Done()
+	s.wg.Wait()
+}
+
+// loop sleeps until each activation of j and triggers it.
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	defer s.wg.Done()
+	for {
+		next := j.schedule.Next(time.Now())
+		if next.IsZero() {
+			s.l.Warning(fmt.Sprintf("job %s: schedule never fires again", j.name))
+			return
+		}
+		if j.jitter > 0 {
+			next = next.Add(time.Duration(rand.Int63n(int64(j.jitter))))
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.trigger(ctx, j)
+	}
+}
+
+// trigger starts a run of j according to its overlap policy.
+func (s *Scheduler) trigger(ctx context.Context, j *job) {
+	switch j.overlap {
+	case Concurrent:
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.run(ctx, j)
+		}()
+	case Queue:
+		select {
+		case j.queue <- struct{}{}:
+		default:
+			s.l.Warning(fmt.Sprintf("job %s: %d runs already queued, dropping this one", j.name, maxQueuedRuns))
+		}
+	default:
+		if !j.running.CompareAndSwap(false, true) {
+			s.l.Warning(fmt.Sprintf("job %s: previous run still going, skipping", j.name))
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer j.running.Store(false)
+			s.run(ctx, j)
+		}()
+	}
+}
+
+// worker runs the queued activations of a Queue job one at a time.
+func (s *Scheduler) worker(ctx context.Context, j *job) {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-j.queue:
+			s.run(ctx, j)
+		}
+	}
+}
+
+// run calls the job function once, logging the outcome.
+func (s *Scheduler) run(ctx context.Context, j *job) {
+	s.l.Info(fmt.Sprintf("job %s: started", j.name))
+	start := time.Now()
+	err := Safe(func() error { return j.fn(ctx) })
+	if err != nil {
+		s.l.Error(fmt.Sprintf("job %s: failed after %s: %v", j.name, time.Since(start), err))
+		return
+	}
+	s.l.Info(fmt.Sprintf("job %s: finished in %s", j.name, time.Since(start)))
+}
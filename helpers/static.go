@@ -0,0 +1,184 @@
+package helpers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// files larger than this are served uncompressed, rather than held in memory.
+const maxGzipSize = 8 << 20
+
+// fingerprintRe matches file names carrying a content hash, app.3f2a9c1d.js,
+// which are safe to cache forever.
+var fingerprintRe = regexp.MustCompile(`[.-][0-9a-fA-F]{8,}\.[^./]+$`)
+
+// StaticHandler serves files from a file system, see ServeStatic. Its fields
+// can be changed before it starts serving.
+type StaticHandler struct {
+	MaxAge time.Duration // Cache-Control max-age of files without a hash in their name
+	Level  Level         // level of the access log, server errors are logged at LevelError
+	Logger Logger        // defaults to the registered logger
+
+	fsys   fs.FS
+	prefix string
+	once   sync.Once
+	h      http.Handler
+
+	mu    sync.Mutex
+	cache map[string]*staticFile // by path
+}
+
+// staticFile is what's kept of a served file: its ETag, and its gzipped
+// content if that's worth sending.
+type staticFile struct {
+	mod  time.Time
+	size int64
+	etag string
+	gz   []byte
+}
+
+// ServeStatic returns a handler serving the files of fsys, typically an
+// embed.FS, under the URL path prefix. Files get an ETag, and a
+// Cache-Control header: a year, immutable, for names with a content hash
+// (app.3f2a9c1d.js), MaxAge (an hour by default) otherwise. Text files are
+// gzipped for clients that accept it. A directory serves its index.html;
+// directories aren't listed. Every request is logged with AccessLog at
+// Level, DEBUG by default.
+func ServeStatic(fsys fs.FS, prefix string) *StaticHandler {
+	return &StaticHandler{
+		MaxAge: time.Hour,
+		fsys:   fsys,
+		prefix: "/" + strings.Trim(prefix, "/"),
+		cache:  map[string]*staticFile{},
+	}
+}
+
+func (s *StaticHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.once.Do(func() { s.h = AccessLog(s.Logger, s.Level, http.HandlerFunc(s.serve)) })
+	s.h.ServeHTTP(w, r)
+}
+
+// serve serves a single file.
+func (s *StaticHandler) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	p := path.Clean("/" + r.URL.Path)
+	if s.prefix != "/" {
+		if p != s.prefix && !strings.HasPrefix(p, s.prefix+"/") {
+			http.NotFound(w, r)
+			return
+		}
+		p = strings.TrimPrefix(p, s.prefix)
+	}
+	name := strings.TrimPrefix(p, "/")
+	if name == "" {
+		name = "."
+	}
+	info, err := fs.Stat(s.fsys, name)
+	if err == nil && info.IsDir() {
+		name = path.Join(name, "index.html")
+		info, err = fs.Stat(s.fsys, name)
+	}
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	data, err := fs.ReadFile(s.fsys, name)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	f := s.file(name, info, data)
+
+	h := w.Header()
+	if fingerprintRe.MatchString(name) {
+		h.Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		h.Set("Cache-Control", "public, max-age="+strconv.Itoa(int(s.MaxAge.Seconds())))
+	}
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		h.Set("Content-Type", ct)
+	}
+	content := data
+	etag := f.etag
+	if f.gz != nil {
+		h.Add("Vary", "Accept-Encoding")
+		if acceptsGzip(r) {
+			h.Set("Content-Encoding", "gzip")
+			content, etag = f.gz, strings.TrimSuffix(etag, `"`)+`-gzip"`
+		}
+	}
+	h.Set("ETag", etag)
+	http.ServeContent(w, r, name, info.ModTime(), bytes.NewReader(content))
+}
+
+// file returns the cached details of the file name, computing them if it's
+// new or has changed.
+func (s *StaticHandler) file(name string, info fs.FileInfo, data []byte) *staticFile {
+	s.mu.Lock()
+	f, ok := s.cache[name]
+	s.mu.Unlock()
+	if ok && f.mod.Equal(info.ModTime()) && f.size == info.Size() {
+		return f
+	}
+	sum := sha256.Sum256(data)
+	f = &staticFile{mod: info.ModTime(), size: info.Size(), etag: `"` + hex.EncodeToString(sum[:8]) + `"`}
+	if compressible(name) && len(data) >= 1024 && len(data) <= maxGzipSize {
+		var buf bytes.Buffer
+		zw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		zw.Write(data)
+		zw.Close()
+		if buf.Len() < len(data) {
+			f.gz = buf.Bytes()
+		}
+	}
+	s.mu.Lock()
+	s.cache[name] = f
+	s.mu.Unlock()
+	return f
+}
+
+// compressible reports whether a file's type is worth gzipping.
+func compressible(name string) bool {
+	ct := mime.TypeByExtension(path.Ext(name))
+	if ct == "" {
+		return false
+	}
+	ct, _, _ = strings.Cut(ct, ";")
+	switch {
+	case strings.HasPrefix(ct, "text/"):
+		return true
+	case strings.HasSuffix(ct, "+xml"), strings.HasSuffix(ct, "+json"):
+		return true
+	}
+	switch ct {
+	case "application/javascript", "application/json", "application/xml", "application/wasm", "image/svg+xml":
+		return true
+	}
+	return false
+}
+
+// acceptsGzip reports whether the client accepts gzip responses.
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		enc, q, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.TrimSpace(enc) == "gzip" && strings.ReplaceAll(q, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+// Package strs holds the small string chores shared by the formatters:
+// ANSI aware truncation and padding, slugs, case conversion and redaction.
+package strs
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const reset = "\033[0m"
+
+// ansiLen returns the length of the ANSI escape sequence at the start of s,
+// or 0 if s doesn't start with one.
+func ansiLen(s string) int {
+	if len(s) < 2 || s[0] != '\033' || s[1] != '[' {
+		return 0
+	}
+	for i := 2; i < len(s); i++ {
+		if c := s[i]; c >= 0x40 && c <= 0x7e {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// StripANSI removes ANSI escape sequences from s.
+func StripANSI(s string) string {
+	if !strings.Contains(s, "\033[") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		if n := ansiLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		sb.WriteByte(s[i])
+		i++
+	}
+	return sb.String()
+}
+
+// VisibleLen returns the number of runes in s that take up space on a
+// terminal, ignoring ANSI escape sequences.
+func VisibleLen(s string) int {
+	return utf8.RuneCountInString(StripANSI(s))
+}
+
+// TruncateWithEllipsis shortens s to at most max visible runes, ending it with
+// "…" when something was cut. Escape sequences are kept intact, and a color
+// left open by the cut is reset.
+func TruncateWithEllipsis(s string, max int) string {
+	if VisibleLen(s) <= max {
+		return s
+	}
+	if max <= 0 {
+		return ""
+	}
+	var sb strings.Builder
+	colored := false
+	visible := 0
+	for i := 0; i < len(s); {
+		if n := ansiLen(s[i:]); n > 0 {
+			seq := s[i : i+n]
+			sb.WriteString(seq)
+			colored = seq != reset
+			i += n
+			continue
+		}
+		if visible == max-1 {
+			break
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		sb.WriteRune(r)
+		visible++
+		i += size
+	}
+	sb.WriteString("…")
+	if colored {
+		sb.WriteString(reset)
+	}
+	return sb.String()
+}
+
+// PadRight pads s with spaces on the right to width visible runes.
+func PadRight(s string, width int) string {
+	if n := VisibleLen(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// PadLeft pads s with spaces on the left to width visible runes.
+func PadLeft(s string, width int) string {
+	if n := VisibleLen(s); n < width {
+		return strings.Repeat(" ", width-n) + s
+	}
+	return s
+}
+
+// Slugify lowercases s and replaces runs of anything that isn't a letter or a
+// digit with a single dash: "Hello, World!" becomes "hello-world".
+func Slugify(s string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(StripANSI(s)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return sb.String()
+}
+
+// CamelToSnake converts CamelCase to snake_case, keeping acronyms together:
+// "HTTPServerID" becomes "http_server_id".
+func CamelToSnake(s string) string {
+	runes := []rune(s)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			prevUpper := i > 0 && unicode.IsUpper(runes[i-1])
+			if prevLower || (prevUpper && nextLower) {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// Redact hides the middle of s behind asterisks, leaving keep runes visible at
+// each end: Redact("sk_live_abcdef123456", 4) is "sk_l************3456".
+// Strings too short to keep anything are hidden entirely.
+func Redact(s string, keep int) string {
+	runes := []rune(s)
+	if keep < 0 {
+		keep = 0
+	}
+	if len(runes) <= 2*keep {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:keep]) + strings.Repeat("*", len(runes)-2*keep) + string(runes[len(runes)-keep:])
+}
+
+// SplitLinesPreservingANSI splits s on newlines so that every line can be
+// printed on its own: a color that's active at the end of a line is reset
+// there and reopened at the start of the next.
+func SplitLinesPreservingANSI(s string) []string {
+	lines := strings.Split(s, "\n")
+	active := ""
+	for i, line := range lines {
+		if active != "" {
+			line = active + line
+		}
+		// find the color in effect at the end of this line.
+		for j := 0; j < len(line); j++ {
+			if n := ansiLen(line[j:]); n > 0 {
+				if seq := line[j : j+n]; seq == reset {
+					active = ""
+				} else {
+					active = seq
+				}
+				j += n - 1
+			}
+		}
+		if active != "" {
+			line += reset
+		}
+		lines[i] = line
+	}
+	return lines
+}
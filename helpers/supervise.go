@@ -0,0 +1,113 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// SuperviseSpec describes a worker for Supervise. Exactly one of Run and Cmd
+// must be set.
+type SuperviseSpec struct {
+	Name string // used in log lines, defaults to "worker"
+	// Run is the worker. It should return when ctx is done; a nil return
+	// means it finished and isn't restarted. Panics are recovered and
+	// treated as failures.
+	Run func(ctx context.Context) error
+	// Cmd returns the command to run as the worker, a new one each start.
+	// It's run with RunCmd, so its output is logged, and a zero exit means
+	// it finished.
+	Cmd     func() *exec.Cmd
+	CmdOpts CmdOptions // its Logger defaults to the spec's
+
+	Backoff     *Backoff      // wait before each restart, NewBackoff(time.Second, time.Minute) if nil
+	MaxRestarts int           // give up after this many restarts within Window, 0 for never
+	Window      time.Duration // window MaxRestarts counts over, 1 minute if zero
+	// a run lasting ResetAfter resets the backoff, 1 minute if zero.
+	ResetAfter time.Duration
+	Logger     Logger // defaults to the registered logger
+}
+
+// Supervise runs the worker described by spec and restarts it whenever it
+// fails, waiting as spec.Backoff says between restarts. Each restart is
+// logged at WARNING with the error or exit code that caused it. Supervise
+// returns nil when the worker finishes, ctx.Err() when ctx is done, and an
+// error when the worker fails more than MaxRestarts times within Window.
+func Supervise(ctx context.Context, spec SuperviseSpec) error {
+	if (spec.Run == nil) == (spec.Cmd == nil) {
+		return errors.New("supervise: exactly one of Run and Cmd must be set")
+	}
+	l := spec.Logger
+	if l == nil {
+		l = log()
+	}
+	name := spec.Name
+	if name == "" {
+		name = "worker"
+	}
+	b := spec.Backoff
+	if b == nil {
+		b = NewBackoff(time.Second, time.Minute)
+	}
+	window := spec.Window
+	if window <= 0 {
+		window = time.Minute
+	}
+	resetAfter := spec.ResetAfter
+	if resetAfter <= 0 {
+		resetAfter = time.Minute
+	}
+	var restarts []time.Time // restarts within the window, oldest first
+	for {
+		start := time.Now()
+		err := superviseRun(ctx, spec)
+		ran := time.Since(start).Round(time.Millisecond)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == nil {
+			l.Info(fmt.Sprintf("supervise %s: finished after %s", name, ran))
+			return nil
+		}
+		if ran >= resetAfter {
+			b.Reset()
+		}
+		now := time.Now()
+		for len(restarts) > 0 && now.Sub(restarts[0]) > window {
+			restarts = restarts[1:]
+		}
+		if spec.MaxRestarts > 0 && len(restarts) >= spec.MaxRestarts {
+			err = fmt.Errorf("supervise %s: giving up after %d restarts in %s: %w", name, len(restarts), window, err)
+			l.Error(err)
+			return err
+		}
+		restarts = append(restarts, now)
+		wait := b.Next()
+		if wait == Stop {
+			err = fmt.Errorf("supervise %s: giving up after %d attempts: %w", name, b.Attempts(), err)
+			l.Error(err)
+			return err
+		}
+		l.Warning(fmt.Sprintf("supervise %s: restarting in %s, failed after %s: %v", name, wait.Round(time.Millisecond), ran, err))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// superviseRun runs the worker once.
+func superviseRun(ctx context.Context, spec SuperviseSpec) error {
+	if spec.Cmd != nil {
+		opts := spec.CmdOpts
+		if opts.Logger == nil {
+			opts.Logger = spec.Logger
+		}
+		_, err := RunCmd(ctx, spec.Cmd(), opts)
+		return err
+	}
+	return Safe(func() error { return spec.Run(ctx) })
+}
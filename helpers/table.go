@@ -0,0 +1,103 @@
+package helpers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jeanhaley32/logger/colors"
+	"github.com/jeanhaley32/logger/helpers/strs"
+)
+
+// Table renders rows as aligned columns under a header, for command line
+// output. Cells may hold ANSI colors; widths are measured on visible text.
+type Table struct {
+	Headers []string
+	// RightAlign lists the columns, by index, padded on the left, for numbers.
+	RightAlign []int
+	// MaxWidth truncates cells wider than it with an ellipsis, 0 for no limit.
+	MaxWidth int
+	// Color paints the header when set.
+	Color bool
+	rows  [][]string
+}
+
+// NewTable returns a table with the given column headers.
+func NewTable(headers ...string) *Table {
+	return &Table{Headers: headers}
+}
+
+// AddRow appends a row, formatting each cell with fmt.Sprint.
+func (t *Table) AddRow(cells ...any) {
+	row := make([]string, len(cells))
+	for i, c := range cells {
+		row[i] = fmt.Sprint(c)
+	}
+	t.rows = append(t.rows, row)
+}
+
+// Len returns the number of rows added.
+func (t *Table) Len() int {
+	return len(t.rows)
+}
+
+// Render writes the table to w.
+func (t *Table) Render(w io.Writer) error {
+	_, err := io.WriteString(w, t.String())
+	return err
+}
+
+// Returns the table as a string, one line per row.
+func (t *Table) String() string {
+	cols := len(t.Headers)
+	for _, r := range t.rows {
+		cols = max(cols, len(r))
+	}
+	cell := func(r []string, i int) string {
+		if i >= len(r) {
+			return ""
+		}
+		if t.MaxWidth > 0 {
+			return strs.TruncateWithEllipsis(r[i], t.MaxWidth)
+		}
+		return r[i]
+	}
+	widths := make([]int, cols)
+	for _, r := range append([][]string{t.Headers}, t.rows...) {
+		for i := 0; i < cols; i++ {
+			widths[i] = max(widths[i], strs.VisibleLen(cell(r, i)))
+		}
+	}
+	right := make(map[int]bool, len(t.RightAlign))
+	for _, i := range t.RightAlign {
+		right[i] = true
+	}
+	var sb strings.Builder
+	line := func(r []string, header bool) {
+		var ln strings.Builder
+		for i := 0; i < cols; i++ {
+			c := cell(r, i)
+			if header && t.Color {
+				c = colors.Wrap(colors.GREEN, c)
+			}
+			if right[i] {
+				c = strs.PadLeft(c, widths[i])
+			} else if i < cols-1 {
+				c = strs.PadRight(c, widths[i])
+			}
+			if i > 0 {
+				ln.WriteString("  ")
+			}
+			ln.WriteString(c)
+		}
+		sb.WriteString(strings.TrimRight(ln.String(), " "))
+		sb.WriteByte('\n')
+	}
+	if len(t.Headers) > 0 {
+		line(t.Headers, true)
+	}
+	for _, r := range t.rows {
+		line(r, false)
+	}
+	return sb.String()
+}
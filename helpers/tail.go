@@ -0,0 +1,85 @@
+package helpers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	// how often TailFile checks the file for new data, truncation or rotation.
+	tailPollInterval = 250 * time.Millisecond
+)
+
+// TailFile follows the file at path, in the spirit of `tail -F`, and sends each
+// new line on the returned channel. It starts at the end of the file, reopens
+// the path when the file is rotated, and starts over when it is truncated.
+// The channel is closed once ctx is done.
+func TailFile(ctx context.Context, path string) (<-chan string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("tail %s: %w", path, err)
+	}
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("tail %s: %w", path, err)
+	}
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		defer func() { f.Close() }()
+		r := bufio.NewReader(f)
+		var partial strings.Builder
+		ticker := time.NewTicker(tailPollInterval)
+		defer ticker.Stop()
+		for {
+			// read everything available right now.
+			for {
+				chunk, rerr := r.ReadString('\n')
+				offset += int64(len(chunk))
+				partial.WriteString(chunk)
+				if rerr != nil {
+					break
+				}
+				line := strings.TrimRight(partial.String(), "\r\n")
+				partial.Reset()
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			// check for rotation: the path now points at a different file.
+			if cur, err := os.Stat(path); err == nil {
+				if old, err := f.Stat(); err == nil && !os.SameFile(old, cur) {
+					if nf, err := os.Open(path); err == nil {
+						f.Close()
+						f, offset = nf, 0
+						r.Reset(f)
+						partial.Reset()
+						continue
+					}
+				}
+				// check for truncation: the file is now shorter than what we've read.
+				if cur.Size() < offset {
+					if _, err := f.Seek(0, io.SeekStart); err == nil {
+						offset = 0
+						r.Reset(f)
+						partial.Reset()
+					}
+				}
+			}
+		}
+	}()
+	return lines, nil
+}
@@ -0,0 +1,118 @@
+package helpers
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// TeeOption configures TeeLogger and TeeWriter.
+type TeeOption func(*teeConfig)
+
+type teeConfig struct {
+	label   string
+	hexdump bool
+}
+
+// TeeHexdump logs a hexdump of every chunk instead of just its size. The
+// dump is plain text, as entries can go to files and JSON outputs.
+func TeeHexdump() TeeOption {
+	return func(c *teeConfig) { c.hexdump = true }
+}
+
+// TeeLabel sets the label logged with each entry, "read" or "write" by default.
+func TeeLabel(label string) TeeOption {
+	return func(c *teeConfig) { c.label = label }
+}
+
+// teeLog logs chunks passing through a tee and keeps the running total.
+type teeLog struct {
+	cfg   teeConfig
+	log   func(any)
+	mu    sync.Mutex
+	total int64
+	once  sync.Once
+}
+
+func newTeeLog(l Logger, level Level, def string, opts []TeeOption) *teeLog {
+	if l == nil {
+		l = log()
+	}
+	t := &teeLog{cfg: teeConfig{label: def}, log: level.of(l)}
+	for _, o := range opts {
+		o(&t.cfg)
+	}
+	return t
+}
+
+func (t *teeLog) chunk(p []byte) {
+	if len(p) == 0 {
+		return
+	}
+	t.mu.Lock()
+	t.total += int64(len(p))
+	total := t.total
+	t.mu.Unlock()
+	if t.cfg.hexdump {
+		// plain: the entry goes to every output, not just a terminal.
+		t.log(fmt.Sprintf("%s %d bytes (%d total):\n%s", t.cfg.label, len(p), total, hexdump(p, false)))
+	} else {
+		t.log(fmt.Sprintf("%s %d bytes (%d total)", t.cfg.label, len(p), total))
+	}
+}
+
+func (t *teeLog) summary() {
+	t.once.Do(func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.log(fmt.Sprintf("%s done, %d bytes total", t.cfg.label, t.total))
+	})
+}
+
+// teeReader logs what's read through it.
+type teeReader struct {
+	r io.Reader
+	t *teeLog
+}
+
+// TeeLogger returns a reader that reads from r and logs the traffic at level:
+// the size of each chunk, or a hexdump with TeeHexdump, and a byte count
+// summary at EOF.
+func TeeLogger(r io.Reader, l Logger, level Level, opts ...TeeOption) io.Reader {
+	return &teeReader{r: r, t: newTeeLog(l, level, "read", opts)}
+}
+
+func (tr *teeReader) Read(p []byte) (int, error) {
+	n, err := tr.r.Read(p)
+	tr.t.chunk(p[:n])
+	if err == io.EOF {
+		tr.t.summary()
+	}
+	return n, err
+}
+
+// teeWriter logs what's written through it.
+type teeWriter struct {
+	w io.Writer
+	t *teeLog
+}
+
+// TeeWriter is the writer equivalent of TeeLogger. The summary is logged on
+// Close, which also closes w if it's an io.Closer.
+func TeeWriter(w io.Writer, l Logger, level Level, opts ...TeeOption) io.WriteCloser {
+	return &teeWriter{w: w, t: newTeeLog(l, level, "write", opts)}
+}
+
+func (tw *teeWriter) Write(p []byte) (int, error) {
+	n, err := tw.w.Write(p)
+	tw.t.chunk(p[:n])
+	return n, err
+}
+
+func (tw *teeWriter) Close() error {
+	tw.t.summary()
+	if c, ok := tw.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
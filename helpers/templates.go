@@ -0,0 +1,163 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+)
+
+// TemplateOptions configures LoadTemplates.
+type TemplateOptions struct {
+	Text   bool           // use text/template instead of html/template
+	Funcs  map[string]any // functions available to the templates
+	Ext    []string       // file extensions loaded, .html, .tmpl and .txt if empty
+	Reload bool           // reparse the templates when a file changes, for development
+	Logger Logger         // defaults to the registered logger
+}
+
+// Templates is a set of templates parsed from a directory, see LoadTemplates.
+type Templates struct {
+	dir  string
+	opts TemplateOptions
+	l    Logger
+
+	mu  sync.RWMutex
+	set templateSet
+}
+
+// templateSet is what html/template and text/template have in common.
+type templateSet interface {
+	ExecuteTemplate(w io.Writer, name string, data any) error
+}
+
+// templateErrRe picks the template name and line out of the errors of both
+// template packages: "template: users/list.html:12:5: executing ...".
+var templateErrRe = regexp.MustCompile(`^template: ([^:]+):(\d+)`)
+
+// LoadTemplates parses every template file under dir into one set, so they
+// can use each other's definitions. Each is named by its path relative to
+// dir, with forward slashes: "users/list.html". With opts.Reload the set is
+// reparsed when a file under dir changes, until ctx is done; a reload that
+// fails to parse is logged and the previous set kept.
+func LoadTemplates(ctx context.Context, dir string, opts TemplateOptions) (*Templates, error) {
+	if len(opts.Ext) == 0 {
+		opts.Ext = []string{".html", ".tmpl", ".txt"}
+	}
+	t := &Templates{dir: dir, opts: opts, l: opts.Logger}
+	if t.l == nil {
+		t.l = log()
+	}
+	set, dirs, err := t.parse()
+	if err != nil {
+		return nil, err
+	}
+	t.set = set
+	if opts.Reload {
+		events, err := Watch(ctx, dirs...)
+		if err != nil {
+			return nil, fmt.Errorf("templates %s: %w", dir, err)
+		}
+		go t.reload(events)
+	}
+	return t, nil
+}
+
+// reload reparses the set when a template file changes.
+func (t *Templates) reload(events <-chan Event) {
+	for ev := range events {
+		if !slices.Contains(t.opts.Ext, filepath.Ext(ev.Path)) {
+			continue
+		}
+		set, _, err := t.parse()
+		if err != nil {
+			t.l.Error(fmt.Sprintf("template reload failed, keeping previous templates: %v", err))
+			continue
+		}
+		t.mu.Lock()
+		t.set = set
+		t.mu.Unlock()
+		t.l.Info(fmt.Sprintf("templates %s: reloaded after %s %s", t.dir, ev.Op, ev.Path))
+	}
+}
+
+// parse parses the template files under dir, and returns the set with the
+// directories it found them in.
+func (t *Templates) parse() (templateSet, []string, error) {
+	var html *htmltemplate.Template
+	var text *texttemplate.Template
+	if t.opts.Text {
+		text = texttemplate.New("").Funcs(t.opts.Funcs)
+	} else {
+		html = htmltemplate.New("").Funcs(t.opts.Funcs)
+	}
+	var dirs []string
+	n := 0
+	err := filepath.WalkDir(t.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			dirs = append(dirs, path)
+			return nil
+		}
+		if !slices.Contains(t.opts.Ext, filepath.Ext(path)) {
+			return nil
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(t.dir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if t.opts.Text {
+			_, err = text.New(name).Parse(string(b))
+		} else {
+			_, err = html.New(name).Parse(string(b))
+		}
+		n++
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("templates %s: %w", t.dir, err)
+	}
+	if n == 0 {
+		return nil, nil, fmt.Errorf("templates %s: no files with extension %s", t.dir, strings.Join(t.opts.Ext, ", "))
+	}
+	if t.opts.Text {
+		return text, dirs, nil
+	}
+	return html, dirs, nil
+}
+
+// Render executes the template name with data and writes the result to w.
+// The output is buffered, so nothing is written if rendering fails; the
+// error is logged with the template and line it happened at, and returned.
+func (t *Templates) Render(w io.Writer, name string, data any) error {
+	t.mu.RLock()
+	set := t.set
+	t.mu.RUnlock()
+	var buf bytes.Buffer
+	if err := set.ExecuteTemplate(&buf, name, data); err != nil {
+		if m := templateErrRe.FindStringSubmatch(err.Error()); m != nil {
+			t.l.Error(fmt.Sprintf("render %s: error in %s line %s: %v", name, m[1], m[2], err))
+		} else {
+			t.l.Error(fmt.Sprintf("render %s: %v", name, err))
+		}
+		return err
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
@@ -0,0 +1,88 @@
+package helpers
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// TickerOption configures Ticker and TickFunc.
+type TickerOption func(*tickerConfig)
+
+type tickerConfig struct {
+	jitter    float64 // fraction of the interval, 0 to 1
+	immediate bool
+}
+
+// TickJitter shifts each tick by a random amount of up to pct percent of the
+// interval, either way, so pollers on many instances spread out.
+func TickJitter(pct float64) TickerOption {
+	return func(c *tickerConfig) {
+		c.jitter = min(max(pct, 0), 100) / 100
+	}
+}
+
+// TickImmediately fires the first tick right away instead of after one interval.
+func TickImmediately() TickerOption {
+	return func(c *tickerConfig) { c.immediate = true }
+}
+
+// Ticker sends the current time on the returned channel every d until ctx is
+// done. Ticks are scheduled against the start time rather than the previous
+// tick, so they don't drift. Like time.Ticker, ticks are dropped if the
+// receiver falls behind.
+func Ticker(ctx context.Context, d time.Duration, opts ...TickerOption) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	go func() {
+		defer close(ch)
+		tick(ctx, d, opts, func(t time.Time) {
+			select {
+			case ch <- t:
+			default:
+			}
+		})
+	}()
+	return ch
+}
+
+// TickFunc calls fn every d until ctx is done, blocking the caller. It takes
+// the same options as Ticker. A slow fn delays the next call but doesn't shift
+// the schedule; missed ticks are skipped.
+func TickFunc(ctx context.Context, d time.Duration, fn func(time.Time), opts ...TickerOption) {
+	tick(ctx, d, opts, fn)
+}
+
+// tick runs the schedule shared by Ticker and TickFunc.
+func tick(ctx context.Context, d time.Duration, opts []TickerOption, fire func(time.Time)) {
+	if d <= 0 {
+		return
+	}
+	var cfg tickerConfig
+	for _, o := range opts {
+		o(&cfg)
+	}
+	start := time.Now()
+	n := int64(1)
+	if cfg.immediate {
+		n = 0
+	}
+	for {
+		next := start.Add(time.Duration(n) * d)
+		if cfg.jitter > 0 {
+			span := int64(float64(d) * cfg.jitter)
+			if span > 0 {
+				next = next.Add(time.Duration(rand.Int63n(2*span) - span))
+			}
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case now := <-timer.C:
+			fire(now)
+		}
+		// skip ticks that passed while fire was running.
+		n = int64(time.Since(start)/d) + 1
+	}
+}
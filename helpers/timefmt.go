@@ -0,0 +1,78 @@
+package helpers
+
+import (
+	"fmt"
+	"time"
+)
+
+// Names of the time formats FormatTime knows besides Go layouts.
+const (
+	TimeISO8601     = "iso8601"     // 2006-01-02T15:04:05.000-07:00, with the zone as an offset
+	TimeRFC3339Nano = "rfc3339nano" // time.RFC3339Nano
+	TimeISOWeek     = "isoweek"     // 2006-W01-1: ISO year, week and weekday
+	TimeRelative    = "relative"    // 3m ago, in 2h, just now
+)
+
+// IsTimeFormatName reports whether name is one of FormatTime's named formats.
+func IsTimeFormatName(name string) bool {
+	switch name {
+	case TimeISO8601, TimeRFC3339Nano, TimeISOWeek, TimeRelative:
+		return true
+	}
+	return false
+}
+
+// FormatTime formats t in one of the named formats, e.g. TimeISOWeek, or
+// else with format as a Go layout.
+func FormatTime(t time.Time, format string) string {
+	switch format {
+	case TimeISO8601:
+		return t.Format("2006-01-02T15:04:05.000-07:00")
+	case TimeRFC3339Nano:
+		return t.Format(time.RFC3339Nano)
+	case TimeISOWeek:
+		return ISOWeek(t)
+	case TimeRelative:
+		return RelativeTime(t, time.Now())
+	}
+	return t.Format(format)
+}
+
+// ISOWeek returns the ISO 8601 week date of t, e.g. "2024-W01-1" for Monday
+// 1 January 2024. The year is the ISO year, which differs from t's around
+// the new year.
+func ISOWeek(t time.Time) string {
+	year, week := t.ISOWeek()
+	day := int(t.Weekday())
+	if day == 0 {
+		day = 7
+	}
+	return fmt.Sprintf("%04d-W%02d-%d", year, week, day)
+}
+
+// RelativeTime describes t relative to now in its largest unit: "3m ago",
+// "in 2h", or "just now" within a second.
+func RelativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	var s string
+	switch {
+	case d < time.Second:
+		return "just now"
+	case d < time.Minute:
+		s = fmt.Sprintf("%ds", int(d/time.Second))
+	case d < time.Hour:
+		s = fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 24*time.Hour:
+		s = fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		s = fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+	if future {
+		return "in " + s
+	}
+	return s + " ago"
+}
@@ -0,0 +1,19 @@
+package helpers
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"time"
+)
+
+// TimeFunc runs fn, logs how long it took at DEBUG through the registered
+// logger, and returns the duration.
+func TimeFunc(fn func()) time.Duration {
+	start := time.Now()
+	fn()
+	d := time.Since(start)
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	log().Debug(fmt.Sprintf("%s took %s", name, d))
+	return d
+}
@@ -0,0 +1,79 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+var (
+	// first and largest waits between WaitFor attempts.
+	waitMinBackoff = 100 * time.Millisecond
+	waitMaxBackoff = 5 * time.Second
+)
+
+// WaitForTCP blocks until a TCP connection to addr succeeds or ctx is done.
+func WaitForTCP(ctx context.Context, addr string) error {
+	return waitFor(ctx, "tcp "+addr, func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	})
+}
+
+// WaitForHTTP blocks until a GET of url answers with expectStatus, or ctx is done.
+func WaitForHTTP(ctx context.Context, url string, expectStatus int) error {
+	client := &http.Client{Timeout: 5 * time.Second}
+	return waitFor(ctx, "http "+url, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expectStatus {
+			return fmt.Errorf("got status %d, want %d", resp.StatusCode, expectStatus)
+		}
+		return nil
+	})
+}
+
+// WaitForFile blocks until path exists or ctx is done.
+func WaitForFile(ctx context.Context, path string) error {
+	return waitFor(ctx, "file "+path, func(context.Context) error {
+		_, err := os.Stat(path)
+		return err
+	})
+}
+
+// waitFor calls check with backoff until it succeeds, logging progress
+// through the registered logger.
+func waitFor(ctx context.Context, what string, check func(context.Context) error) error {
+	start := time.Now()
+	backoff := NewBackoff(waitMinBackoff, waitMaxBackoff)
+	for attempt := 1; ; attempt++ {
+		err := check(ctx)
+		if err == nil {
+			if attempt > 1 {
+				log().Info(fmt.Sprintf("%s is ready after %s", what, time.Since(start).Round(time.Millisecond)))
+			}
+			return nil
+		}
+		wait := backoff.Next()
+		log().Info(fmt.Sprintf("waiting for %s: %v (attempt %d, retrying in %s)", what, err, attempt, wait.Round(time.Millisecond)))
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for %s: %w (last error: %v)", what, ctx.Err(), err)
+		case <-time.After(wait):
+		}
+	}
+}
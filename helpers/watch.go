@@ -0,0 +1,153 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Op describes what happened to a watched path.
+type Op int
+
+const (
+	Create Op = iota
+	Modify
+	Delete
+)
+
+func (o Op) String() string {
+	switch o {
+	case Create:
+		return "create"
+	case Modify:
+		return "modify"
+	case Delete:
+		return "delete"
+	}
+	return "unknown"
+}
+
+// Event is a single debounced change reported by Watch.
+type Event struct {
+	Path string
+	Op   Op
+}
+
+var (
+	// how often Watch polls the watched paths.
+	watchPollInterval = 200 * time.Millisecond
+	// how long a path must stay quiet before its change is reported.
+	watchDebounce = 300 * time.Millisecond
+)
+
+// fileState is the part of a file's metadata Watch compares between polls.
+type fileState struct {
+	mod  time.Time
+	size int64
+}
+
+// Watch polls the given paths and sends debounced create, modify and delete
+// events on the returned channel. A directory is watched along with its direct
+// children. Bursts of changes to the same path (editors writing a file in
+// several steps, for example) are collapsed into a single event. The channel
+// is closed once ctx is done.
+func Watch(ctx context.Context, paths ...string) (<-chan Event, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("watch: no paths given")
+	}
+	abs := make([]string, 0, len(paths))
+	for _, p := range paths {
+		a, err := filepath.Abs(p)
+		if err != nil {
+			return nil, fmt.Errorf("watch %s: %w", p, err)
+		}
+		abs = append(abs, a)
+	}
+	events := make(chan Event)
+	// taken before returning, so changes made right after are seen.
+	prev := snapshot(abs)
+	go func() {
+		defer close(events)
+		// pending holds changes waiting for the debounce window to pass.
+		pending := map[string]Event{}
+		lastSeen := map[string]time.Time{}
+		ticker := time.NewTicker(watchPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			cur := snapshot(abs)
+			now := time.Now()
+			for p, st := range cur {
+				old, ok := prev[p]
+				switch {
+				case !ok:
+					pending[p] = mergeOp(pending, p, Create)
+					lastSeen[p] = now
+				case !st.mod.Equal(old.mod) || st.size != old.size:
+					pending[p] = mergeOp(pending, p, Modify)
+					lastSeen[p] = now
+				}
+			}
+			for p := range prev {
+				if _, ok := cur[p]; !ok {
+					pending[p] = mergeOp(pending, p, Delete)
+					lastSeen[p] = now
+				}
+			}
+			prev = cur
+			for p, ev := range pending {
+				if now.Sub(lastSeen[p]) < watchDebounce {
+					continue
+				}
+				delete(pending, p)
+				delete(lastSeen, p)
+				select {
+				case events <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// mergeOp folds a new op into any pending event for path, so that a create
+// followed by writes is still reported as a create.
+func mergeOp(pending map[string]Event, path string, op Op) Event {
+	if ev, ok := pending[path]; ok && ev.Op == Create && op == Modify {
+		return ev
+	}
+	return Event{Path: path, Op: op}
+}
+
+// snapshot stats every watched path, and the direct children of directories.
+func snapshot(paths []string) map[string]fileState {
+	states := map[string]fileState{}
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		states[p] = fileState{mod: info.ModTime(), size: info.Size()}
+		if !info.IsDir() {
+			continue
+		}
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if ei, err := e.Info(); err == nil {
+				states[filepath.Join(p, e.Name())] = fileState{mod: ei.ModTime(), size: ei.Size()}
+			}
+		}
+	}
+	return states
+}
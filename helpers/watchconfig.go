@@ -0,0 +1,78 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// ConfigValue holds the current value of a config file watched by WatchConfig.
+type ConfigValue[T any] struct {
+	cur atomic.Pointer[T]
+}
+
+// Get returns the current config. The returned value must be treated as
+// read-only; a reload swaps in a new value instead of modifying it.
+func (c *ConfigValue[T]) Get() *T {
+	return c.cur.Load()
+}
+
+// WatchConfig loads the config file at path with LoadConfig, then reloads it
+// whenever it changes. A reload that parses and validates is swapped in
+// atomically and passed to onChange along with the previous value; the
+// changed fields are logged ("Logger.Level changed info→debug"). A reload that
+// fails is logged and the previous config is kept.
+func WatchConfig[T any](ctx context.Context, path string, onChange func(old, new *T)) (*ConfigValue[T], error) {
+	return watchConfig(ctx, path, onChange, true)
+}
+
+// WatchConfigQuiet is WatchConfig without the log of the changed fields, for
+// an onChange that logs the changes itself.
+func WatchConfigQuiet[T any](ctx context.Context, path string, onChange func(old, new *T)) (*ConfigValue[T], error) {
+	return watchConfig(ctx, path, onChange, false)
+}
+
+func watchConfig[T any](ctx context.Context, path string, onChange func(old, new *T), logChanges bool) (*ConfigValue[T], error) {
+	first := new(T)
+	if err := LoadConfig(path, first); err != nil {
+		return nil, err
+	}
+	cv := &ConfigValue[T]{}
+	cv.cur.Store(first)
+	// watch the directory, editors and atomic writers replace the file rather than write to it.
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("watch config %s: %w", path, err)
+	}
+	events, err := Watch(ctx, filepath.Dir(abs))
+	if err != nil {
+		return nil, fmt.Errorf("watch config %s: %w", path, err)
+	}
+	go func() {
+		for ev := range events {
+			if ev.Path != abs || ev.Op == Delete {
+				continue
+			}
+			next := new(T)
+			if err := LoadConfig(path, next); err != nil {
+				log().Error(fmt.Sprintf("config reload failed, keeping previous config: %v", err))
+				continue
+			}
+			old := cv.cur.Swap(next)
+			changes := Diff(old, next)
+			if len(changes) == 0 {
+				continue
+			}
+			if logChanges {
+				for _, c := range changes {
+					log().Info(fmt.Sprintf("config %s: %s", filepath.Base(path), c))
+				}
+			}
+			if onChange != nil {
+				onChange(old, next)
+			}
+		}
+	}()
+	return cv, nil
+}
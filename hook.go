@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// Hook receives every entry written, after rules and filters, to send it
+// somewhere an encoder can't: a pager, a database, a message bus.
+// Hooks run on the goroutine writing the entry, usually the mediator, so a
+// slow hook holds up logging and should hand its work off. A hook that
+// panics is reported to the error handler, and removed after
+// maxHookPanics panics in a row.
+type Hook interface {
+	Fire(r Record) error
+}
+
+// HookFunc adapts a function to a Hook.
+type HookFunc func(r Record) error
+
+func (f HookFunc) Fire(r Record) error {
+	return f(r)
+}
+
+// maxHookPanics is how many consecutive panics disable a hook.
+const maxHookPanics = 3
+
+// hooks is the list of hooks, replaced as a whole on change so the mediator
+// reads it without locking.
+type hooks struct {
+	list atomic.Pointer[[]*hook]
+}
+
+// hook is an added Hook and its run of panics.
+type hook struct {
+	Hook
+	panics   atomic.Int32 // consecutive panics
+	disabled atomic.Bool
+}
+
+// AddHook calls h for every entry written from now on.
+func (l *Mylogger) AddHook(h Hook) {
+	for {
+		old := l.hooks.list.Load()
+		var next []*hook
+		if old != nil {
+			next = append(next, *old...)
+		}
+		next = append(next, &hook{Hook: h})
+		if l.hooks.list.CompareAndSwap(old, &next) {
+			return
+		}
+	}
+}
+
+// fire calls every hook with r, reporting their errors and panics to l's
+// error handler, so a broken hook can't stop the mediator.
+func (hs *hooks) fire(l *Mylogger, r Record) {
+	list := hs.list.Load()
+	if list == nil {
+		return
+	}
+	for _, h := range *list {
+		if h.disabled.Load() {
+			continue
+		}
+		err := helpers.Safe(func() error { return h.Fire(r) })
+		var pe *helpers.PanicError
+		if !errors.As(err, &pe) {
+			h.panics.Store(0)
+			if err != nil {
+				l.reportError(fmt.Errorf("hook %T: %w", h.Hook, err))
+			}
+			continue
+		}
+		if h.panics.Add(1) >= maxHookPanics {
+			h.disabled.Store(true)
+			l.reportError(fmt.Errorf("hook %T disabled after %d panics in a row: %w", h.Hook, maxHookPanics, err))
+			continue
+		}
+		l.reportError(fmt.Errorf("hook %T: %w", h.Hook, err))
+	}
+}
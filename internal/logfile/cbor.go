@@ -0,0 +1,53 @@
+package logfile
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// IsCBOR reports whether a log starting with b is a CBOR sequence, as
+// written by logger.CBOREncoder: a map head (0xa0 to 0xbf), which no text
+// line starts with.
+func IsCBOR(b byte) bool {
+	return b >= 0xa0 && b <= 0xbf
+}
+
+// ReadCBOR calls fn with every entry of a CBOR sequence.
+func ReadCBOR(r io.Reader, fn func(Record)) error {
+	dec := cbor.NewDecoder(bufio.NewReader(r))
+	for {
+		var m map[string]any
+		err := dec.Decode(&m)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("cbor: %w", err)
+		}
+		rec := Record{Fields: m, Version: 1}
+		if v, ok := m["v"].(uint64); ok {
+			rec.Version = int(v)
+			delete(m, "v")
+		}
+		if v, ok := take(m, timeKeys); ok {
+			switch t := v.(type) {
+			case time.Time:
+				rec.Time = t
+			case string:
+				rec.Time, _ = ParseTime(t)
+			}
+		}
+		if v, ok := take(m, levelKeys); ok {
+			rec.Level = NormalizeLevel(toString(v))
+		}
+		if v, ok := take(m, msgKeys); ok {
+			rec.Msg = toString(v)
+		}
+		fn(rec)
+	}
+}
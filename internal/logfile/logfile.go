@@ -0,0 +1,273 @@
+// Package logfile parses the lines written by the logger, in its JSON,
+// logfmt and text formats, for the command line tools.
+package logfile
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jeanhaley32/logger"
+	"github.com/jeanhaley32/logger/helpers/strs"
+)
+
+// Record is one parsed log line.
+type Record struct {
+	Time    time.Time // zero if the line had no parseable time
+	Level   string    // lowercase: debug, info, warning, error, critical
+	Msg     string
+	Fields  map[string]any // everything else on the line
+	Version int            // "v" of JSON entries, see logger.EntrySchemaVersion; 0 for other formats
+	Raw     string
+}
+
+// Keys returns the names of the record's extra fields, sorted.
+func (r Record) Keys() []string {
+	keys := make([]string, 0, len(r.Fields))
+	for k := range r.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// field names recognized for the time, level and message.
+var (
+	timeKeys  = []string{"time", "ts", "timestamp", "@timestamp"}
+	levelKeys = []string{"level", "lvl", "severity"}
+	msgKeys   = []string{"msg", "message"}
+)
+
+// time layouts tried when parsing timestamps.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05.000",
+}
+
+// text lines look like "2006-01-02 15:04:05:INFO: message", or with the
+// caller, "2006-01-02 15:04:05:INFO:main.go:12: message".
+var textLine = regexp.MustCompile(`^(\d{4}-\d\d-\d\d \d\d:\d\d:\d\d):([A-Z]+):(?:(\S+\.go:\d+):)? ?(.*)$`)
+
+// Parse parses a line in any of the logger's formats, or JSON and logfmt
+// lines of other programs. It returns false for lines that don't look like
+// log entries.
+func Parse(line string) (Record, bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return Record{}, false
+	}
+	if e, err := logger.ParseEntry([]byte(line)); err == nil {
+		return fromEntry(line, e), true
+	}
+	if strings.HasPrefix(trimmed, "{") {
+		return parseJSON(line, trimmed)
+	}
+	if m := textLine.FindStringSubmatch(strs.StripANSI(trimmed)); m != nil {
+		r := Record{Level: NormalizeLevel(m[2]), Msg: m[4], Raw: line, Fields: map[string]any{}}
+		r.Time, _ = ParseTime(m[1])
+		if m[3] != "" {
+			r.Fields["caller"] = m[3]
+		}
+		return r, true
+	}
+	if strings.Contains(trimmed, "=") {
+		return parseLogfmt(line, trimmed)
+	}
+	return Record{}, false
+}
+
+func parseJSON(raw, line string) (Record, bool) {
+	m, err := decodeJSON(line)
+	if err != nil {
+		return Record{}, false
+	}
+	version := 1
+	if v, ok := m["v"].(json.Number); ok {
+		if n, err := v.Int64(); err == nil {
+			version = int(n)
+			delete(m, "v")
+		}
+	}
+	r := fromMap(raw, m)
+	r.Version = version
+	return r, true
+}
+
+func decodeJSON(line string) (map[string]any, error) {
+	var m map[string]any
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	err := dec.Decode(&m)
+	return m, err
+}
+
+func parseLogfmt(raw, line string) (Record, bool) {
+	m := map[string]any{}
+	for len(line) > 0 {
+		line = strings.TrimLeft(line, " ")
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 {
+			break
+		}
+		key := line[:eq]
+		line = line[eq+1:]
+		var val string
+		if strings.HasPrefix(line, `"`) {
+			// quoted value, with backslash escapes.
+			var sb strings.Builder
+			i := 1
+			for ; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) {
+					i++
+					switch line[i] {
+					case 'n':
+						sb.WriteByte('\n')
+						continue
+					case 't':
+						sb.WriteByte('\t')
+						continue
+					}
+				}
+				sb.WriteByte(line[i])
+			}
+			val = sb.String()
+			line = line[min(i+1, len(line)):]
+		} else {
+			end := strings.IndexByte(line, ' ')
+			if end < 0 {
+				end = len(line)
+			}
+			val, line = line[:end], line[end:]
+		}
+		m[key] = val
+	}
+	if len(m) == 0 {
+		return Record{}, false
+	}
+	return fromMap(raw, m), true
+}
+
+// fromEntry converts a line parsed by logger.ParseEntry, which splits the
+// fields off text entries too.
+func fromEntry(raw string, e logger.Entry) Record {
+	r := Record{
+		Time:   e.Time,
+		Level:  strings.ToLower(e.Level.String()),
+		Msg:    e.Message,
+		Fields: map[string]any{},
+		Raw:    raw,
+	}
+	if e.Format == "json" {
+		r.Version = e.Version
+	}
+	if e.Caller != "" {
+		r.Fields["caller"] = e.Caller
+	}
+	for _, a := range e.Attrs {
+		r.Fields[a.Key] = fieldValue(a.Value)
+	}
+	return r
+}
+
+// fieldValue turns groups into maps, as they're decoded from JSON.
+func fieldValue(v any) any {
+	g, ok := v.([]logger.Attr)
+	if !ok {
+		return v
+	}
+	m := make(map[string]any, len(g))
+	for _, a := range g {
+		m[a.Key] = fieldValue(a.Value)
+	}
+	return m
+}
+
+// fromMap pulls the well known fields out of a decoded line.
+func fromMap(raw string, m map[string]any) Record {
+	r := Record{Raw: raw, Fields: m}
+	if v, ok := take(m, timeKeys); ok {
+		r.Time, _ = ParseTime(toString(v))
+	}
+	if v, ok := take(m, levelKeys); ok {
+		r.Level = NormalizeLevel(toString(v))
+	}
+	if v, ok := take(m, msgKeys); ok {
+		r.Msg = toString(v)
+	}
+	return r
+}
+
+func take(m map[string]any, keys []string) (any, bool) {
+	for _, k := range keys {
+		if v, ok := m[k]; ok {
+			delete(m, k)
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func toString(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case nil:
+		return ""
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// ParseTime parses the timestamp formats the logger writes.
+func ParseTime(s string) (time.Time, error) {
+	var err error
+	for _, layout := range timeLayouts {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// NormalizeLevel maps level spellings to the logger's lowercase names.
+func NormalizeLevel(s string) string {
+	switch l := strings.ToLower(strings.TrimSpace(s)); l {
+	case "warn":
+		return "warning"
+	case "err":
+		return "error"
+	case "crit", "fatal":
+		return "critical"
+	case "trace":
+		return "debug"
+	default:
+		return l
+	}
+}
+
+// Severity orders levels from debug (0) to critical (4); unknown levels are -1.
+func Severity(level string) int {
+	switch NormalizeLevel(level) {
+	case "debug":
+		return 0
+	case "info", "notice":
+		return 1
+	case "warning":
+		return 2
+	case "error":
+		return 3
+	case "critical":
+		return 4
+	}
+	return -1
+}
+
+// FieldString formats a field value for display.
+func FieldString(v any) string {
+	return toString(v)
+}
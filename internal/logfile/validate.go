@@ -0,0 +1,62 @@
+package logfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jeanhaley32/logger"
+)
+
+var callerField = regexp.MustCompile(`^\S+\.go:\d+$`)
+
+// Validate checks a JSON line against logger.EntryJSONSchema, returning
+// what's wrong with it.
+func Validate(line string) error {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "{") {
+		return errors.New("not a JSON object")
+	}
+	m, err := decodeJSON(line)
+	if err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if v, ok := m["v"]; ok {
+		n, isNum := v.(json.Number)
+		i, err := n.Int64()
+		if !isNum || err != nil {
+			return fmt.Errorf(`"v" is %s, not an integer`, toString(v))
+		}
+		if i < 1 || i > logger.EntrySchemaVersion {
+			return fmt.Errorf(`"v" is %d, this version knows 1 to %d`, i, logger.EntrySchemaVersion)
+		}
+	}
+	for _, key := range []string{"time", "level", "msg"} {
+		if _, ok := m[key]; !ok {
+			return fmt.Errorf("%q is missing", key)
+		}
+	}
+	for _, key := range []string{"time", "level", "msg", "caller", "stream"} {
+		if v, ok := m[key]; ok {
+			if _, isStr := v.(string); !isStr {
+				return fmt.Errorf("%q is %s, not a string", key, toString(v))
+			}
+		}
+	}
+	if _, err := time.Parse(time.RFC3339Nano, m["time"].(string)); err != nil {
+		return fmt.Errorf(`"time" is not an RFC 3339 time: %w`, err)
+	}
+	if Severity(m["level"].(string)) < 0 || m["level"] != NormalizeLevel(m["level"].(string)) {
+		return fmt.Errorf(`"level" %q is not one of debug, info, warning, error, critical`, m["level"])
+	}
+	if c, ok := m["caller"]; ok && !callerField.MatchString(c.(string)) {
+		return fmt.Errorf(`"caller" %q is not file.go:line`, c)
+	}
+	if s, ok := m["stream"]; ok && s != "stdout" && s != "stderr" {
+		return fmt.Errorf(`"stream" %q is not stdout or stderr`, s)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package logger
+
+import "github.com/jeanhaley32/logger/helpers"
+
+// Log v as pretty printed JSON at INFO, see helpers.PrettyJSON. The JSON is
+// syntax highlighted on sinks whose encoder writes color, see TextEncoder.
+func (l *Mylogger) InfoJSON(label string, v any) {
+	if l.enabled(INFO) {
+		l.Info(jsonMessage{label, helpers.PrettyJSON(v)})
+	}
+}
+
+// Log v as pretty printed JSON at DEBUG, handy for request and response bodies.
+func (l *Mylogger) DebugJSON(label string, v any) {
+	if l.enabled(DEBUG) {
+		l.Debug(jsonMessage{label, helpers.PrettyJSON(v)})
+	}
+}
+
+// jsonMessage is the message of InfoJSON and DebugJSON, kept apart so each
+// sink's encoder can decide whether to highlight the JSON.
+type jsonMessage struct {
+	label, json string
+}
+
+func (m jsonMessage) String() string {
+	return m.label + ":\n" + m.json
+}
+
+// jsonStart returns where the JSON of a jsonMessage begins in its text, or 0.
+func jsonStart(v any) int {
+	if m, ok := v.(jsonMessage); ok {
+		return len(m.label) + 2
+	}
+	return 0
+}
@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONIsHighlightedPerSink(t *testing.T) {
+	var term, plain, js bytes.Buffer
+	l, err := StartLogger(WithSyncMode(), WithOutput(&term), WithEncoder(TextEncoder{}), WithSink(&plain), WithSink(&js, JSONEncoder{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Shutdown(nil)
+	l.out.mu.Lock()
+	l.out.terminal = true
+	l.out.mu.Unlock()
+
+	l.InfoJSON("body", map[string]any{"user": "ada", "n": 1})
+	if !strings.Contains(term.String(), "\x1b[") {
+		t.Errorf("terminal output isn't highlighted: %q", term.String())
+	}
+	if strings.Contains(plain.String(), "\x1b[") || !strings.Contains(plain.String(), "body:\n{") {
+		t.Errorf("file output = %q, want plain JSON", plain.String())
+	}
+	var e struct{ Msg string }
+	if err := json.Unmarshal(js.Bytes(), &e); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(e.Msg, "\x1b[") || !strings.HasPrefix(e.Msg, "body:\n{") {
+		t.Errorf("JSON sink msg = %q, want plain JSON", e.Msg)
+	}
+}
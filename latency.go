@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// maxOpNames bounds the operation names timed for the heartbeat and the
+// shutdown report; operations with new names past it aren't timed.
+const maxOpNames = 1000
+
+// latencyBuckets is the number of histogram buckets. Each is 2^(1/4), about
+// 19%, wider than the one before, starting at a microsecond; the last one
+// holds everything from 2^33.5µs, about 3.4 hours, on.
+const latencyBuckets = 136
+
+// histogram counts durations in exponential buckets, so quantiles are
+// accurate to within a bucket's width whatever the scale.
+type histogram struct {
+	counts [latencyBuckets]uint64
+	n      uint64
+	max    time.Duration
+}
+
+// bucketOf returns the bucket d falls in.
+func bucketOf(d time.Duration) int {
+	if d <= time.Microsecond {
+		return 0
+	}
+	i := int(math.Ceil(4 * math.Log2(float64(d)/float64(time.Microsecond))))
+	return min(i, latencyBuckets-1)
+}
+
+// bucketUpper returns the upper bound of bucket i.
+func bucketUpper(i int) time.Duration {
+	return time.Duration(float64(time.Microsecond) * math.Pow(2, float64(i)/4))
+}
+
+func (h *histogram) observe(d time.Duration) {
+	h.counts[bucketOf(d)]++
+	h.n++
+	h.max = max(h.max, d)
+}
+
+// quantile returns the duration q of the observations are at or under, as
+// the upper bound of its bucket, capped at the largest seen.
+func (h *histogram) quantile(q float64) time.Duration {
+	rank := uint64(math.Ceil(q * float64(h.n)))
+	var seen uint64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= rank && c > 0 {
+			return min(bucketUpper(i), h.max)
+		}
+	}
+	return h.max
+}
+
+// opLatencies holds a histogram of the durations of each operation name,
+// filled by Operation.End.
+type opLatencies struct {
+	mu  sync.Mutex
+	ops map[string]*histogram
+}
+
+func (o *opLatencies) observe(name string, d time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	h, ok := o.ops[name]
+	if !ok {
+		if o.ops == nil {
+			o.ops = map[string]*histogram{}
+		}
+		if len(o.ops) >= maxOpNames {
+			return
+		}
+		h = &histogram{}
+		o.ops[name] = h
+	}
+	h.observe(d)
+}
+
+// opSummary is the latency of one operation name.
+type opSummary struct {
+	name          string
+	n             uint64
+	p50, p95, p99 time.Duration
+	max           time.Duration
+}
+
+// summaries returns the latency of every operation, by name.
+func (o *opLatencies) summaries() []opSummary {
+	o.mu.Lock()
+	out := make([]opSummary, 0, len(o.ops))
+	for name, h := range o.ops {
+		out = append(out, opSummary{name: name, n: h.n, p50: h.quantile(0.5), p95: h.quantile(0.95), p99: h.quantile(0.99), max: h.max})
+	}
+	o.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].name < out[j].name })
+	return out
+}
+
+// summary formats the latencies for the heartbeat:
+// "ops: migrate-db(n=12 p50=2.1s p95=3s p99=3.2s)". It's empty if no
+// operation has ended.
+func (o *opLatencies) summary() string {
+	list := o.summaries()
+	if len(list) == 0 {
+		return ""
+	}
+	parts := make([]string, len(list))
+	for i, s := range list {
+		parts[i] = fmt.Sprintf("%s(n=%d p50=%s p95=%s p99=%s)", s.name, s.n, helpers.Duration(s.p50), helpers.Duration(s.p95), helpers.Duration(s.p99))
+	}
+	return "ops: " + strings.Join(parts, " ")
+}
+
+// attrs returns the latencies as a group per operation, for the shutdown report.
+func (o *opLatencies) attrs() []Attr {
+	var attrs []Attr
+	for _, s := range o.summaries() {
+		attrs = append(attrs, Field(s.name, []Attr{
+			Field("count", s.n),
+			Field("p50", s.p50.String()),
+			Field("p95", s.p95.String()),
+			Field("p99", s.p99.String()),
+			Field("max", s.max.String()),
+		}))
+	}
+	return attrs
+}
@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+func TestShutdownLeavesNoGoroutines(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      func(t *testing.T) []Option
+		heartbeat bool
+	}{
+		{"default", func(*testing.T) []Option { return nil }, false},
+		{"spill", func(t *testing.T) []Option { return []Option{WithSpill(t.TempDir()), WithQueueSize(1)} }, false},
+		{"heartbeat", func(*testing.T) []Option { return nil }, true},
+		{"spill and heartbeat", func(t *testing.T) []Option { return []Option{WithSpill(t.TempDir()), WithQueueSize(1)} }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helpers.VerifyNoLeaks(t)
+			var out bytes.Buffer
+			l, err := StartLogger(append([]Option{WithOutput(&out)}, tt.opts(t)...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.heartbeat {
+				l.Heartbeat(context.Background(), 10*time.Millisecond)
+			}
+			for i := 0; i < 200; i++ {
+				l.Infof("entry %d", i)
+			}
+			time.Sleep(30 * time.Millisecond)
+			l.Shutdown(nil)
+		})
+	}
+}
@@ -1,128 +1,76 @@
 package logger
 
 import (
-	"errors"
+	"bytes"
 	"fmt"
-	"log"
 	"os"
 	"os/signal"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
+
+	"github.com/jeanhaley32/logger/helpers"
 )
 
 type ch chan any
 
-type errorType int
+// Level is the severity of a log entry, from DEBUG up to CRITICAL.
+// Shutdown and signals are handled on their own channels, not as levels.
+type Level int
 
 const (
-	DEBUG errorType = iota
-	DONE
-	CRITICAL
-	ERROR
-	WARNING
+	DEBUG Level = iota
 	INFO
-	INTSIGNAL
-	QUIT
+	WARNING
+	ERROR
+	CRITICAL
 )
 
 var (
-	crit, err, warn, info, debug, sigs, quit, done ch      // various channels used to receive logs.
-	verboseDefault                                 = false // verbose is set to false by default.
-	debugColor                                     = BLUE
-	critColor                                      = PURPLE
-	errColor                                       = RED
-	warnColor                                      = YELLOW
-	baseColor                                      = WHITE
-	timeFormat                                     = "2006-01-02 15:04:05"
+	done ch // closed on shutdown.
 )
 
-func (e errorType) String() string {
-	timeNow := func() string {
-		return time.Now().Format(timeFormat)
-	}
+// Returns the name of the level, e.g. "WARNING"
+func (e Level) String() string {
 	switch e {
 	case DEBUG:
-		return fmt.Sprintf(timeNow() + ":" + colorWrap(e.Color(), "DEBUG:"))
+		return "DEBUG"
 	case CRITICAL:
-		return fmt.Sprintf(timeNow() + ":" + colorWrap(e.Color(), "CRITICAL:"))
+		return "CRITICAL"
 	case ERROR:
-		return fmt.Sprintf(timeNow() + ":" + colorWrap(e.Color(), "ERROR:"))
+		return "ERROR"
 	case WARNING:
-		return fmt.Sprintf(timeNow() + ":" + colorWrap(e.Color(), "WARNING:"))
+		return "WARNING"
 	case INFO:
-		return fmt.Sprintf(timeNow() + ":" + colorWrap(e.Color(), "INFO:"))
+		return "INFO"
 	}
-	return fmt.Sprintf(timeNow() + ":" + colorWrap(e.Color(), "INFO:"))
+	return fmt.Sprintf("Level(%d)", int(e))
 }
 
-func (e errorType) Color() Color {
-	switch e {
-	case DEBUG:
-		return debugColor
-	case CRITICAL:
-		return critColor
-	case ERROR:
-		return errColor
-	case WARNING:
-		return warnColor
-	case INFO:
-		return baseColor
-	}
-	return baseColor
+// Color returns the default color of the level. A logger's config can give
+// it another, for that logger only.
+func (e Level) Color() Color {
+	return defaultStyle.color(e)
 }
 
-func (e errorType) initChan() ch {
+// severity orders the log levels from least to most severe.
+// Values that aren't log levels return -1.
+func (e Level) severity() int {
 	switch e {
-	case INTSIGNAL:
-		sigs = make(ch, 1)
-		return sigs
-	case QUIT:
-		quit = make(ch, 1)
-		return quit
 	case DEBUG:
-		debug = make(ch, chBufSize)
-		return debug
-	case CRITICAL:
-		crit = make(ch, chBufSize)
-		return crit
-	case ERROR:
-		err = make(ch, chBufSize)
-		return err
-	case WARNING:
-		warn = make(ch, chBufSize)
-		return warn
-	case DONE:
-		return make(ch, chBufSize)
+		return 0
 	case INFO:
-		info = make(ch, chBufSize)
-		return info
-	}
-	return make(ch, chBufSize)
-}
-
-func (e errorType) initLog(f *os.File) *log.Logger {
-	return log.New(f, fmt.Sprintf("%v", e), log.Lshortfile)
-}
-
-func (e errorType) channel() ch {
-	switch e {
-	case DEBUG:
-		return debug
-	case CRITICAL:
-		return crit
-	case ERROR:
-		return err
+		return 1
 	case WARNING:
-		return warn
-	case INFO:
-		return info
-	case DONE:
-		return done
-	case INTSIGNAL:
-		return sigs
+		return 2
+	case ERROR:
+		return 3
+	case CRITICAL:
+		return 4
 	}
-	return info
+	return -1
 }
 
 var (
@@ -130,141 +78,205 @@ var (
 	chBufSize = 100
 )
 
+// entry is one log entry waiting in the queue.
+type entry struct {
+	at     time.Time // when it was logged; entries the logger writes itself get the time they're written
+	level  Level
+	value  any           // the message, or the format string if format is set
+	args   []any         // arguments for the format
+	format bool          // logged with one of the f methods, e.g. Infof
+	caller string        // "file.go:12", if the logger records callers
+	attrs  []Attr        // fields of the Scope it was logged through
+	alert  bool          // raised by an alert rule, not counted by them
+	always bool          // written whatever the level: settings changes and flushed captures
+	ack    chan struct{} // closed once written, for guaranteed delivery
+}
+
 // Struct defining the various channels used to log messages.
+// Every level shares the entries queue, so entries are written in the order
+// they were logged.
 type channels struct {
-	crit  ch
-	err   ch
-	warn  ch
-	info  ch
-	debug ch
-	done  ch
-	sigs  chan os.Signal
-	quit  chan interface{}
+	entries chan entry
+	done    ch
+	sigs    chan os.Signal
+	quit    chan interface{}
 }
 
 // Struct defining a Custom Logger
 type Mylogger struct {
-	start    time.Time
-	chans    channels
-	wg       *sync.WaitGroup
-	warnlog  *log.Logger
-	errlog   *log.Logger
-	critlog  *log.Logger
-	debuglog *log.Logger
-	infolog  *log.Logger
-	verbose  bool
-}
-
-// Drain all log channels
+	start        time.Time
+	runID        string // identifies this run of the program in the logs
+	stats        *stats
+	chans        channels
+	wg           *sync.WaitGroup
+	out          *swapWriter           // the main output, the first of sinks
+	sinks        []*sink               // every output entries are written to
+	caller       bool                  // record the caller of each entry, see WithCaller
+	filters      filters               // see AddFilter
+	rules        rules                 // see AddRule
+	alerts       alerts                // see AddAlert
+	hooks        hooks                 // see AddHook
+	errors       errorHandler          // see SetErrorHandler
+	style        atomic.Pointer[style] // level colors and time layout, see ApplyConfig
+	level        atomic.Int64          // minimum Level logged, see severity()
+	routines     routines
+	shutdownOnce sync.Once
+	sync         bool             // write from the caller instead of the mediator, see WithSyncMode
+	syncMu       sync.Mutex       // serializes writes in sync mode
+	guaranteed   map[Level]bool   // levels whose callers wait for the write, see WithGuaranteedDelivery
+	sendMu       sync.RWMutex     // held for reading while queueing, see drainLogChannels
+	closed       bool             // the queue was drained, entries are written by the caller
+	spill        *spill           // see WithSpill
+	queueSize    int              // capacity of the entries queue, see WithQueueSize
+	lockThread   bool             // run the mediator on its own OS thread, see WithLockedThread
+	tune         *tuner           // sizes the queue, see WithAdaptiveQueue
+	boost        boost            // see BoostLevel
+	assertPanics bool             // failed assertions panic, see WithPanicOnAssert
+	deprecations sync.Map         // call sites Deprecated has logged, by pc
+	once         counts           // see Once and EveryN
+	watches      watches          // see Watch
+	health       healthState      // see Health
+	elapsed      bool             // add the time since start to entries, see WithElapsed
+	fingerprints fingerprints     // errors by shape, for the shutdown report
+	ops          opLatencies      // durations of operations, see Begin
+	now          func() time.Time // stamps entries, see WithClock
+}
+
+// Drain the log queue, and switch to writing entries from the caller.
+// Once it returns nothing is queued any more, so nothing can be lost when
+// the mediator stops.
 func (l *Mylogger) drainLogChannels() {
-	defer l.wg.Done()
-	chList := []errorType{
-		ERROR,
-		WARNING,
-		INFO,
-		DEBUG,
-	}
-	// define function used to drain channels
-	drainAndClose := func(e errorType) {
+	// stop queueing. Senders that got in first may be blocked on a full
+	// queue, so keep writing entries until they're all through.
+	locked := make(chan struct{})
+	go func() {
+		l.sendMu.Lock()
+		close(locked)
+	}()
+	for {
 		select {
-		case m := <-e.channel():
-			switch e {
-			case ERROR:
-				l.errlog.Println(cioe(m).Error())
-			case WARNING:
-				l.warnlog.Println(cioe(m).Error())
-			case INFO:
-				l.infolog.Println(cioe(m).Error())
-			case DEBUG:
-				l.debuglog.Println(cioe(m).Error())
+		case m := <-l.chans.entries:
+			l.writeEntry(m)
+		case <-locked:
+			l.closed = true
+			l.writeQueued(l.chans.entries)
+			if l.spill != nil {
+				l.spill.drain(l)
 			}
-		default:
-			close(e.channel())
+			l.sendMu.Unlock()
 			return
 		}
 	}
-
-	// create a waitgroup to wait for all channels to be drained.
-	// once all channels are drained, close the channels.
-	// this is done to ensure that all logs are drained before the channels are closed.
-	for _, e := range chList {
-		drainAndClose(e)
-	}
 }
 
 // generic shutdown sequence, return true at end of shutdown
+// Only the first call runs the sequence; later calls wait for it to finish.
 func (l *Mylogger) genericshutdownSequence(e error) bool {
+	l.shutdownOnce.Do(func() { l.shutdownSequence(e) })
+	return true
+}
+
+func (l *Mylogger) shutdownSequence(e error) {
 	// close done channel, signaling the intention to shutdown to listening applications.
 	close(l.chans.done)
 	// and listening applications should decrement from the wait group. Once the waitgroup
 	// is zero ensuring that everything is closed, we continue
-	l.wg.Wait()
-	if l.verbose {
-		l.debuglog.Println("All tracked Routines stopped")
+	l.waitRoutines()
+	// let alerts that are being sent get out.
+	l.alerts.wg.Wait()
+	if l.enabled(DEBUG) {
+		l.writef(DEBUG, "All tracked Routines stopped")
 	}
-	l.infolog.Printf("Server ran for %s", time.Since(l.StartTime()))
+	l.write(l.shutdownReport())
 	if e != nil {
-		l.warnlog.Println("Server exited with error: ", e.Error())
+		l.writef(WARNING, "Server exited with error: %v", e)
+		helpers.RunExitHooks()
 		os.Exit(1)
 	}
-	l.infolog.Printf("Shutting Down...")
-	// after all routines have stopped, drain the channels of logs.
-	l.AddToWaitGroup()
-	go l.drainLogChannels()
-	l.wg.Wait()
-	// exit with status 0
-	return true
+	l.writef(INFO, "Shutting Down...")
+	l.watches.close()
+	l.stats.unregister()
+	helpers.UnsetLogger(l)
+	// release pid files and anything else registered with helpers.OnExit.
+	helpers.RunExitHooks()
 }
 
 // Begin the logging process
-// Returns a pointer to a Mylogger struct
+// Returns a pointer to a Mylogger struct, writing to stderr unless an
+// option says otherwise. Invalid options are reported before anything starts.
+// In a container the default format is docker (JSON lines in UTC, no color)
+// rather than text; WithFormat("text") keeps text.
 // Example:
-// l := StartLogger(log.Default())
+// l, err := StartLogger(WithFile("/var/log/app.log"), WithVerbose())
 // l.Debug("Debug message")
 // l.Error("Error message")...
-func StartLogger(f *os.File, isVerbose ...bool) *Mylogger {
+func StartLogger(opts ...Option) (*Mylogger, error) {
 	wg := &sync.WaitGroup{} // waitgroup is intended to track the number of active goroutines.
 	quit := make(chan any, 1)
 	sigs := make(chan os.Signal, 1)
-	crit = make(ch, chBufSize)
-	err = make(ch, chBufSize)
-	warn = make(ch, chBufSize)
-	info = make(ch, chBufSize)
-	debug = make(ch, chBufSize)
-	done = make(ch, chBufSize)
+	runID := helpers.UUIDv7()
+	st := newStats(runID)
+	out := newSwapWriter(os.Stderr, st.bytes)
 	l := Mylogger{
-		wg:       wg,
-		start:    time.Now(), // Set start time of the server.
-		warnlog:  WARNING.initLog(f),
-		errlog:   ERROR.initLog(f),
-		critlog:  CRITICAL.initLog(f),
-		debuglog: DEBUG.initLog(f),
-		infolog:  INFO.initLog(f),
-		verbose: func() bool {
-			if len(isVerbose) > 0 {
-				return isVerbose[0]
-			} else {
-				return verboseDefault
+		stats: st,
+		wg:    wg,
+		start: time.Now(), // Set start time of the server.
+		now:   time.Now,
+		runID: runID,
+		out:   out,
+		sinks: []*sink{newSink(out, defaultEncoder())},
+	}
+	l.setLevel(INFO, "")
+	l.style.Store(defaultStyle)
+	for _, opt := range opts {
+		if err := opt(&l); err != nil {
+			for _, s := range l.sinks {
+				s.w.close()
 			}
-		}(),
+			st.unregister()
+			return nil, fmt.Errorf("start logger: %w", err)
+		}
+	}
+	done = make(ch, chBufSize)
+	if l.queueSize == 0 {
+		l.queueSize = chBufSize
+	}
+	if l.tune != nil {
+		l.queueSize = min(max(l.queueSize, l.tune.min), l.tune.max)
+		l.tune.capacity.Set(float64(l.queueSize))
 	}
 	l.chans = channels{
-		crit:  crit,
-		err:   err,
-		warn:  warn,
-		info:  info,
-		debug: debug,
-		done:  done,
-		sigs:  sigs,
-		quit:  quit,
+		entries: make(chan entry, l.queueSize),
+		done:    done,
+		sigs:    sigs,
+		quit:    quit,
 	}
+	// the first logger started is the one the helpers package logs through.
+	helpers.SetLoggerIfUnset(&l)
+	if l.sync {
+		return &l, nil
+	}
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	// count the mediator before it starts, so a quick Shutdown can't miss it.
+	l.AddToWaitGroup()
 	go func() {
-		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+		defer signal.Stop(sigs)
+		if l.lockThread {
+			runtime.LockOSThread()
+			defer runtime.UnlockOSThread()
+		}
 		// mediate channels
-		l.AddToWaitGroup()
 		mediateChannels(&l)
 	}()
-	return &l
+	return &l, nil
+}
+
+// defaultEncoder is the format of the main output unless an option sets one.
+func defaultEncoder() Encoder {
+	if helpers.InContainer() {
+		return DockerEncoder{}
+	}
+	return TextEncoder{}
 }
 
 // Signal the start of a new goroutine to the WaitGroup.
@@ -279,86 +291,330 @@ func (l *Mylogger) Done() {
 
 // mediates Log messages between the various channels.
 func mediateChannels(l *Mylogger) {
+	var tick <-chan time.Time
+	if l.tune != nil {
+		t := time.NewTicker(tuneEvery)
+		defer t.Stop()
+		tick = t.C
+	}
 	for {
 		select {
 		case <-l.chans.done:
+			// write out what's queued; anything logged from here on is written by the caller.
+			l.drainLogChannels()
+			l.writef(INFO, "Closing mediateChannels Routine")
 			l.Done()
-			l.infolog.Println("Closing mediateChannels Routine")
 			return
 		case <-l.chans.quit:
-			l.warnlog.Println("Received Quit Signal, shutting down logger")
+			l.writef(WARNING, "Received Quit Signal, shutting down logger")
+			l.drainLogChannels()
 			l.Done()
 			return
-		case e := <-l.chans.err:
-			l.errlog.Println(cioe(e).Error())
-		case e := <-l.chans.warn:
-			l.warnlog.Println(cioe(e).Error())
-		case e := <-l.chans.info:
-			l.infolog.Println(cioe(e).Error())
-		case e := <-l.chans.debug:
-			l.debuglog.Println(cioe(e).Error())
+		case e := <-l.chans.entries:
+			queued := len(l.chans.entries)
+			if l.tune != nil {
+				l.tune.observe(queued + 1)
+			}
+			if queued == 0 {
+				l.health.fullSince.Store(0)
+			}
+			l.writeEntry(e)
+		case <-tick:
+			l.tuneQueue()
 		case s := <-l.chans.sigs:
-			l.infolog.Println("Received Signal: ", s.String())
+			l.writef(INFO, "Received Signal: %s", s)
+			// the shutdown sequence waits for every tracked routine, this one included.
+			l.drainLogChannels()
+			l.Done()
 			l.genericshutdownSequence(nil)
+			return
+		}
+	}
+}
+
+// send queues an entry for the mediator, or writes it straight away in sync
+// mode and once the logger has shut down. For levels with guaranteed
+// delivery it waits until the entry is written.
+func (l *Mylogger) send(en entry) {
+	// stamp it now, so time spent in the queue doesn't skew it.
+	if en.at.IsZero() {
+		en.at = l.now()
+	}
+	if l.caller && en.caller == "" {
+		en.caller = caller()
+	}
+	if l.sync {
+		l.syncMu.Lock()
+		defer l.syncMu.Unlock()
+		l.write(en)
+		return
+	}
+	l.sendMu.RLock()
+	if l.closed {
+		l.sendMu.RUnlock()
+		l.syncMu.Lock()
+		defer l.syncMu.Unlock()
+		l.write(en)
+		return
+	}
+	if l.guaranteed[en.level] {
+		en.ack = make(chan struct{})
+	} else if l.spill != nil {
+		l.spill.offer(l, en)
+		l.sendMu.RUnlock()
+		return
+	}
+	l.enqueue(en)
+	l.sendMu.RUnlock()
+	if en.ack != nil {
+		<-en.ack
+	}
+}
+
+// writeEntry writes a queued entry and acknowledges it.
+func (l *Mylogger) writeEntry(en entry) {
+	l.write(en)
+	if en.ack != nil {
+		close(en.ack)
+	}
+}
+
+// write encodes an entry for every sink, each with its own encoder, and
+// counts it. The time is taken once, so every sink agrees on it.
+func (l *Mylogger) write(en entry) {
+	if en.at.IsZero() {
+		en.at = l.now()
+	}
+	r := Record{Time: en.at, Level: en.level, Message: en.text(), Caller: en.caller, Attrs: errorAttrs(en.value, en.attrs), jsonAt: jsonStart(en.value), style: l.style.Load()}
+	if l.elapsed {
+		// both times carry monotonic clock readings, so wall clock changes don't show.
+		r.Attrs = append(r.Attrs[:len(r.Attrs):len(r.Attrs)], Field("elapsed", en.at.Sub(l.start).Round(time.Microsecond)))
+	}
+	if r.Level != CRITICAL && !en.always {
+		if r.Level = l.rules.apply(l, r); !l.enabled(r.Level) {
+			return
 		}
 	}
+	if l.filters.dropped(l, r) {
+		l.stats.filtered.Inc()
+		return
+	}
+	var buf bytes.Buffer
+	for i, s := range l.sinks {
+		buf.Reset()
+		w := s.writer(r.Level)
+		l.encode(&buf, s.encoder(w), r, w.isTerminal())
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			w.failures.Add(1)
+			// an output that stays broken is reported once, until it works again.
+			if !w.failing.Swap(true) {
+				l.reportError(fmt.Errorf("write to output %d: %w", i, err))
+			}
+		} else {
+			w.failing.Store(false)
+		}
+	}
+	l.stats.entry(r.Level)
+	if r.Level.severity() >= ERROR.severity() && !en.alert {
+		l.fingerprints.add(r.Message)
+	}
+	if r.Level == CRITICAL {
+		l.health.lastCritical.Store(r.Time.UnixNano())
+	}
+	l.hooks.fire(l, r)
+	l.watches.check(l, r)
+	if !en.alert {
+		l.checkAlerts(r)
+	}
 }
 
-// convert into error
-func cioe(a any) error {
+// encode encodes r with enc, falling back to text if enc panics, so a buggy
+// encoder costs its format rather than the entry or the mediator.
+func (l *Mylogger) encode(buf *bytes.Buffer, enc Encoder, r Record, color bool) {
+	n := buf.Len()
+	err := helpers.Safe(func() error {
+		enc.Encode(buf, r, color)
+		return nil
+	})
+	if err != nil {
+		l.reportError(fmt.Errorf("encoder %T: %w", enc, err))
+		buf.Truncate(n)
+		TextEncoder{}.Encode(buf, r, color)
+	}
+}
+
+// writef writes one of the logger's own messages, bypassing the queue.
+func (l *Mylogger) writef(e Level, format string, args ...any) {
+	l.write(entry{level: e, value: format, args: args, format: true})
+}
+
+// text renders the entry's message. Only entries logged with a format are
+// formatted, so a plain message containing % verbs is written as it is.
+func (en entry) text() string {
+	if en.format {
+		return fmt.Sprintf(message(en.value), en.args...)
+	}
+	return message(en.value)
+}
+
+// message returns the text of a logged value.
+func message(a any) string {
 	switch t := a.(type) {
 	case error:
-		return t
+		return t.Error()
 	case string:
-		return errors.New(t)
-	default:
-		return nil
+		return t
 	}
+	return fmt.Sprint(a)
 }
 
 // Kill the server.
-func (l Mylogger) Shutdown(e error) bool {
+func (l *Mylogger) Shutdown(e error) bool {
 	return l.genericshutdownSequence(e)
 }
 
+// Returns a channel that's closed once shutdown starts, by Shutdown or a
+// signal. Routines tracked with Go or AddToWaitGroup should return when it is.
+func (l *Mylogger) Stopping() <-chan any {
+	return l.chans.done
+}
+
 // Returns start time of server.
-func (l Mylogger) StartTime() time.Time {
+func (l *Mylogger) StartTime() time.Time {
 	return l.start
 }
 
+// Returns the ID of this run of the server, a time ordered UUID that
+// correlates every entry a process logged.
+func (l *Mylogger) RunID() string {
+	return l.runID
+}
+
 // Log Critical Error and shutdown
 func (l *Mylogger) Critical(a any) {
+	l.critical(entry{level: CRITICAL, value: a})
+}
+
+// Log Critical Error with a format, like fmt.Printf, and shutdown
+func (l *Mylogger) Criticalf(format string, args ...any) {
+	l.critical(entry{level: CRITICAL, value: format, args: args, format: true})
+}
+
+func (l *Mylogger) critical(en entry) {
+	en.at = l.now()
+	if l.caller {
+		en.caller = caller()
+	}
 	// Abort all operations and shutdown server.
-	err := cioe(a)
-	l.critlog.Fatal(err.Error())
+	// write out what's already queued, so the critical entry is the last thing logged.
+	l.flush()
+	if l.spill != nil {
+		// best effort: an entry being replayed right now may be written twice.
+		l.spill.drain(l)
+		l.flush()
+	}
+	l.write(en)
+	helpers.RunExitHooks()
+	os.Exit(1)
+}
+
+// flush writes out the entries currently queued, without closing any
+// channels. It's for goroutines other than the mediator: the queue is read
+// under sendMu, as resizeQueue replaces it, and again if it was replaced
+// while being flushed.
+func (l *Mylogger) flush() {
+	for {
+		entries := l.queue()
+		l.writeQueued(entries)
+		if l.queue() == entries {
+			return
+		}
+	}
+}
+
+// writeQueued writes out the entries currently in entries.
+func (l *Mylogger) writeQueued(entries chan entry) {
+	for {
+		select {
+		case e := <-entries:
+			l.writeEntry(e)
+		default:
+			return
+		}
+	}
+}
+
+// queue returns the entries queue, for goroutines other than the mediator.
+func (l *Mylogger) queue() chan entry {
+	l.sendMu.RLock()
+	defer l.sendMu.RUnlock()
+	return l.chans.entries
 }
 
 // Log Error
 func (l *Mylogger) Error(a any) {
-	l.chans.err <- a
+	if l.enabled(ERROR) {
+		l.send(entry{level: ERROR, value: a})
+	}
+}
+
+// Log Error with a format, like fmt.Printf
+func (l *Mylogger) Errorf(format string, args ...any) {
+	if l.enabled(ERROR) {
+		l.send(entry{level: ERROR, value: format, args: args, format: true})
+	}
 }
 
 // Log Debug Message
 func (l *Mylogger) Debug(a any) {
-	// if verbose is set, send to debug channel, else return.
-	if l.verbose {
-		l.chans.debug <- a
+	// if the level is set to DEBUG, send to debug channel, else return.
+	if l.enabled(DEBUG) {
+		l.send(entry{level: DEBUG, value: a})
 	} else {
 		return
 	}
 }
 
+// Log Debug Message with a format, like fmt.Printf
+func (l *Mylogger) Debugf(format string, args ...any) {
+	if l.enabled(DEBUG) {
+		l.send(entry{level: DEBUG, value: format, args: args, format: true})
+	}
+}
+
 // Log Warning
 func (l *Mylogger) Warning(a any) {
-	l.chans.warn <- a
+	if l.enabled(WARNING) {
+		l.send(entry{level: WARNING, value: a})
+	}
+}
+
+// Log Warning with a format, like fmt.Printf
+func (l *Mylogger) Warningf(format string, args ...any) {
+	if l.enabled(WARNING) {
+		l.send(entry{level: WARNING, value: format, args: args, format: true})
+	}
 }
 
 // Log Information
 func (l *Mylogger) Info(a any) {
-	l.chans.info <- a
+	if l.enabled(INFO) {
+		l.send(entry{level: INFO, value: a})
+	}
+}
+
+// Log Information with a format, like fmt.Printf
+func (l *Mylogger) Infof(format string, args ...any) {
+	if l.enabled(INFO) {
+		l.send(entry{level: INFO, value: format, args: args, format: true})
+	}
 }
 
 // shutsdown logger routine. This is not a graceful exit.
+// In sync mode there's no routine to stop, and Quit does nothing.
 func (l *Mylogger) Quit(a any) {
+	if l.sync {
+		return
+	}
 	l.chans.quit <- a
 }
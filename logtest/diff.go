@@ -0,0 +1,74 @@
+package logtest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is how many unchanged lines are shown around a change.
+const diffContext = 2
+
+// diffLines returns a line diff of want and got, with "-" for lines only in
+// want, "+" for lines only in got, and a little unchanged context around
+// them. It uses the longest common subsequence, which is plenty for the
+// size of a test's log.
+func diffLines(want, got []string) string {
+	// lcs[i][j] is the length of the longest common subsequence of want[i:] and got[j:].
+	lcs := make([][]int, len(want)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(got)+1)
+	}
+	for i := len(want) - 1; i >= 0; i-- {
+		for j := len(got) - 1; j >= 0; j-- {
+			if want[i] == got[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	type line struct {
+		op   byte // ' ', '-' or '+'
+		text string
+	}
+	var lines []line
+	i, j := 0, 0
+	for i < len(want) || j < len(got) {
+		switch {
+		case i < len(want) && j < len(got) && want[i] == got[j]:
+			lines = append(lines, line{' ', want[i]})
+			i++
+			j++
+		case i < len(want) && (j == len(got) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{'-', want[i]})
+			i++
+		default:
+			lines = append(lines, line{'+', got[j]})
+			j++
+		}
+	}
+	// keep the changes and diffContext lines around each.
+	keep := make([]bool, len(lines))
+	for k, ln := range lines {
+		if ln.op == ' ' {
+			continue
+		}
+		for c := max(0, k-diffContext); c <= min(len(lines)-1, k+diffContext); c++ {
+			keep[c] = true
+		}
+	}
+	var sb strings.Builder
+	skipped := false
+	for k, ln := range lines {
+		if !keep[k] {
+			skipped = true
+			continue
+		}
+		if skipped {
+			sb.WriteString("  ...\n")
+			skipped = false
+		}
+		fmt.Fprintf(&sb, "%c %s\n", ln.op, ln.text)
+	}
+	return sb.String()
+}
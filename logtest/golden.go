@@ -0,0 +1,166 @@
+// Package logtest locks down a program's logging in tests. A Recorder is a
+// logger that keeps its entries, as JSON lines stamped by a frozen clock,
+// and compares them with a golden file:
+//
+//	func TestMigrate(t *testing.T) {
+//		rec := logtest.Record(t)
+//		migrate(rec.Mylogger)
+//		rec.CompareGolden("testdata/migrate.golden")
+//	}
+//
+// Run the tests with UPDATE_GOLDEN=1 to write the golden files from the
+// current output, and review the change like any other.
+package logtest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeanhaley32/logger"
+)
+
+// TB is the part of testing.TB a Recorder needs.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+	Logf(format string, args ...any)
+	Cleanup(func())
+}
+
+// FrozenTime is the time every recorded entry is stamped with.
+var FrozenTime = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// replacement rewrites the parts of the output that change between runs.
+type replacement struct {
+	re   *regexp.Regexp
+	with string
+}
+
+// defaultReplacements normalize the run ID, operation IDs and durations.
+var defaultReplacements = []replacement{
+	{regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`), "<uuid>"},
+	{regexp.MustCompile(`("(?:parent_)?op_id":)"[0-9A-Za-z]+"`), `$1"<id>"`},
+	{regexp.MustCompile(`\b(?:\d+h)?(?:\d+m)?\d+(?:\.\d+)?(?:ns|µs|us|ms|s)\b`), "<duration>"},
+}
+
+// Recorder is a logger whose entries are kept for comparison, see Record.
+type Recorder struct {
+	*logger.Mylogger
+	t       TB
+	buf     *lockedBuffer
+	replace []replacement
+}
+
+// Record returns a Recorder for the test t. It logs every level, in sync
+// mode so entries are in the buffer as soon as the log call returns, as
+// JSON lines stamped with FrozenTime. opts are applied after those
+// settings, and can change them. The logger is shut down when the test
+// ends.
+func Record(t TB, opts ...logger.Option) *Recorder {
+	t.Helper()
+	buf := &lockedBuffer{}
+	base := []logger.Option{
+		logger.WithSyncMode(),
+		logger.WithOutput(buf),
+		logger.WithEncoder(logger.JSONEncoder{}),
+		logger.WithLevel(logger.DEBUG),
+		logger.WithClock(func() time.Time { return FrozenTime }),
+	}
+	l, err := logger.StartLogger(append(base, opts...)...)
+	if err != nil {
+		t.Fatalf("logtest: %v", err)
+	}
+	t.Cleanup(func() { l.Shutdown(nil) })
+	return &Recorder{Mylogger: l, t: t, buf: buf, replace: defaultReplacements}
+}
+
+// Replace adds a normalization: matches of the regular expression pattern
+// in the output are replaced with with, which can refer to submatches as
+// regexp.ReplaceAllString does. Use it for values the program generates,
+// like IDs and addresses.
+func (r *Recorder) Replace(pattern, with string) {
+	r.t.Helper()
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		r.t.Fatalf("logtest: %v", err)
+	}
+	r.replace = append(r.replace[:len(r.replace):len(r.replace)], replacement{re, with})
+}
+
+// Output returns the entries recorded so far, normalized.
+func (r *Recorder) Output() string {
+	out := r.buf.String()
+	for _, rep := range r.replace {
+		out = rep.re.ReplaceAllString(out, rep.with)
+	}
+	return out
+}
+
+// Reset discards the entries recorded so far.
+func (r *Recorder) Reset() {
+	r.buf.Reset()
+}
+
+// CompareGolden fails the test if the normalized entries differ from the
+// golden file at path, showing the lines that differ. With UPDATE_GOLDEN
+// set in the environment it writes the file instead.
+func (r *Recorder) CompareGolden(path string) {
+	r.t.Helper()
+	got := r.Output()
+	if os.Getenv("UPDATE_GOLDEN") != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			r.t.Fatalf("logtest: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			r.t.Fatalf("logtest: %v", err)
+		}
+		r.t.Logf("logtest: wrote %s", path)
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		r.t.Fatalf("logtest: %v (run with UPDATE_GOLDEN=1 to create it)", err)
+	}
+	if got == string(want) {
+		return
+	}
+	r.t.Errorf("logtest: entries differ from %s (-want +got):\n%s", path, diffLines(splitLines(string(want)), splitLines(got)))
+}
+
+// splitLines splits s into lines, without a trailing empty one.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// lockedBuffer is a bytes.Buffer safe for concurrent use.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (b *lockedBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Reset()
+}
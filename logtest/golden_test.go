@@ -0,0 +1,147 @@
+package logtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeanhaley32/logger"
+)
+
+// fakeTB records what a Recorder reports, to test the failures themselves.
+type fakeTB struct {
+	errors, fatals, logs []string
+	cleanups             []func()
+}
+
+// fatal stops the code under test at Fatalf, as testing.T does.
+type fatal struct{}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Fatalf(format string, args ...any) {
+	f.fatals = append(f.fatals, fmt.Sprintf(format, args...))
+	panic(fatal{})
+}
+
+func (f *fakeTB) Logf(format string, args ...any) {
+	f.logs = append(f.logs, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+// run calls fn, stopping at a Fatalf of f.
+func (f *fakeTB) run(fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(fatal); !ok {
+				panic(r)
+			}
+		}
+	}()
+	fn()
+}
+
+// migrate logs what changes between runs: operation IDs, a UUID and durations.
+func migrate(l *logger.Mylogger) {
+	op := l.Begin("migrate")
+	sub := op.Begin("create table")
+	sub.Info(fmt.Sprintf("request %s", "6ba7b810-9dad-11d1-80b4-00c04fd430c8"))
+	sub.End(nil)
+	op.With(logger.Field("took", 1500*time.Millisecond), logger.Field("wait", 250*time.Microsecond)).Info("slow step")
+	op.End(nil)
+}
+
+func TestCompareGolden(t *testing.T) {
+	rec := Record(t)
+	migrate(rec.Mylogger)
+	rec.CompareGolden("testdata/migrate.golden")
+}
+
+func TestDefaultReplacements(t *testing.T) {
+	rec := Record(t)
+	migrate(rec.Mylogger)
+	out := rec.Output()
+	for _, want := range []string{`"op_id":"<id>"`, `"parent_op_id":"<id>"`, "request <uuid>", `"took":"<duration>"`, `"wait":"<duration>"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %s:\n%s", want, out)
+		}
+	}
+	for _, raw := range []string{"6ba7b810", "1.5s", "250µs"} {
+		if strings.Contains(out, raw) {
+			t.Errorf("output still holds %s:\n%s", raw, out)
+		}
+	}
+}
+
+func TestRecordShutsDownAtCleanup(t *testing.T) {
+	var rec *Recorder
+	t.Run("test", func(t *testing.T) { rec = Record(t) })
+	select {
+	case <-rec.Stopping():
+	default:
+		t.Error("the logger is still running after the test")
+	}
+}
+
+func TestUpdateGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "new.golden")
+	t.Setenv("UPDATE_GOLDEN", "1")
+	var tb fakeTB
+	rec := Record(&tb)
+	defer rec.Shutdown(nil)
+	migrate(rec.Mylogger)
+	rec.CompareGolden(path)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != rec.Output() {
+		t.Errorf("wrote %q, want the normalized output %q", b, rec.Output())
+	}
+	if len(tb.logs) != 1 || !strings.Contains(tb.logs[0], "wrote "+path) {
+		t.Errorf("logged %q", tb.logs)
+	}
+
+	// what was written compares equal.
+	t.Setenv("UPDATE_GOLDEN", "")
+	rec.CompareGolden(path)
+	if len(tb.errors)+len(tb.fatals) > 0 {
+		t.Errorf("the written file doesn't match: %q %q", tb.errors, tb.fatals)
+	}
+}
+
+func TestGoldenMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migrate.golden")
+	want := `{"msg":"one"}` + "\n" + `{"msg":"two"}` + "\n"
+	if err := os.WriteFile(path, []byte(want), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var tb fakeTB
+	rec := Record(&tb, logger.WithEncoder(logger.TextEncoder{}))
+	defer rec.Shutdown(nil)
+	rec.Info("one")
+	tb.run(func() { rec.CompareGolden(path) })
+	if len(tb.errors) != 1 {
+		t.Fatalf("reported %q, want one difference", tb.errors)
+	}
+	msg := tb.errors[0]
+	if !strings.Contains(msg, "entries differ from "+path) || !strings.Contains(msg, "\n- {\"msg\":\"two\"}\n") || !strings.Contains(msg, "\n+ 2000-01-01 00:00:00:INFO: one\n") {
+		t.Errorf("reported:\n%s", msg)
+	}
+
+	tb = fakeTB{}
+	tb.run(func() { rec.CompareGolden(filepath.Join(t.TempDir(), "missing.golden")) })
+	if len(tb.fatals) != 1 || !strings.Contains(tb.fatals[0], "run with UPDATE_GOLDEN=1 to create it") {
+		t.Errorf("missing golden file reported %q", tb.fatals)
+	}
+}
@@ -0,0 +1,53 @@
+package logger
+
+import "sync"
+
+// maxOnceKeys bounds the keys Once and EveryN remember. Past it, an
+// arbitrary key is forgotten for each new one, and may log again.
+const maxOnceKeys = 10000
+
+// counts are how many times Once and EveryN were called for each key.
+type counts struct {
+	mu sync.Mutex
+	n  map[string]uint64
+}
+
+// next counts a call for key and returns the number of calls before it.
+func (c *counts) next(key string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.n == nil {
+		c.n = map[string]uint64{}
+	}
+	n, ok := c.n[key]
+	if !ok && len(c.n) >= maxOnceKeys {
+		for k := range c.n {
+			delete(c.n, k)
+			break
+		}
+	}
+	c.n[key] = n + 1
+	return n
+}
+
+// Once returns a Scope that logs the first time it's asked for with key,
+// and discards entries every time after, for problems that would otherwise
+// be logged on every pass of a loop.
+// Example:
+// l.Once("config-missing").Warning("no config file, using defaults")
+func (l *Mylogger) Once(key string) *Scope {
+	return l.EveryN(key, 0)
+}
+
+// EveryN returns a Scope that logs on the first and then every nth time it's
+// asked for with key, and discards entries the rest of the time. An n of 0
+// logs only the first time, like Once. Critical is never discarded.
+// Example:
+// l.EveryN("retry", 100).Infof("retrying %s", url) // 1st, 101st, 201st...
+func (l *Mylogger) EveryN(key string, n uint64) *Scope {
+	c := l.once.next(key)
+	if c == 0 || n > 0 && c%n == 0 {
+		return &Scope{l: l}
+	}
+	return &Scope{l: l, muted: true}
+}
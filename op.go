@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// Operation is a Scope for one unit of work, a lightweight span: every
+// entry logged through it carries the operation's name and ID, and End logs
+// how long it took and how it went.
+// Example:
+// op := l.Begin("migrate-db")
+// op.Info("applying 3 migrations") // {"msg":"applying 3 migrations","op":"migrate-db","op_id":"k3x9..."}
+// op.End(err)                      // "migrate-db finished in 2.1s", or failed at ERROR
+type Operation struct {
+	*Scope
+	base  *Scope // the scope it was begun in, for sub-operations
+	name  string
+	id    string
+	start time.Time
+	ended atomic.Bool
+}
+
+// Begin starts the operation name, with attrs on every entry, and logs its
+// start at DEBUG.
+func (l *Mylogger) Begin(name string, attrs ...Attr) *Operation {
+	return (&Scope{l: l}).Begin(name, attrs...)
+}
+
+// Begin starts the operation name within s, keeping its fields, see
+// Mylogger.Begin.
+func (s *Scope) Begin(name string, attrs ...Attr) *Operation {
+	id := helpers.ShortID(11)
+	op := &Operation{Scope: s.With(append([]Attr{Field("op", name), Field("op_id", id)}, attrs...)...), base: s, name: name, id: id, start: time.Now()}
+	op.Debugf("%s started", name)
+	return op
+}
+
+// Begin starts a sub-operation, whose entries carry this operation's ID as
+// parent_op_id.
+func (o *Operation) Begin(name string, attrs ...Attr) *Operation {
+	return o.base.With(Field("parent_op_id", o.id)).Begin(name, attrs...)
+}
+
+// ID returns the operation's ID, the op_id field of its entries.
+func (o *Operation) ID() string {
+	return o.id
+}
+
+// End logs the outcome of the operation with a duration field: "finished"
+// at INFO if err is nil, "failed" with err at its severity (see LogError)
+// otherwise. It returns how long the operation took. Only the first call
+// logs, and counts towards the operation's latency percentiles in the
+// heartbeat and the shutdown report.
+func (o *Operation) End(err error) time.Duration {
+	d := time.Since(o.start)
+	if !o.ended.CompareAndSwap(false, true) {
+		return d
+	}
+	o.l.ops.observe(o.name, d)
+	s := o.With(Field("duration", d.String()))
+	if err == nil {
+		s.With(Field("outcome", "ok")).Infof("%s finished in %s", o.name, helpers.Duration(d))
+		return d
+	}
+	s.With(Field("outcome", "error")).LogError(fmt.Errorf("%s failed after %s: %w", o.name, helpers.Duration(d), err))
+	return d
+}
@@ -0,0 +1,234 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Option configures a logger started with StartLogger. Options are checked
+// before anything is started, and the first invalid one is returned as an error.
+type Option func(*Mylogger) error
+
+// WithOutput writes entries to w instead of stderr. The logger doesn't close w.
+func WithOutput(w io.Writer) Option {
+	return func(l *Mylogger) error {
+		if err := checkOutput(w); err != nil {
+			return err
+		}
+		l.out.setOwned(w, false)
+		l.sinks[0].errw.Store(nil)
+		return nil
+	}
+}
+
+// WithSink writes entries to w as well as the main output, in the format of
+// enc if one is given, text otherwise. Colors are decided per output:
+// terminals get them, files and pipes get plain text. The logger doesn't
+// close w.
+func WithSink(w io.Writer, enc ...Encoder) Option {
+	return func(l *Mylogger) error {
+		if err := checkOutput(w); err != nil {
+			return err
+		}
+		var e Encoder = TextEncoder{}
+		if len(enc) > 0 && enc[0] != nil {
+			e = enc[0]
+		}
+		l.sinks = append(l.sinks, newSink(newSwapWriter(w, l.stats.bytes), e))
+		return nil
+	}
+}
+
+// WithEncoder sets the format of the main output, e.g. JSONEncoder{}.
+func WithEncoder(enc Encoder) Option {
+	return func(l *Mylogger) error {
+		if enc == nil {
+			return errors.New("encoder is nil")
+		}
+		l.sinks[0].setEncoder(enc)
+		return nil
+	}
+}
+
+// WithFormat sets the format of the main output by name: text, json, logfmt,
+// docker, cbor or one added with RegisterEncoder.
+func WithFormat(name string) Option {
+	return func(l *Mylogger) error {
+		enc, err := encoderByName(name)
+		if err != nil {
+			return err
+		}
+		l.sinks[0].setEncoder(enc)
+		return nil
+	}
+}
+
+// WithStdStreams writes DEBUG and INFO entries to stdout and WARNING and
+// above to stderr, as most CI systems and container orchestrators expect.
+// Both get the main output's format. A later WithOutput or WithFile puts
+// everything back in one output.
+func WithStdStreams() Option {
+	return func(l *Mylogger) error {
+		l.out.setOwned(os.Stdout, false)
+		l.sinks[0].errw.Store(newSwapWriter(os.Stderr, l.stats.bytes))
+		return nil
+	}
+}
+
+// WithCaller records the file and line that logged each entry. It costs a
+// stack walk per entry.
+func WithCaller() Option {
+	return func(l *Mylogger) error {
+		l.caller = true
+		return nil
+	}
+}
+
+// checkOutput rejects nil writers and files that can't be written to.
+func checkOutput(w io.Writer) error {
+	if w == nil {
+		return errors.New("output is nil")
+	}
+	if f, ok := w.(*os.File); ok {
+		if f == nil {
+			return errors.New("output is a nil *os.File")
+		}
+		if _, err := f.Stat(); err != nil {
+			return fmt.Errorf("output %s is not usable: %w", f.Name(), err)
+		}
+	}
+	return nil
+}
+
+// WithFile appends entries to the file at path, creating it if needed.
+// The directory must exist and be writable.
+func WithFile(path string) Option {
+	return func(l *Mylogger) error {
+		if path == "" {
+			return errors.New("output file path is empty")
+		}
+		if info, err := os.Stat(filepath.Dir(path)); err != nil {
+			return fmt.Errorf("output directory: %w", err)
+		} else if !info.IsDir() {
+			return fmt.Errorf("output directory %s is not a directory", filepath.Dir(path))
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("output file: %w", err)
+		}
+		l.out.setOwned(f, true)
+		l.sinks[0].errw.Store(nil)
+		return nil
+	}
+}
+
+// WithLevel sets the minimum level logged, INFO by default.
+func WithLevel(e Level) Option {
+	return func(l *Mylogger) error {
+		if e.severity() < 0 {
+			return fmt.Errorf("unknown level %v", e)
+		}
+		l.setLevel(e, "")
+		return nil
+	}
+}
+
+// WithVerbose logs DEBUG entries too.
+func WithVerbose() Option {
+	return WithLevel(DEBUG)
+}
+
+// WithTimeFormat sets the Go time layout of the entries' timestamps, or one
+// of the named formats of helpers.FormatTime, e.g. "iso8601".
+func WithTimeFormat(layout string) Option {
+	return func(l *Mylogger) error {
+		if err := checkTimeFormat(layout); err != nil {
+			return err
+		}
+		l.setStyle(func(s *style) { s.timeFormat = layout })
+		return nil
+	}
+}
+
+// WithSyncMode writes each entry from the calling goroutine, under a mutex,
+// instead of queueing it for the mediator goroutine. No goroutines are
+// started and no signal handlers installed, so there's nothing to drain at
+// exit: meant for short lived command line tools, where an entry logged just
+// before os.Exit must not be lost.
+func WithSyncMode() Option {
+	return func(l *Mylogger) error {
+		l.sync = true
+		return nil
+	}
+}
+
+// WithGuaranteedDelivery makes logging at min and above wait until the entry
+// is written, while less severe entries are fire and forget. CRITICAL
+// entries are always written before Critical returns.
+// Example:
+// l, err := StartLogger(WithFile(path), WithGuaranteedDelivery(ERROR)) // errors are on disk once Error returns
+func WithGuaranteedDelivery(min Level) Option {
+	return func(l *Mylogger) error {
+		if min.severity() < 0 {
+			return fmt.Errorf("unknown level %v", min)
+		}
+		l.guaranteed = map[Level]bool{}
+		for _, e := range []Level{DEBUG, INFO, WARNING, ERROR, CRITICAL} {
+			l.guaranteed[e] = e.severity() >= min.severity()
+		}
+		return nil
+	}
+}
+
+// WithElapsed adds an "elapsed" field to every entry: the time since the
+// logger started, on the monotonic clock, so phases of a startup can be
+// compared without subtracting timestamps.
+func WithElapsed() Option {
+	return func(l *Mylogger) error {
+		l.elapsed = true
+		return nil
+	}
+}
+
+// WithClock stamps entries with the time now returns instead of the current
+// time, for tests that compare output: a frozen clock makes every run log
+// the same timestamps.
+func WithClock(now func() time.Time) Option {
+	return func(l *Mylogger) error {
+		if now == nil {
+			return errors.New("clock is nil")
+		}
+		l.now = now
+		l.start = now()
+		return nil
+	}
+}
+
+// WithLockedThread runs the mediator on an OS thread of its own
+// (runtime.LockOSThread), so writing entries out doesn't compete with other
+// goroutines for that thread. It can lower the tail latency of log calls in
+// latency sensitive programs when GOMAXPROCS leaves a spare CPU; measure with
+// cmd/logbench's "locked" mode before turning it on.
+func WithLockedThread() Option {
+	return func(l *Mylogger) error {
+		l.lockThread = true
+		return nil
+	}
+}
+
+// WithQueueSize sets how many entries can wait for the mediator before
+// logging blocks (or spills, see WithSpill). The default is 100; a deeper
+// queue absorbs longer bursts at the cost of memory.
+func WithQueueSize(n int) Option {
+	return func(l *Mylogger) error {
+		if n <= 0 {
+			return fmt.Errorf("queue size %d must be positive", n)
+		}
+		l.queueSize = n
+		return nil
+	}
+}
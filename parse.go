@@ -0,0 +1,303 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry is a line written by one of the encoders, parsed back by ParseEntry.
+type Entry struct {
+	Record
+	Format  string // "json", "logfmt" or "text"
+	Version int    // "v" of JSON entries, 1 if it's missing; 0 for the other formats
+}
+
+// ParseEntry parses a line written by JSONEncoder, DockerEncoder,
+// LogfmtEncoder or TextEncoder, telling the formats apart by their shape.
+// Lines in other formats, or without a time, level and message, return an
+// error. It doesn't panic whatever the line holds, so it can be fuzzed.
+//
+// Encoding the parsed Record again with the same encoder gives the line
+// back, as far as the format keeps the record:
+//   - JSON: numbers come back as json.Number, objects as groups, and other
+//     values as encoding/json decodes them into an any. A docker entry's
+//     stream is a field.
+//   - logfmt and text: values come back as strings, and groups as dotted
+//     keys, billing.plan.
+//   - text: the time is read in the default layout, iso8601 or
+//     rfc3339nano, to its precision, in the local time zone, and is zero
+//     for the isoweek and relative formats; other layouts set with
+//     WithTimeFormat don't parse. Trailing key=value pairs are taken to be fields,
+//     so a message ending in one loses it to them.
+func ParseEntry(line []byte) (Entry, error) {
+	line = bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))
+	switch {
+	case len(line) == 0:
+		return Entry{}, errors.New("empty line")
+	case line[0] == '{':
+		return parseJSONEntry(line)
+	case bytes.HasPrefix(line, []byte("time=")):
+		return parseLogfmtEntry(string(line))
+	}
+	return parseTextEntry(string(line))
+}
+
+func parseJSONEntry(line []byte) (Entry, error) {
+	attrs, err := decodeJSONObject(line)
+	if err != nil {
+		return Entry{}, err
+	}
+	e := Entry{Format: "json", Version: 1}
+	var hasTime, hasLevel, hasMsg, hasCaller, hasVersion bool
+	for _, a := range attrs {
+		// the first of each is the entry's own, later ones are fields.
+		switch {
+		case a.Key == "v" && !hasVersion:
+			hasVersion = true
+			n, ok := a.Value.(json.Number)
+			v, err := n.Int64()
+			if !ok || err != nil {
+				return Entry{}, fmt.Errorf("bad version %v", a.Value)
+			}
+			e.Version = int(v)
+		case a.Key == "time" && !hasTime:
+			hasTime = true
+			s, _ := a.Value.(string)
+			if e.Time, err = time.Parse(time.RFC3339Nano, s); err != nil {
+				return Entry{}, fmt.Errorf("bad time %v", a.Value)
+			}
+		case a.Key == "level" && !hasLevel:
+			hasLevel = true
+			s, _ := a.Value.(string)
+			if e.Level, err = ParseLevel(s); err != nil {
+				return Entry{}, err
+			}
+		case a.Key == "msg" && !hasMsg:
+			hasMsg = true
+			s, ok := a.Value.(string)
+			if !ok {
+				return Entry{}, fmt.Errorf("bad message %v", a.Value)
+			}
+			e.Message = s
+		case a.Key == "caller" && !hasCaller:
+			hasCaller = true
+			e.Caller, _ = a.Value.(string)
+		default:
+			e.Attrs = append(e.Attrs, a)
+		}
+	}
+	if !hasTime || !hasLevel || !hasMsg {
+		return Entry{}, errors.New("not a log entry: no time, level or msg")
+	}
+	return e, nil
+}
+
+// decodeJSONObject decodes a JSON object into fields, in the order they're
+// written, with objects nested as groups and numbers as json.Number.
+func decodeJSONObject(data []byte) ([]Attr, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if t, err := dec.Token(); err != nil {
+		return nil, err
+	} else if t != json.Delim('{') {
+		return nil, errors.New("not a JSON object")
+	}
+	var attrs []Attr
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := t.(string)
+		if !ok {
+			return nil, fmt.Errorf("bad key %v", t)
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		var v any
+		if raw[0] == '{' {
+			g, err := decodeJSONObject(raw)
+			if err != nil {
+				return nil, err
+			}
+			// empty groups aren't written, so {} was a value.
+			v = g
+			if len(g) == 0 {
+				v = map[string]any{}
+			}
+		} else {
+			d := json.NewDecoder(bytes.NewReader(raw))
+			d.UseNumber()
+			if err := d.Decode(&v); err != nil {
+				return nil, err
+			}
+		}
+		attrs = append(attrs, Attr{Key: key, Value: v})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("data after the JSON object")
+	}
+	return attrs, nil
+}
+
+func parseLogfmtEntry(line string) (Entry, error) {
+	pairs, ok := splitLogfmt(line)
+	if !ok || len(pairs) < 3 || pairs[0].Key != "time" || pairs[1].Key != "level" || pairs[2].Key != "msg" {
+		return Entry{}, errors.New("not a logfmt entry: want time, level and msg first")
+	}
+	e := Entry{Format: "logfmt"}
+	var err error
+	if e.Time, err = time.Parse(time.RFC3339Nano, pairs[0].Value.(string)); err != nil {
+		return Entry{}, fmt.Errorf("bad time %q", pairs[0].Value)
+	}
+	if e.Level, err = ParseLevel(pairs[1].Value.(string)); err != nil {
+		return Entry{}, err
+	}
+	e.Message = pairs[2].Value.(string)
+	pairs = pairs[3:]
+	if len(pairs) > 0 && pairs[0].Key == "caller" {
+		e.Caller = pairs[0].Value.(string)
+		pairs = pairs[1:]
+	}
+	e.Attrs = pairs
+	return e, nil
+}
+
+// textHeader matches the start of a text entry, "2006-01-02 15:04:05:INFO:",
+// with the level in color or not.
+var textHeader = regexp.MustCompile(`^(.*?):((?:\x1b\[[0-9;]*m)*)(DEBUG|INFO|WARNING|ERROR|CRITICAL):((?:\x1b\[[0-9;]*m)*)`)
+
+func parseTextEntry(line string) (Entry, error) {
+	m := textHeader.FindStringSubmatchIndex(line)
+	if m == nil {
+		return Entry{}, errors.New("not a log entry")
+	}
+	e := Entry{Format: "text"}
+	var err error
+	if e.Time, err = parseTextTime(line[m[2]:m[3]]); err != nil {
+		return Entry{}, err
+	}
+	e.Level, _ = ParseLevel(line[m[6]:m[7]])
+	// then " message", or "file.go:12: message" with the caller.
+	rest := line[m[1]:]
+	if strings.HasPrefix(rest, " ") {
+		rest = rest[1:]
+	} else if i := strings.Index(rest, ": "); i > 0 {
+		e.Caller, rest = rest[:i], rest[i+2:]
+	} else {
+		return Entry{}, errors.New("not a log entry: no message")
+	}
+	e.Message, e.Attrs = splitTextFields(rest)
+	return e, nil
+}
+
+// textTimeLayouts are the layouts a text entry's time is read with: the
+// default, and the named formats that can be read back.
+var textTimeLayouts = []string{defaultStyle.timeFormat, "2006-01-02T15:04:05.000-07:00", time.RFC3339Nano}
+
+// isoWeekRe matches a time written in the isoweek format.
+var isoWeekRe = regexp.MustCompile(`^\d{4}-W\d\d-\d$`)
+
+// parseTextTime parses a text entry's time, see ParseEntry.
+func parseTextTime(s string) (time.Time, error) {
+	for _, layout := range textTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if isoWeekRe.MatchString(s) || s == "just now" || strings.HasSuffix(s, " ago") || strings.HasPrefix(s, "in ") {
+		// isoweek and relative times can't be read back.
+		return time.Time{}, nil
+	}
+	return time.Time{}, fmt.Errorf("bad time %q", s)
+}
+
+// splitTextFields splits the end of a text entry into its message and the
+// key=value pairs after it: the longest run of pairs reaching the end of
+// the line.
+func splitTextFields(s string) (string, []Attr) {
+	// tail[i] tells whether s[i:] is nothing but pairs, worked out from the end.
+	tail := make(map[int]bool)
+	var pairsFrom func(i int) bool
+	pairsFrom = func(i int) bool {
+		if ok, seen := tail[i]; seen {
+			return ok
+		}
+		_, end, ok := logfmtPair(s, i)
+		ok = ok && (end == len(s) || (s[end] == ' ' && pairsFrom(end+1)))
+		tail[i] = ok
+		return ok
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] == ' ' && pairsFrom(i+1) {
+			attrs, _ := splitLogfmt(s[i+1:])
+			return s[:i], attrs
+		}
+	}
+	return s, nil
+}
+
+// splitLogfmt splits a line of space separated key=value pairs, as
+// LogfmtEncoder writes them. It returns false if s holds anything else.
+func splitLogfmt(s string) ([]Attr, bool) {
+	var attrs []Attr
+	for i := 0; i < len(s); {
+		a, end, ok := logfmtPair(s, i)
+		if !ok || (end < len(s) && (s[end] != ' ' || end+1 == len(s))) {
+			return nil, false
+		}
+		attrs = append(attrs, a)
+		i = end + 1
+	}
+	return attrs, len(attrs) > 0
+}
+
+// logfmtPair parses the key=value pair at s[i:], returning where it ends.
+// Values are quoted as writeLogfmtValue does.
+func logfmtPair(s string, i int) (Attr, int, bool) {
+	eq := strings.IndexByte(s[i:], '=')
+	if eq <= 0 || strings.ContainsAny(s[i:i+eq], ` "`) {
+		return Attr{}, 0, false
+	}
+	key := s[i : i+eq]
+	i += eq + 1
+	if i < len(s) && s[i] == '"' {
+		j := i + 1
+		for ; j < len(s) && s[j] != '"'; j++ {
+			if s[j] == '\\' {
+				j++
+			}
+		}
+		if j >= len(s) {
+			return Attr{}, 0, false
+		}
+		v, err := strconv.Unquote(s[i : j+1])
+		if err != nil {
+			return Attr{}, 0, false
+		}
+		return Attr{Key: key, Value: v}, j + 1, true
+	}
+	j := i
+	for j < len(s) && s[j] != ' ' {
+		if logfmtSpecial(rune(s[j])) {
+			return Attr{}, 0, false
+		}
+		j++
+	}
+	if j == i {
+		return Attr{}, 0, false
+	}
+	return Attr{Key: key, Value: s[i:j]}, j, true
+}
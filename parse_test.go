@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// parseRecords are the records the parse tests encode, with what each format
+// has to escape or quote.
+var parseRecords = []Record{
+	{Time: time.Date(2024, 3, 1, 12, 30, 45, 0, time.Local), Level: INFO, Message: "server listening"},
+	{Time: time.Date(2024, 3, 1, 12, 30, 46, 0, time.Local), Level: ERROR, Message: `query "users" failed: a=b`, Caller: "db.go:42",
+		Attrs: []Attr{Field("table", "users"), Field("rows", json.Number("12")), Field("note", "two words"), Field("billing", []Attr{Field("plan", "pro"), Field("seats", json.Number("3"))})}},
+	{Time: time.Date(2024, 3, 1, 12, 30, 47, 0, time.Local), Level: WARNING, Message: "line one\nline two\ttabbed", Attrs: []Attr{Field("quote", `say "hi"`), Field("empty", "")}},
+	{Time: time.Date(2024, 3, 1, 12, 30, 48, 0, time.Local), Level: DEBUG, Message: "unicode é ✓ \x00"},
+}
+
+// roundTripEncoders are the encoders whose output parses back to the record.
+var roundTripEncoders = map[string]Encoder{
+	"json":   JSONEncoder{},
+	"logfmt": LogfmtEncoder{},
+	"text":   TextEncoder{},
+}
+
+// encoderFor returns the encoder that writes entries of e's format.
+func encoderFor(e Entry) Encoder {
+	switch e.Format {
+	case "json":
+		return JSONEncoder{Schema: e.Version}
+	case "logfmt":
+		return LogfmtEncoder{}
+	}
+	return TextEncoder{}
+}
+
+func encodeRecord(enc Encoder, r Record, color bool) []byte {
+	var buf bytes.Buffer
+	enc.Encode(&buf, r, color)
+	return buf.Bytes()
+}
+
+// Encoding a parsed entry again gives the line back.
+func TestParseEntryRoundTrip(t *testing.T) {
+	for name, enc := range roundTripEncoders {
+		for _, r := range parseRecords {
+			line := encodeRecord(enc, r, false)
+			e, err := ParseEntry(line)
+			if err != nil {
+				t.Errorf("%s: ParseEntry(%q): %v", name, line, err)
+				continue
+			}
+			if e.Format != name {
+				t.Errorf("%s: %q parsed as %s", name, line, e.Format)
+			}
+			if again := encodeRecord(enc, e.Record, false); !bytes.Equal(again, line) {
+				t.Errorf("%s: round trip changed the line:\n%q\n%q", name, line, again)
+			}
+		}
+	}
+}
+
+// JSON keeps the whole record: numbers, groups and the caller.
+func TestParseJSONEntryIsExact(t *testing.T) {
+	for _, r := range parseRecords {
+		e, err := ParseEntry(encodeRecord(JSONEncoder{}, r, false))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !e.Time.Equal(r.Time) || e.Level != r.Level || e.Message != r.Message || e.Caller != r.Caller || !reflect.DeepEqual(e.Attrs, r.Attrs) {
+			t.Errorf("parsed %+v, want %+v", e.Record, r)
+		}
+	}
+}
+
+func FuzzParseEntry(f *testing.F) {
+	for _, r := range parseRecords {
+		for _, enc := range []Encoder{JSONEncoder{}, JSONEncoder{Schema: 1}, DockerEncoder{Stream: "stderr"}, LogfmtEncoder{}, TextEncoder{}} {
+			f.Add(encodeRecord(enc, r, false))
+		}
+		f.Add(encodeRecord(TextEncoder{}, r, true))
+	}
+	f.Fuzz(func(t *testing.T, line []byte) {
+		e, err := ParseEntry(line)
+		if err != nil {
+			return
+		}
+		// whatever parsed, encoding it again must parse to the same encoding.
+		enc := encoderFor(e)
+		first := encodeRecord(enc, e.Record, false)
+		e2, err := ParseEntry(first)
+		if err != nil {
+			t.Fatalf("re-encoded entry %q doesn't parse: %v", first, err)
+		}
+		if second := encodeRecord(encoderFor(e2), e2.Record, false); !bytes.Equal(first, second) {
+			t.Fatalf("encoding isn't stable:\n%q\n%q", first, second)
+		}
+	})
+}
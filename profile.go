@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime/pprof"
+	"sync/atomic"
+	"time"
+
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// ProfileTrigger captures CPU and heap profiles when the entries logged
+// suggest something is wrong, so the evidence is collected while it
+// happens. When is an AlertRule, e.g. 20 errors within a minute, or one
+// entry matching "deadline exceeded"; its Cooldown, at least CPU, keeps
+// profiling from running back to back.
+type ProfileTrigger struct {
+	When AlertRule
+	Dir  string        // where profiles are written, created if needed
+	CPU  time.Duration // how long the CPU is profiled for, 10 seconds if zero
+}
+
+// profiling is set while a CPU profile runs: the runtime can only run one.
+var profiling atomic.Bool
+
+// AddProfileTrigger adds t. Each time it fires, <name>-<time>.cpu.pprof and
+// <name>-<time>.heap.pprof are written to t.Dir, and logged at INFO.
+// Example:
+//
+//	l.AddProfileTrigger(ProfileTrigger{
+//		When: AlertRule{Name: "errors", Threshold: 50, Window: time.Minute, Cooldown: time.Hour},
+//		Dir:  "/var/tmp/profiles",
+//	})
+func (l *Mylogger) AddProfileTrigger(t ProfileTrigger) error {
+	if t.Dir == "" {
+		return fmt.Errorf("add profile trigger: no directory")
+	}
+	if err := os.MkdirAll(t.Dir, 0o755); err != nil {
+		return fmt.Errorf("add profile trigger: %w", err)
+	}
+	if t.CPU <= 0 {
+		t.CPU = 10 * time.Second
+	}
+	t.When.Cooldown = max(t.When.Cooldown, t.CPU)
+	err := l.addAlertRule(t.When, func(a Alert) {
+		go l.captureProfiles(t, a)
+	})
+	if err != nil {
+		return fmt.Errorf("add profile trigger: %w", err)
+	}
+	return nil
+}
+
+// unsafeName matches what shouldn't go in a file name.
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// captureProfiles writes the heap profile, then profiles the CPU for t.CPU.
+func (l *Mylogger) captureProfiles(t ProfileTrigger, a Alert) {
+	name := unsafeName.ReplaceAllString(a.Rule, "_")
+	if name == "" {
+		name = "profile"
+	}
+	base := filepath.Join(t.Dir, fmt.Sprintf("%s-%s", name, a.Last.Time.Format("20060102T150405")))
+	l.Infof("profiling: %s fired after %d entries in %s, writing %s.{heap,cpu}.pprof", a.Rule, a.Count, helpers.Duration(a.Window), base)
+
+	if err := writeProfile(base+".heap.pprof", func(f *os.File) error {
+		return pprof.Lookup("heap").WriteTo(f, 0)
+	}); err != nil {
+		l.reportError(fmt.Errorf("profiling: %w", err))
+	}
+	if !profiling.CompareAndSwap(false, true) {
+		l.reportError(fmt.Errorf("profiling: a CPU profile is already running"))
+		return
+	}
+	defer profiling.Store(false)
+	err := writeProfile(base+".cpu.pprof", func(f *os.File) error {
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return err
+		}
+		select {
+		case <-time.After(t.CPU):
+		case <-l.chans.done:
+		}
+		pprof.StopCPUProfile()
+		return nil
+	})
+	if err != nil {
+		l.reportError(fmt.Errorf("profiling: %w", err))
+		return
+	}
+	l.Infof("profiling: wrote %s.cpu.pprof", base)
+}
+
+// writeProfile creates path and has write fill it.
+func writeProfile(path string, write func(*os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
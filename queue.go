@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// tuneEvery is how often an adaptive queue is resized, if it needs to be.
+var tuneEvery = time.Second
+
+// tuner sizes the entries queue between min and max from what senders went
+// through since the last check, see WithAdaptiveQueue.
+type tuner struct {
+	min, max int
+	full     atomic.Int64   // sends that found the queue full
+	wait     atomic.Int64   // nanoseconds senders waited for room
+	peak     int            // most entries queued at once; only the mediator touches it
+	lost     int64          // entries dropped or spilled as of the last check
+	capacity *helpers.Gauge // the current queue size
+}
+
+// WithAdaptiveQueue lets the queue grow and shrink between min and max
+// entries as traffic changes, instead of having a fixed size. Once a second
+// the queue doubles if senders spent more than 1% of that time waiting for
+// room, or entries were dropped (see TryInfo) or spilled (see WithSpill); it
+// halves if it was never more than a quarter full. Each change is logged at
+// DEBUG, and the current size is the logger_queue_capacity gauge.
+func WithAdaptiveQueue(min, max int) Option {
+	return func(l *Mylogger) error {
+		if min <= 0 || max < min {
+			return fmt.Errorf("adaptive queue bounds %d..%d are invalid", min, max)
+		}
+		l.tune = &tuner{min: min, max: max, capacity: l.stats.gauge("logger_queue_capacity", nil)}
+		return nil
+	}
+}
+
+// enqueue puts en on the queue, waiting for room if it's full. A full queue
+// is noted for Health, and waits are measured for the tuner.
+func (l *Mylogger) enqueue(en entry) {
+	select {
+	case l.chans.entries <- en:
+		return
+	default:
+	}
+	l.health.fullSince.CompareAndSwap(0, time.Now().UnixNano())
+	if l.tune == nil {
+		l.chans.entries <- en
+		return
+	}
+	start := time.Now()
+	l.chans.entries <- en
+	l.tune.full.Add(1)
+	l.tune.wait.Add(int64(time.Since(start)))
+}
+
+// observe records how many entries were queued when one was taken off.
+func (t *tuner) observe(queued int) {
+	t.peak = max(t.peak, queued)
+}
+
+// tuneQueue resizes the queue if the last interval calls for it. It's run by
+// the mediator.
+func (l *Mylogger) tuneQueue() {
+	t := l.tune
+	size := cap(l.chans.entries)
+	full, wait := t.full.Swap(0), time.Duration(t.wait.Swap(0))
+	peak := t.peak
+	t.peak = 0
+	lost := l.stats.dropped.Load()
+	if l.spill != nil {
+		lost += l.spill.count.Load()
+	}
+	newlyLost := lost - t.lost
+	t.lost = lost
+
+	next := size
+	switch {
+	case newlyLost > 0 || wait > tuneEvery/100:
+		next = min(size*2, t.max)
+	case full == 0 && peak < size/4:
+		next = max(size/2, t.min)
+	}
+	if next == size {
+		return
+	}
+	l.resizeQueue(next)
+	t.capacity.Set(float64(next))
+	if l.enabled(DEBUG) {
+		l.writef(DEBUG, "Queue resized from %d to %d entries: %d sends waited %s, %d dropped or spilled, peak %d queued",
+			size, next, full, helpers.Duration(wait), newlyLost, peak)
+	}
+}
+
+// resizeQueue replaces the queue with one of n entries. Like
+// drainLogChannels, it keeps writing entries until every sender is out of
+// the way, then moves what's left over in order.
+func (l *Mylogger) resizeQueue(n int) {
+	locked := make(chan struct{})
+	go func() {
+		l.sendMu.Lock()
+		close(locked)
+	}()
+	for waiting := true; waiting; {
+		select {
+		case en := <-l.chans.entries:
+			l.writeEntry(en)
+		case <-locked:
+			waiting = false
+		}
+	}
+	defer l.sendMu.Unlock()
+	old := l.chans.entries
+	// write out what won't fit, so the rest keeps its place in the new queue.
+	for len(old) > n {
+		l.writeEntry(<-old)
+	}
+	next := make(chan entry, n)
+	for len(old) > 0 {
+		next <- <-old
+	}
+	l.chans.entries = next
+}
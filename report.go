@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// maxFingerprints bounds the distinct errors counted for the shutdown
+// report; errors of new shapes past it aren't counted.
+const maxFingerprints = 1000
+
+// fingerprints counts errors by helpers.Fingerprint.
+type fingerprints struct {
+	mu     sync.Mutex
+	counts map[string]*fingerprint
+}
+
+type fingerprint struct {
+	n      int
+	sample string // the first message of this shape
+}
+
+func (f *fingerprints) add(msg string) {
+	fp := helpers.Fingerprint(msg)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if c, ok := f.counts[fp]; ok {
+		c.n++
+		return
+	}
+	if f.counts == nil {
+		f.counts = map[string]*fingerprint{}
+	}
+	if len(f.counts) < maxFingerprints {
+		f.counts[fp] = &fingerprint{n: 1, sample: msg}
+	}
+}
+
+// top returns the n most frequent errors as "count× message".
+func (f *fingerprints) top(n int) []string {
+	f.mu.Lock()
+	list := make([]*fingerprint, 0, len(f.counts))
+	for _, c := range f.counts {
+		list = append(list, c)
+	}
+	f.mu.Unlock()
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].n != list[j].n {
+			return list[i].n > list[j].n
+		}
+		return list[i].sample < list[j].sample
+	})
+	var out []string
+	for _, c := range list[:min(n, len(list))] {
+		out = append(out, fmt.Sprintf("%d× %s", c.n, c.sample))
+	}
+	return out
+}
+
+// shutdownReport is the entry that sums up the run when the logger shuts
+// down: uptime, entries per level, what was lost, each output's bytes and
+// failed writes, and the most frequent errors.
+func (l *Mylogger) shutdownReport() entry {
+	uptime := time.Since(l.start)
+	var total int64
+	var levels []Attr
+	for _, e := range []Level{DEBUG, INFO, WARNING, ERROR, CRITICAL} {
+		n := l.stats.entries[e].Load()
+		total += n
+		levels = append(levels, Field(e.name(), n))
+	}
+	var outputs []Attr
+	seen := map[string]bool{}
+	for _, s := range l.sinks {
+		for _, w := range []*swapWriter{s.w, s.errw.Load()} {
+			if w == nil {
+				continue
+			}
+			name := w.name()
+			for i := 2; seen[name]; i++ {
+				name = w.name() + "#" + strconv.Itoa(i)
+			}
+			seen[name] = true
+			outputs = append(outputs, Field(name, []Attr{Field("bytes", w.own.Load()), Field("failed_writes", w.failures.Load())}))
+		}
+	}
+	attrs := []Attr{
+		Field("run_id", l.runID),
+		Field("uptime", uptime.Round(time.Millisecond)),
+		Field("entries", levels),
+		Field("dropped", l.stats.dropped.Load()),
+		Field("filtered", l.stats.filtered.Load()),
+	}
+	if l.spill != nil {
+		attrs = append(attrs, Field("spilled", l.spill.count.Load()))
+	}
+	attrs = append(attrs, Field("logger_errors", l.stats.errors.Load()), Field("outputs", outputs))
+	if ops := l.ops.attrs(); len(ops) > 0 {
+		attrs = append(attrs, Field("operations", ops))
+	}
+	if top := l.fingerprints.top(5); len(top) > 0 {
+		attrs = append(attrs, Field("top_errors", strings.Join(top, "; ")))
+	}
+	return entry{
+		level:  INFO,
+		value:  "Server run %s ran for %s, wrote %s entries, %s of logs",
+		args:   []any{l.runID, helpers.Duration(uptime), helpers.Count(total), helpers.Bytes(l.out.Written())},
+		format: true,
+		attrs:  attrs,
+	}
+}
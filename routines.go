@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// routines tracks the goroutines started with Mylogger.Go, for the shutdown watchdog.
+type routines struct {
+	mu       sync.Mutex
+	next     int
+	running  map[int]string
+	watchdog time.Duration // 0 disables the watchdog
+}
+
+// Go runs fn in a new goroutine tracked by the logger's WaitGroup, like
+// AddToWaitGroup and Done, and remembers it by name so a hanging shutdown can
+// report which routines never finished. fn should return once the Stopping
+// channel is closed.
+func (l *Mylogger) Go(name string, fn func()) {
+	l.routines.mu.Lock()
+	if l.routines.running == nil {
+		l.routines.running = map[int]string{}
+	}
+	id := l.routines.next
+	l.routines.next++
+	l.routines.running[id] = name
+	l.routines.mu.Unlock()
+	l.AddToWaitGroup()
+	go func() {
+		defer l.Done()
+		defer func() {
+			l.routines.mu.Lock()
+			delete(l.routines.running, id)
+			l.routines.mu.Unlock()
+		}()
+		fn()
+	}()
+}
+
+// SetShutdownWatchdog makes Shutdown report a hang: if the tracked routines
+// haven't all finished after d, the names of those started with Go that are
+// still running and the stacks of all goroutines are logged at WARNING.
+// Shutdown keeps waiting afterwards. 0 disables the watchdog.
+func (l *Mylogger) SetShutdownWatchdog(d time.Duration) {
+	l.routines.mu.Lock()
+	defer l.routines.mu.Unlock()
+	l.routines.watchdog = d
+}
+
+// waitRoutines waits for the WaitGroup, running the watchdog if it's enabled.
+func (l *Mylogger) waitRoutines() {
+	l.routines.mu.Lock()
+	d := l.routines.watchdog
+	l.routines.mu.Unlock()
+	if d <= 0 {
+		l.wg.Wait()
+		return
+	}
+	finished := make(chan struct{})
+	go func() {
+		l.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(d):
+		l.writef(WARNING, "%s", l.hangReport(d))
+		<-finished
+	}
+}
+
+// hangReport describes what shutdown is still waiting for.
+func (l *Mylogger) hangReport(d time.Duration) string {
+	l.routines.mu.Lock()
+	names := make([]string, 0, len(l.routines.running))
+	for _, n := range l.routines.running {
+		names = append(names, n)
+	}
+	l.routines.mu.Unlock()
+	sort.Strings(names)
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "shutdown still waiting after %s", d)
+	if len(names) > 0 {
+		fmt.Fprintf(&sb, ", unfinished routines: %s", strings.Join(names, ", "))
+	} else {
+		sb.WriteString(", no routines started with Go are running (check AddToWaitGroup/Done calls)")
+	}
+	fmt.Fprintf(&sb, "\ngoroutine stacks:\n%s", buf)
+	return sb.String()
+}
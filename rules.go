@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// rule changes the level of entries logged at from that match.
+type rule struct {
+	from, to Level
+	match    func(Record) bool
+}
+
+// rules holds the rules added in code and those from the config, each list
+// replaced as a whole on change so the mediator reads them without locking.
+type rules struct {
+	code   atomic.Pointer[[]rule]
+	config atomic.Pointer[[]rule] // replaced by every ApplyConfig
+}
+
+// RuleConfig is a re-leveling rule in a config file:
+//
+//	rules:
+//	  - match: "context deadline exceeded"
+//	    from: warning
+//	    to: error
+type RuleConfig struct {
+	Match string `json:"match" yaml:"match" toml:"match"`
+	From  string `json:"from" yaml:"from" toml:"from"`
+	To    string `json:"to" yaml:"to" toml:"to"`
+}
+
+// AddRule changes the level of entries logged at from that match, without
+// touching the call sites: promote "context deadline exceeded" warnings to
+// errors, or demote a noisy library's errors to debug:
+//
+//	l.AddRule(WARNING, "context deadline exceeded", ERROR)
+//	l.AddRule(ERROR, `^redis: connection pool timeout`, DEBUG)
+//
+// match is the same as for AddFilter. Rules run before filters and the
+// level check, so a demoted entry is dropped if its new level isn't logged.
+// Entries disabled at the call site never reach the rules, and CRITICAL
+// entries are left alone; promoting to CRITICAL doesn't exit.
+func (l *Mylogger) AddRule(from Level, match any, to Level) error {
+	r, err := newRule(from, match, to)
+	if err != nil {
+		return fmt.Errorf("add rule: %w", err)
+	}
+	for {
+		old := l.rules.code.Load()
+		var next []rule
+		if old != nil {
+			next = append(next, *old...)
+		}
+		next = append(next, r)
+		if l.rules.code.CompareAndSwap(old, &next) {
+			return nil
+		}
+	}
+}
+
+// ClearRules removes every rule added with AddRule.
+func (l *Mylogger) ClearRules() {
+	l.rules.code.Store(nil)
+}
+
+func newRule(from Level, match any, to Level) (rule, error) {
+	if from.severity() < 0 || from == CRITICAL {
+		return rule{}, fmt.Errorf("can't re-level entries logged at %v", from)
+	}
+	if to.severity() < 0 {
+		return rule{}, fmt.Errorf("unknown level %v", to)
+	}
+	fn, err := matcher(match)
+	if err != nil {
+		return rule{}, err
+	}
+	return rule{from: from, to: to, match: fn}, nil
+}
+
+// parseRules compiles the rules of a config.
+func parseRules(cs []RuleConfig) ([]rule, error) {
+	var out []rule
+	for i, c := range cs {
+		from, err := ParseLevel(c.From)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		to, err := ParseLevel(c.To)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		r, err := newRule(from, c.Match, to)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// apply returns the level of r after the first matching rule, code rules first.
+func (rs *rules) apply(l *Mylogger, r Record) Level {
+	for _, list := range []*[]rule{rs.code.Load(), rs.config.Load()} {
+		if list == nil {
+			continue
+		}
+		for _, ru := range *list {
+			if ru.from == r.Level && l.matches("rule", ru.match, r) {
+				return ru.to
+			}
+		}
+	}
+	return r.Level
+}
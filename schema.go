@@ -0,0 +1,56 @@
+package logger
+
+// EntrySchemaVersion is the version of the JSON entry format, written as
+// the "v" field of every JSON entry so parsers can tell what to expect.
+//
+//	1: time, level, msg, caller and the fields, before versioning.
+//	2: adds "v".
+//
+// Fields are only ever added within a major layout; a parser written for
+// version n reads n+1 by ignoring what it doesn't know.
+const EntrySchemaVersion = 2
+
+// EntryJSONSchema returns the JSON Schema (draft 2020-12) of the entries
+// written by JSONEncoder and DockerEncoder, for validating logs downstream.
+// Fields added with With are additional properties.
+func EntryJSONSchema() []byte {
+	return []byte(entryJSONSchema)
+}
+
+const entryJSONSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": "https://github.com/jeanhaley32/logger/entry.schema.json",
+  "title": "Log entry",
+  "description": "One line written by the logger's JSON and docker formats.",
+  "type": "object",
+  "required": ["time", "level", "msg"],
+  "properties": {
+    "v": {
+      "description": "Version of the entry format; absent in version 1.",
+      "type": "integer",
+      "minimum": 1,
+      "maximum": 2
+    },
+    "time": {
+      "type": "string",
+      "format": "date-time"
+    },
+    "stream": {
+      "description": "Output the entry was written to, docker format only.",
+      "enum": ["stdout", "stderr"]
+    },
+    "level": {
+      "enum": ["debug", "info", "warning", "error", "critical"]
+    },
+    "msg": {
+      "type": "string"
+    },
+    "caller": {
+      "description": "file.go:line of the call that logged the entry.",
+      "type": "string",
+      "pattern": "^\\S+\\.go:\\d+$"
+    }
+  },
+  "additionalProperties": true
+}
+`
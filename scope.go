@@ -0,0 +1,139 @@
+package logger
+
+// Attr is a key and value attached to entries, see Field and With.
+// A group is an Attr whose Value is a []Attr.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// Field returns an Attr, for With.
+func Field(key string, value any) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Scope is a logger that adds fields to every entry, made with With or
+// WithGroup. It writes through the logger it came from.
+// Example:
+// billing := l.With(Field("tenant", id)).WithGroup("billing")
+// billing.Info("invoice sent") // {"msg":"invoice sent","tenant":"t1","billing":{...}}
+type Scope struct {
+	l       *Mylogger
+	attrs   []Attr   // fields so far, with groups nested as []Attr values
+	groups  []string // open groups, innermost last; later fields go in them
+	capture *capture // holds entries back, see Capture
+	muted   bool     // entries are discarded, see Once and EveryN
+}
+
+// With returns a Scope that adds attrs to every entry.
+func (l *Mylogger) With(attrs ...Attr) *Scope {
+	return (&Scope{l: l}).With(attrs...)
+}
+
+// WithGroup returns a Scope whose fields are nested under name.
+func (l *Mylogger) WithGroup(name string) *Scope {
+	return (&Scope{l: l}).WithGroup(name)
+}
+
+// With returns a Scope with attrs added to the fields of s, inside its open
+// groups. s isn't changed.
+func (s *Scope) With(attrs ...Attr) *Scope {
+	if len(attrs) == 0 {
+		return s
+	}
+	return &Scope{l: s.l, attrs: addAttrs(s.attrs, s.groups, attrs), groups: s.groups, capture: s.capture, muted: s.muted}
+}
+
+// WithGroup returns a Scope whose later fields are nested under name.
+// An empty name returns s.
+func (s *Scope) WithGroup(name string) *Scope {
+	if name == "" {
+		return s
+	}
+	groups := append(s.groups[:len(s.groups):len(s.groups)], name)
+	return &Scope{l: s.l, attrs: s.attrs, groups: groups, capture: s.capture, muted: s.muted}
+}
+
+// addAttrs returns a copy of dst with attrs added in the group at path,
+// creating the groups that don't exist yet.
+func addAttrs(dst []Attr, path []string, attrs []Attr) []Attr {
+	out := make([]Attr, len(dst), len(dst)+len(attrs)+1)
+	copy(out, dst)
+	if len(path) == 0 {
+		return append(out, attrs...)
+	}
+	for i := len(out) - 1; i >= 0; i-- {
+		if g, ok := out[i].Value.([]Attr); ok && out[i].Key == path[0] {
+			out[i] = Attr{Key: path[0], Value: addAttrs(g, path[1:], attrs)}
+			return out
+		}
+	}
+	return append(out, Attr{Key: path[0], Value: addAttrs(nil, path[1:], attrs)})
+}
+
+// Log Critical Error and shutdown
+func (s *Scope) Critical(a any) {
+	s.capture.flush(s.l)
+	s.l.critical(entry{level: CRITICAL, value: a, attrs: s.attrs})
+}
+
+// Log Critical Error with a format, like fmt.Printf, and shutdown
+func (s *Scope) Criticalf(format string, args ...any) {
+	s.capture.flush(s.l)
+	s.l.critical(entry{level: CRITICAL, value: format, args: args, format: true, attrs: s.attrs})
+}
+
+// Log Error
+func (s *Scope) Error(a any) {
+	s.log(entry{level: ERROR, value: a})
+}
+
+// Log Error with a format, like fmt.Printf
+func (s *Scope) Errorf(format string, args ...any) {
+	s.log(entry{level: ERROR, value: format, args: args, format: true})
+}
+
+// Log Warning
+func (s *Scope) Warning(a any) {
+	s.log(entry{level: WARNING, value: a})
+}
+
+// Log Warning with a format, like fmt.Printf
+func (s *Scope) Warningf(format string, args ...any) {
+	s.log(entry{level: WARNING, value: format, args: args, format: true})
+}
+
+// Log Information
+func (s *Scope) Info(a any) {
+	s.log(entry{level: INFO, value: a})
+}
+
+// Log Information with a format, like fmt.Printf
+func (s *Scope) Infof(format string, args ...any) {
+	s.log(entry{level: INFO, value: format, args: args, format: true})
+}
+
+// Log Debug Message
+func (s *Scope) Debug(a any) {
+	s.log(entry{level: DEBUG, value: a})
+}
+
+// Log Debug Message with a format, like fmt.Printf
+func (s *Scope) Debugf(format string, args ...any) {
+	s.log(entry{level: DEBUG, value: format, args: args, format: true})
+}
+
+func (s *Scope) log(en entry) {
+	if s.muted {
+		return
+	}
+	if s.capture != nil {
+		en.attrs = s.attrs
+		s.capture.add(s.l, en)
+		return
+	}
+	if s.l.enabled(en.level) {
+		en.attrs = s.attrs
+		s.l.send(en)
+	}
+}
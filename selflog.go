@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+// errorHandler receives the logger's own problems, see SetErrorHandler.
+type errorHandler struct {
+	fn       atomic.Pointer[func(error)]
+	dropping atomic.Bool // the last try to queue an entry dropped it, see trySend
+}
+
+// SetErrorHandler sets the function told about the logger's own problems:
+// failed writes to an output, failing hooks, entries dropped because the
+// queue was full and the like, which can't be logged through the logger
+// itself. They go to stderr by default; nil restores that. fn is called from
+// the goroutine writing entries, or from the one logging for drops, so it
+// must not log through the logger, and should return quickly.
+func (l *Mylogger) SetErrorHandler(fn func(err error)) {
+	if fn == nil {
+		l.errors.fn.Store(nil)
+		return
+	}
+	l.errors.fn.Store(&fn)
+}
+
+// reportError counts err and hands it to the error handler.
+func (l *Mylogger) reportError(err error) {
+	l.stats.errors.Inc()
+	if fn := l.errors.fn.Load(); fn != nil {
+		(*fn)(err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "logger: %v\n", err)
+}
+
+// reportDrop reports that an entry was dropped, once until one gets in again.
+func (l *Mylogger) reportDrop() {
+	if !l.errors.dropping.Swap(true) {
+		l.reportError(errors.New("queue full: dropping entries"))
+	}
+}
+
+// queued notes that an entry got into the queue, ending a run of drops.
+func (l *Mylogger) queued() {
+	if l.errors.dropping.Load() {
+		l.errors.dropping.Store(false)
+	}
+}
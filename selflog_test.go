@@ -0,0 +1,184 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// errorLog collects the errors reported to an error handler.
+type errorLog struct {
+	mu   sync.Mutex
+	errs []string
+}
+
+func (e *errorLog) handle(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.errs = append(e.errs, err.Error())
+}
+
+func (e *errorLog) list() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.errs...)
+}
+
+// flakyWriter fails its writes while broken is set.
+type flakyWriter struct {
+	broken atomic.Bool
+	buf    bytes.Buffer
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	if w.broken.Load() {
+		return 0, errors.New("disk full")
+	}
+	return w.buf.Write(p)
+}
+
+func TestErrorHandler(t *testing.T) {
+	l, err := StartLogger(WithSyncMode(), WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Shutdown(nil)
+	var errs errorLog
+	l.SetErrorHandler(errs.handle)
+	l.AddHook(HookFunc(func(Record) error { return errors.New("audit down") }))
+	l.Info("one")
+	if got := errs.list(); len(got) != 1 || !strings.Contains(got[0], "audit down") {
+		t.Errorf("reported %q, want the hook's error", got)
+	}
+	if n := l.stats.errors.Load(); n != 1 {
+		t.Errorf("logger_errors_total = %d, want 1", n)
+	}
+
+	// nil restores stderr.
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	l.SetErrorHandler(nil)
+	l.Info("two")
+	os.Stderr = stderr
+	w.Close()
+	out, _ := io.ReadAll(r)
+	if !strings.HasPrefix(string(out), "logger: hook ") || !strings.Contains(string(out), "audit down") {
+		t.Errorf("stderr = %q, want the hook's error", out)
+	}
+	if len(errs.list()) != 1 {
+		t.Errorf("the removed handler was still called: %q", errs.list())
+	}
+	l.SetErrorHandler(errs.handle) // keeps the shutdown entries off stderr
+}
+
+func TestSinkWriteFailures(t *testing.T) {
+	var fw flakyWriter
+	l, err := StartLogger(WithSyncMode(), WithOutput(io.Discard), WithSink(&fw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Shutdown(nil)
+	var errs errorLog
+	l.SetErrorHandler(errs.handle)
+
+	fw.broken.Store(true)
+	l.Info("lost 1")
+	l.Info("lost 2")
+	if got := errs.list(); len(got) != 1 || got[0] != "write to output 1: disk full" {
+		t.Fatalf("reported %q, want one write failure", got)
+	}
+	if n := l.sinks[1].w.failures.Load(); n != 2 {
+		t.Errorf("%d failed writes counted, want 2", n)
+	}
+	fw.broken.Store(false)
+	l.Info("written")
+	fw.broken.Store(true)
+	l.Info("lost 3")
+	if got := errs.list(); len(got) != 2 {
+		t.Errorf("reported %q, want the failure again after the output recovered", got)
+	}
+	if s := fw.buf.String(); !strings.Contains(s, "written") || strings.Contains(s, "lost") {
+		t.Errorf("sink got %q", s)
+	}
+}
+
+func TestDroppedEntriesAreReported(t *testing.T) {
+	l, err := StartLogger(WithOutput(io.Discard), WithQueueSize(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var errs errorLog
+	l.SetErrorHandler(errs.handle)
+	// a hook holding the mediator keeps the queue full.
+	busy, release := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	l.AddHook(HookFunc(func(Record) error {
+		once.Do(func() {
+			close(busy)
+			<-release
+		})
+		return nil
+	}))
+	l.Info("holds the mediator")
+	<-busy
+	l.Info("fills the queue")
+	for i := 0; i < 3; i++ {
+		if l.TryInfo("dropped") {
+			t.Fatal("TryInfo got into a full queue")
+		}
+	}
+	close(release)
+	if !l.InfoWithTimeout("gets in", time.Minute) {
+		t.Fatal("InfoWithTimeout was dropped once the queue drained")
+	}
+	l.Shutdown(nil)
+
+	if got := errs.list(); len(got) != 1 || got[0] != "queue full: dropping entries" {
+		t.Errorf("reported %q, want one report of the drops", got)
+	}
+	if n := l.stats.dropped.Load(); n != 3 {
+		t.Errorf("logger_entries_dropped_total = %d, want 3", n)
+	}
+	if l.errors.dropping.Load() {
+		t.Error("still marked as dropping after an entry got in")
+	}
+}
+
+func TestPanickingHookIsDisabled(t *testing.T) {
+	var out bytes.Buffer
+	l, err := StartLogger(WithSyncMode(), WithOutput(&out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Shutdown(nil)
+	var errs errorLog
+	l.SetErrorHandler(errs.handle)
+	var calls atomic.Int32
+	l.AddHook(HookFunc(func(Record) error {
+		calls.Add(1)
+		panic("nil map")
+	}))
+	for i := 0; i < maxHookPanics+2; i++ {
+		l.Info("entry")
+	}
+	if n := strings.Count(out.String(), "entry"); n != maxHookPanics+2 {
+		t.Errorf("%d entries written, want %d", n, maxHookPanics+2)
+	}
+	if n := calls.Load(); n != maxHookPanics {
+		t.Errorf("hook called %d times, want %d before it's disabled", n, maxHookPanics)
+	}
+	got := errs.list()
+	if len(got) != maxHookPanics || !strings.Contains(got[len(got)-1], "disabled after 3 panics") || !strings.Contains(got[0], "nil map") {
+		t.Errorf("reported %q", got)
+	}
+}
@@ -0,0 +1,88 @@
+// Package service runs a program under a service manager: systemd with
+// Type=notify, or the Windows service control manager. Either way the
+// manager's lifecycle goes through the logger: readiness is reported when
+// the program says so, stop requests become a graceful Shutdown, and the
+// systemd watchdog is fed only while the logger is healthy.
+//
+//	func main() {
+//		l, _ := logger.StartLogger()
+//		err := service.Run(l, "myapp", func(ready func()) error {
+//			srv := start()
+//			ready()
+//			<-l.Stopping()
+//			return srv.Close()
+//		})
+//		...
+//	}
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jeanhaley32/logger"
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// Run runs main as the service name, and returns what main returns. main
+// calls ready once it's serving, and should return once l.Stopping() is
+// closed. Outside a Windows service Run speaks systemd's notify protocol,
+// which does nothing if systemd didn't ask for it, so the same binary runs
+// from a terminal too.
+func Run(l *logger.Mylogger, name string, main func(ready func()) error) error {
+	if isWindowsService() {
+		return runWindows(l, name, main)
+	}
+	return runSystemd(l, main)
+}
+
+// runSystemd reports READY=1 when main is ready and STOPPING=1 when the
+// logger starts shutting down, and feeds the watchdog if it's enabled.
+func runSystemd(l *logger.Mylogger, main func(ready func()) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-l.Stopping():
+			notify(l, "STOPPING=1")
+		case <-ctx.Done():
+		}
+	}()
+	if every := WatchdogInterval(); every > 0 {
+		go watchdog(ctx, l, every)
+	}
+	var once sync.Once
+	return main(func() {
+		once.Do(func() {
+			notify(l, "READY=1")
+			l.Info("service: ready")
+		})
+	})
+}
+
+// watchdog sends WATCHDOG=1 twice per interval while the logger is ready:
+// a logger whose outputs fail or whose queue stays backed up stops the
+// pings, and systemd restarts the program.
+func watchdog(ctx context.Context, l *logger.Mylogger, every time.Duration) {
+	h := l.Health()
+	ok := true
+	helpers.TickFunc(ctx, every/2, func(time.Time) {
+		problems := h.Ready()
+		if len(problems) > 0 {
+			if ok {
+				l.Warningf("service: not feeding the watchdog: %v", problems)
+			}
+			ok = false
+			return
+		}
+		ok = true
+		notify(l, "WATCHDOG=1")
+	})
+}
+
+func notify(l *logger.Mylogger, state string) {
+	if err := Notify(state); err != nil {
+		l.Warning(err)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package service
+
+import (
+	"errors"
+
+	"github.com/jeanhaley32/logger"
+)
+
+func isWindowsService() bool {
+	return false
+}
+
+func runWindows(*logger.Mylogger, string, func(func()) error) error {
+	return errors.ErrUnsupported
+}
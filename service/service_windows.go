@@ -0,0 +1,77 @@
+//go:build windows
+
+package service
+
+import (
+	"sync"
+
+	"github.com/jeanhaley32/logger"
+	"golang.org/x/sys/windows/svc"
+)
+
+func isWindowsService() bool {
+	ok, err := svc.IsWindowsService()
+	return err == nil && ok
+}
+
+// runWindows runs main as a Windows service, turning stop and shutdown
+// requests into a graceful shutdown of the logger.
+func runWindows(l *logger.Mylogger, name string, main func(ready func()) error) error {
+	h := &handler{l: l, main: main}
+	if err := svc.Run(name, h); err != nil {
+		return err
+	}
+	return h.err
+}
+
+type handler struct {
+	l    *logger.Mylogger
+	main func(ready func()) error
+	err  error // what main returned
+}
+
+func (h *handler) Execute(_ []string, reqs <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	const accepts = svc.AcceptStop | svc.AcceptShutdown
+	status <- svc.Status{State: svc.StartPending}
+	done := make(chan error, 1)
+	var once sync.Once
+	go func() {
+		done <- h.main(func() {
+			once.Do(func() {
+				status <- svc.Status{State: svc.Running, Accepts: accepts}
+				h.l.Info("service: running")
+			})
+		})
+	}()
+	var stopped chan struct{}
+	for {
+		select {
+		case err := <-done:
+			// let a shutdown we started write its last entries.
+			if stopped != nil {
+				<-stopped
+			}
+			h.err = err
+			if err != nil {
+				return false, 1
+			}
+			return false, 0
+		case req := <-reqs:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				if stopped != nil {
+					continue
+				}
+				status <- svc.Status{State: svc.StopPending}
+				h.l.Infof("service: %s requested", map[svc.Cmd]string{svc.Stop: "stop", svc.Shutdown: "shutdown"}[req.Cmd])
+				stopped = make(chan struct{})
+				go func() {
+					defer close(stopped)
+					h.l.Shutdown(nil)
+				}()
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Package bus publishes entries to a message bus, NATS or MQTT, for
+// deployments that already aggregate over one rather than over HTTP:
+//
+//	s, err := bus.DialNATS("nats://edge-broker:4222", "logs.{level}")
+//	if err != nil { ... }
+//	l.AddHook(s)
+//	defer s.Close()
+//
+// Entries are encoded with the logger's JSON encoder unless another is set.
+package bus
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/jeanhaley32/logger"
+)
+
+// publisher holds what the NATS and MQTT sinks share.
+type publisher struct {
+	MinLevel logger.Level   // least severe level published, INFO by default
+	Encoder  logger.Encoder // logger.JSONEncoder{} if nil
+}
+
+// encode returns r encoded for publishing, or nil if it's below MinLevel.
+func (p *publisher) encode(r logger.Record) []byte {
+	if r.Level < p.MinLevel {
+		return nil
+	}
+	enc := p.Encoder
+	if enc == nil {
+		enc = logger.JSONEncoder{}
+	}
+	var buf bytes.Buffer
+	enc.Encode(&buf, r, false)
+	return bytes.TrimRight(buf.Bytes(), "\n")
+}
+
+// subject expands "{level}" in pattern to the lowercase level name, so
+// consumers can subscribe to the levels they want.
+func subject(pattern string, lv logger.Level) string {
+	if !strings.Contains(pattern, "{level}") {
+		return pattern
+	}
+	return strings.ReplaceAll(pattern, "{level}", strings.ToLower(lv.String()))
+}
@@ -0,0 +1,78 @@
+package bus
+
+import (
+	"fmt"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/jeanhaley32/logger"
+)
+
+// MQTT publishes entries to an MQTT topic.
+type MQTT struct {
+	publisher
+	Client   mqtt.Client
+	Topic    string // may contain "{level}"
+	QoS      byte   // 0 at most once, 1 at least once, 2 exactly once
+	Retained bool
+
+	owned bool // Client was connected by DialMQTT and is disconnected by Close
+}
+
+// NewMQTT returns a sink publishing on a connected client, which stays the
+// caller's to disconnect.
+func NewMQTT(c mqtt.Client, topic string, qos byte) *MQTT {
+	return &MQTT{publisher: publisher{MinLevel: logger.INFO}, Client: c, Topic: topic, QoS: qos}
+}
+
+// DialMQTT connects to broker, e.g. "tcp://broker:1883", as clientID and
+// returns a sink publishing to topic, reconnecting if the connection drops.
+func DialMQTT(broker, clientID, topic string, qos byte) (*MQTT, error) {
+	if qos > 2 {
+		return nil, fmt.Errorf("mqtt: qos %d is not 0, 1 or 2", qos)
+	}
+	opts := mqtt.NewClientOptions().
+		AddBroker(broker).
+		SetClientID(clientID).
+		SetAutoReconnect(true).
+		SetConnectRetry(true)
+	c := mqtt.NewClient(opts)
+	if t := c.Connect(); !t.WaitTimeout(10*time.Second) || t.Error() != nil {
+		c.Disconnect(0)
+		if t.Error() != nil {
+			return nil, fmt.Errorf("mqtt: %w", t.Error())
+		}
+		return nil, fmt.Errorf("mqtt: connecting to %s timed out", broker)
+	}
+	m := NewMQTT(c, topic, qos)
+	m.owned = true
+	return m, nil
+}
+
+// Fire publishes r without waiting for the broker, except for CRITICAL
+// entries, which are waited for since the process exits right after.
+func (m *MQTT) Fire(r logger.Record) error {
+	b := m.encode(r)
+	if b == nil {
+		return nil
+	}
+	t := m.Client.Publish(subject(m.Topic, r.Level), m.QoS, m.Retained, b)
+	if r.Level == logger.CRITICAL {
+		if !t.WaitTimeout(5 * time.Second) {
+			return fmt.Errorf("mqtt: publishing to %s timed out", m.Topic)
+		}
+		if err := t.Error(); err != nil {
+			return fmt.Errorf("mqtt: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close disconnects the client if DialMQTT connected it, giving in-flight
+// messages a second to go out.
+func (m *MQTT) Close() error {
+	if m.owned {
+		m.Client.Disconnect(1000)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package bus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jeanhaley32/logger"
+	"github.com/nats-io/nats.go"
+)
+
+// NATS publishes entries to a NATS subject.
+type NATS struct {
+	publisher
+	Conn    *nats.Conn
+	Subject string // may contain "{level}"
+
+	owned bool // Conn was dialed by DialNATS and is closed by Close
+}
+
+// NewNATS returns a sink publishing on nc, which stays the caller's to close.
+func NewNATS(nc *nats.Conn, subject string) *NATS {
+	return &NATS{publisher: publisher{MinLevel: logger.INFO}, Conn: nc, Subject: subject}
+}
+
+// DialNATS connects to the NATS server at url and returns a sink publishing
+// to subject, reconnecting forever if the connection drops.
+func DialNATS(url, subject string, opts ...nats.Option) (*NATS, error) {
+	opts = append([]nats.Option{nats.Name("logger"), nats.MaxReconnects(-1)}, opts...)
+	nc, err := nats.Connect(url, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("nats: %w", err)
+	}
+	n := NewNATS(nc, subject)
+	n.owned = true
+	return n, nil
+}
+
+// Fire publishes r. Publishing only buffers the message, the client sends
+// it in the background; CRITICAL entries are flushed before returning since
+// the process exits right after.
+func (n *NATS) Fire(r logger.Record) error {
+	b := n.encode(r)
+	if b == nil {
+		return nil
+	}
+	if err := n.Conn.Publish(subject(n.Subject, r.Level), b); err != nil {
+		return fmt.Errorf("nats: %w", err)
+	}
+	if r.Level == logger.CRITICAL {
+		return n.Conn.FlushTimeout(5 * time.Second)
+	}
+	return nil
+}
+
+// Close flushes what's buffered, and closes the connection if DialNATS
+// opened it.
+func (n *NATS) Close() error {
+	if n.owned {
+		return n.Conn.Drain()
+	}
+	return n.Conn.FlushTimeout(5 * time.Second)
+}
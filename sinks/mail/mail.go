@@ -0,0 +1,270 @@
+// Package mail sends CRITICAL entries and alerts by email, and optionally a
+// digest of the errors logged over an interval.
+//
+//	m := mail.New("smtp.example.com:587", "app@example.com", "oncall@example.com")
+//	m.Auth = smtp.PlainAuth("", user, pass, "smtp.example.com")
+//	m.Digest = 15 * time.Minute
+//	l.AddHook(m)
+//	l.AddAlertSink(m)
+//	defer m.Close()
+package mail
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/jeanhaley32/logger"
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// DefaultSubject and DefaultBody are the templates used when Mail's are nil.
+var (
+	DefaultSubject = template.Must(template.New("subject").Parse(`[{{.Host}}] {{.Title}}`))
+	DefaultBody    = template.Must(template.New("body").Funcs(Funcs).Parse(`{{.Title}}
+{{with .Alert}}
+Rule:   {{.Rule}}
+Count:  {{.Count}} in {{.Window}}
+{{end}}{{with .Entries}}
+{{range .}}{{entry .}}
+{{end}}{{end}}{{with .Recent}}
+Recent entries:
+{{range .}}{{entry .}}
+{{end}}{{end}}`))
+)
+
+// Funcs are the functions available to the templates: entry formats a
+// record as a text log line.
+var Funcs = template.FuncMap{
+	"entry": func(r logger.Record) string {
+		var buf bytes.Buffer
+		logger.TextEncoder{}.Encode(&buf, r, false)
+		return strings.TrimRight(buf.String(), "\n")
+	},
+}
+
+// Data is what the subject and body templates are executed with.
+type Data struct {
+	Host    string
+	Time    time.Time
+	Title   string          // one line summary
+	Alert   *logger.Alert   // the alert, for alert emails
+	Entries []logger.Record // the CRITICAL entry, or the entries of a digest
+	Recent  []logger.Record // the entries logged just before, oldest first
+}
+
+// Mail is an SMTP sink. CRITICAL entries and alerts are sent as soon as
+// they're logged; with Digest set, entries at DigestLevel or above are also
+// collected and sent as one email per interval.
+type Mail struct {
+	Addr        string // host:port of the SMTP server
+	From        string
+	To          []string
+	Auth        smtp.Auth   // nil to send without authenticating
+	TLS         *tls.Config // used for STARTTLS or ImplicitTLS; verifies the server name if nil
+	ImplicitTLS bool        // connect with TLS, as on port 465, instead of STARTTLS
+	Timeout     time.Duration
+
+	Subject *template.Template // DefaultSubject if nil
+	Body    *template.Template // DefaultBody if nil
+
+	Digest      time.Duration // interval of the digest; zero disables it
+	DigestLevel logger.Level  // least severe level in the digest, ERROR by default
+	Recent      int           // entries of any level included as context
+
+	mu     sync.Mutex
+	recent []logger.Record // ring of the last Recent entries
+	next   int
+	digest *helpers.Batcher[logger.Record]
+}
+
+// New returns a sink sending from from to the given addresses through the
+// SMTP server at addr, with 20 recent entries as context.
+func New(addr, from string, to ...string) *Mail {
+	return &Mail{
+		Addr:        addr,
+		From:        from,
+		To:          to,
+		Timeout:     30 * time.Second,
+		DigestLevel: logger.ERROR,
+		Recent:      20,
+	}
+}
+
+// Fire sends CRITICAL entries, adds entries to the digest and keeps the
+// recent entries. CRITICAL entries are sent before returning, since the
+// process exits right after. Alert entries are left to SendAlert.
+func (m *Mail) Fire(r logger.Record) error {
+	recent := m.remember(r)
+	for _, a := range r.Attrs {
+		if a.Key == "alert" {
+			return nil
+		}
+	}
+	if r.Level == logger.CRITICAL {
+		return m.send(Data{Title: "CRITICAL: " + firstLine(r.Message), Entries: []logger.Record{r}, Recent: recent})
+	}
+	if m.Digest > 0 && r.Level >= m.DigestLevel {
+		m.batcher().Add(r)
+	}
+	return nil
+}
+
+// SendAlert emails an alert with the entries logged before it.
+func (m *Mail) SendAlert(a logger.Alert) error {
+	m.mu.Lock()
+	recent := m.ring()
+	m.mu.Unlock()
+	return m.send(Data{Title: a.Message, Alert: &a, Recent: recent})
+}
+
+// Close sends the pending digest.
+func (m *Mail) Close() error {
+	m.mu.Lock()
+	b := m.digest
+	m.mu.Unlock()
+	if b != nil {
+		b.Close()
+	}
+	return nil
+}
+
+// batcher starts the digest on first use, so Digest can be set after New.
+func (m *Mail) batcher() *helpers.Batcher[logger.Record] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.digest == nil {
+		m.digest = helpers.NewBatcher(1000, m.Digest, func(rs []logger.Record) error {
+			title := fmt.Sprintf("%d entries at %s or above", len(rs), m.DigestLevel)
+			return m.send(Data{Title: title, Entries: rs})
+		})
+	}
+	return m.digest
+}
+
+// remember adds r to the ring of recent entries and returns the entries
+// before it.
+func (m *Mail) remember(r logger.Record) []logger.Record {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Recent <= 0 {
+		return nil
+	}
+	prev := m.ring()
+	if len(m.recent) < m.Recent {
+		m.recent = append(m.recent, r)
+	} else {
+		m.recent[m.next] = r
+		m.next = (m.next + 1) % len(m.recent)
+	}
+	return prev
+}
+
+// ring returns a copy of the recent entries, oldest first.
+func (m *Mail) ring() []logger.Record {
+	out := make([]logger.Record, 0, len(m.recent))
+	out = append(out, m.recent[m.next:]...)
+	return append(out, m.recent[:m.next]...)
+}
+
+// send renders d and delivers it.
+func (m *Mail) send(d Data) error {
+	d.Host, _ = os.Hostname()
+	d.Time = time.Now()
+	subject, body := m.Subject, m.Body
+	if subject == nil {
+		subject = DefaultSubject
+	}
+	if body == nil {
+		body = DefaultBody
+	}
+	var s, b bytes.Buffer
+	if err := subject.Execute(&s, d); err != nil {
+		return fmt.Errorf("mail: subject: %w", err)
+	}
+	if err := body.Execute(&b, d); err != nil {
+		return fmt.Errorf("mail: body: %w", err)
+	}
+	if err := m.deliver(firstLine(s.String()), b.Bytes()); err != nil {
+		return fmt.Errorf("mail: %w", err)
+	}
+	return nil
+}
+
+// deliver sends one message over a new SMTP connection.
+func (m *Mail) deliver(subject string, body []byte) error {
+	host, _, err := net.SplitHostPort(m.Addr)
+	if err != nil {
+		return err
+	}
+	cfg := m.TLS
+	if cfg == nil {
+		cfg = &tls.Config{ServerName: host}
+	}
+	timeout := m.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	if m.ImplicitTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", m.Addr, cfg)
+	} else {
+		conn, err = dialer.Dial("tcp", m.Addr)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok && !m.ImplicitTLS {
+		if err := c.StartTLS(cfg); err != nil {
+			return err
+		}
+	}
+	if m.Auth != nil {
+		if err := c.Auth(m.Auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(m.From); err != nil {
+		return err
+	}
+	for _, to := range m.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n",
+		m.From, strings.Join(m.To, ", "), mime.QEncoding.Encode("utf-8", subject), time.Now().Format(time.RFC1123Z))
+	w.Write(body)
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// firstLine returns s up to its first newline.
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
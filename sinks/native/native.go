@@ -0,0 +1,78 @@
+// Package native writes entries to the platform's system log, so services
+// packaged for Windows or macOS show up in Event Viewer or Console with the
+// right severity:
+//
+//	s, err := native.New("myservice")
+//	if err != nil { ... } // errors.Is(err, errors.ErrUnsupported) elsewhere
+//	l.AddHook(s)
+//	defer s.Close()
+//
+// On Windows entries go to the Application event log under the given
+// source, which Install registers (once, as administrator, typically from
+// the installer). On macOS they go to the unified log (os_log) with the name
+// as subsystem; this needs cgo.
+package native
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jeanhaley32/logger"
+)
+
+// Sink is a hook writing entries at MinLevel or above to the system log.
+type Sink struct {
+	MinLevel logger.Level // least severe level written, INFO by default
+	sys      *system
+}
+
+// New opens the system log for the named service.
+func New(name string) (*Sink, error) {
+	sys, err := open(name)
+	if err != nil {
+		return nil, fmt.Errorf("native log: %w", err)
+	}
+	return &Sink{MinLevel: logger.INFO, sys: sys}, nil
+}
+
+// Install registers name as an event source, which Windows needs before it
+// can show the entries' text. It does nothing on other platforms.
+func Install(name string) error {
+	return install(name)
+}
+
+// Fire writes r with the system log's closest severity.
+func (s *Sink) Fire(r logger.Record) error {
+	if r.Level < s.MinLevel {
+		return nil
+	}
+	return s.sys.write(r.Level, message(r))
+}
+
+// Close closes the system log.
+func (s *Sink) Close() error {
+	return s.sys.close()
+}
+
+// message is the entry's text as the system log shows it: the system adds
+// the time and severity, so it's the message, the fields as key=value, and
+// the caller.
+func message(r logger.Record) string {
+	var b strings.Builder
+	b.WriteString(r.Message)
+	writeAttrs(&b, "", r.Attrs)
+	if r.Caller != "" {
+		fmt.Fprintf(&b, " (%s)", r.Caller)
+	}
+	return b.String()
+}
+
+func writeAttrs(b *strings.Builder, prefix string, attrs []logger.Attr) {
+	for _, a := range attrs {
+		if g, ok := a.Value.([]logger.Attr); ok {
+			writeAttrs(b, prefix+a.Key+".", g)
+			continue
+		}
+		fmt.Fprintf(b, " %s%s=%v", prefix, a.Key, a.Value)
+	}
+}
@@ -0,0 +1,59 @@
+//go:build darwin && cgo
+
+package native
+
+/*
+#include <os/log.h>
+#include <stdlib.h>
+
+static void logWithType(os_log_t log, os_log_type_t type, const char *msg) {
+	os_log_with_type(log, type, "%{public}s", msg);
+}
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/jeanhaley32/logger"
+)
+
+type system struct {
+	log C.os_log_t
+}
+
+func open(name string) (*system, error) {
+	sub, cat := C.CString(name), C.CString("default")
+	defer C.free(unsafe.Pointer(sub))
+	defer C.free(unsafe.Pointer(cat))
+	return &system{log: C.os_log_create(sub, cat)}, nil
+}
+
+func install(string) error {
+	return nil
+}
+
+// write logs msg with the os_log type of its level: debug, info, default
+// for warnings, error, and fault for CRITICAL.
+func (s *system) write(lv logger.Level, msg string) error {
+	t := C.os_log_type_t(C.OS_LOG_TYPE_DEFAULT)
+	switch lv {
+	case logger.DEBUG:
+		t = C.OS_LOG_TYPE_DEBUG
+	case logger.INFO:
+		t = C.OS_LOG_TYPE_INFO
+	case logger.ERROR:
+		t = C.OS_LOG_TYPE_ERROR
+	case logger.CRITICAL:
+		t = C.OS_LOG_TYPE_FAULT
+	}
+	cmsg := C.CString(msg)
+	defer C.free(unsafe.Pointer(cmsg))
+	C.logWithType(s.log, t, cmsg)
+	return nil
+}
+
+// close does nothing: os_log handles live for the process.
+func (s *system) close() error {
+	return nil
+}
@@ -0,0 +1,27 @@
+//go:build !windows && !(darwin && cgo)
+
+package native
+
+import (
+	"errors"
+
+	"github.com/jeanhaley32/logger"
+)
+
+type system struct{}
+
+func open(string) (*system, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func install(string) error {
+	return nil
+}
+
+func (*system) write(logger.Level, string) error {
+	return errors.ErrUnsupported
+}
+
+func (*system) close() error {
+	return nil
+}
@@ -0,0 +1,46 @@
+//go:build windows
+
+package native
+
+import (
+	"github.com/jeanhaley32/logger"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+type system struct {
+	log *eventlog.Log
+}
+
+func open(name string) (*system, error) {
+	l, err := eventlog.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &system{log: l}, nil
+}
+
+func install(name string) error {
+	err := eventlog.InstallAsEventCreate(name, eventlog.Error|eventlog.Warning|eventlog.Info)
+	// eventlog only tells an existing source apart by its message.
+	if err != nil && err.Error() == name+" registry key already exists" {
+		return nil
+	}
+	return err
+}
+
+// write logs msg as an error, warning or information event, with the level
+// as the event ID (1 for DEBUG to 5 for CRITICAL) so they can be filtered.
+func (s *system) write(lv logger.Level, msg string) error {
+	id := uint32(lv) + 1
+	switch lv {
+	case logger.CRITICAL, logger.ERROR:
+		return s.log.Error(id, msg)
+	case logger.WARNING:
+		return s.log.Warning(id, msg)
+	}
+	return s.log.Info(id, msg)
+}
+
+func (s *system) close() error {
+	return s.log.Close()
+}
@@ -0,0 +1,191 @@
+// Package pager turns CRITICAL entries and alerts into incidents on
+// PagerDuty or Opsgenie, and resolves them when a recovery is logged.
+//
+// Both sinks are logger hooks and alert sinks:
+//
+//	pd := pager.NewPagerDuty(routingKey)
+//	l.AddHook(pd)
+//	l.AddAlertSink(pd)
+//	...
+//	l.With(logger.Field("incident", "db")).Info("db recovered") // resolves the "db" incident
+package pager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/jeanhaley32/logger"
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// DefaultRecovered matches entries that report a recovery.
+var DefaultRecovered = regexp.MustCompile(`(?i)\b(recovered|resolved)\b`)
+
+// incident is a triggered incident that hasn't been resolved.
+type incident struct {
+	key  string
+	name string // incident field or alert rule name, matched against recoveries
+}
+
+// tracker decides which entries trigger and resolve incidents, and keeps the
+// incidents opened by this process.
+type tracker struct {
+	mu   sync.Mutex
+	open map[string]incident
+	wg   sync.WaitGroup // sends in flight
+}
+
+// incidentKey returns the dedup key and name for a record: the "incident"
+// field if there is one, otherwise a hash of the message's fingerprint, so
+// repeats of the same error share an incident.
+func incidentKey(r logger.Record) (key, name string) {
+	if v, ok := attr(r.Attrs, "incident"); ok {
+		name = fmt.Sprint(v)
+		return name, name
+	}
+	sum := sha256.Sum256([]byte(helpers.Fingerprint(r.Message)))
+	return "fp-" + hex.EncodeToString(sum[:8]), ""
+}
+
+// alertKey returns the dedup key for alerts raised by rule.
+func alertKey(rule string) string {
+	return "alert:" + rule
+}
+
+// opened records an incident as open.
+func (t *tracker) opened(key, name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.open == nil {
+		t.open = map[string]incident{}
+	}
+	t.open[key] = incident{key: key, name: name}
+}
+
+// recovered returns the keys of the incidents r resolves, and forgets them.
+// A record with an "incident" field resolves that incident, even one opened
+// before a restart; otherwise every open incident whose name appears in the
+// message as a word is resolved.
+func (t *tracker) recovered(r logger.Record) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if v, ok := attr(r.Attrs, "incident"); ok {
+		key := fmt.Sprint(v)
+		delete(t.open, key)
+		return []string{key}
+	}
+	var keys []string
+	for key, in := range t.open {
+		if in.name != "" && mentions(r.Message, in.name) {
+			keys = append(keys, key)
+			delete(t.open, key)
+		}
+	}
+	return keys
+}
+
+// mentions reports whether msg contains name as a whole word, so "db"
+// isn't resolved by "dbproxy recovered".
+func mentions(msg, name string) bool {
+	for i := 0; ; {
+		j := strings.Index(msg[i:], name)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(name)
+		if (start == 0 || !isWord(msg[start-1])) && (end == len(msg) || !isWord(msg[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func isWord(c byte) bool {
+	return c == '_' || c == '-' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// async runs send on its own goroutine, tracked by Flush, reporting errors
+// on stderr since there's no one else to tell.
+func (t *tracker) async(send func() error) {
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		if err := helpers.Safe(send); err != nil {
+			fmt.Fprintf(os.Stderr, "pager: %v\n", err)
+		}
+	}()
+}
+
+// attr returns the value of the top-level attribute key.
+func attr(attrs []logger.Attr, key string) (any, bool) {
+	for _, a := range attrs {
+		if a.Key == key {
+			return a.Value, true
+		}
+	}
+	return nil, false
+}
+
+// details flattens attrs into a map for an incident's custom details.
+func details(r logger.Record) map[string]any {
+	m := attrMap(r.Attrs)
+	if r.Caller != "" {
+		m["caller"] = r.Caller
+	}
+	return m
+}
+
+func attrMap(attrs []logger.Attr) map[string]any {
+	m := make(map[string]any, len(attrs))
+	for _, a := range attrs {
+		switch v := a.Value.(type) {
+		case []logger.Attr:
+			m[a.Key] = attrMap(v)
+		case error:
+			m[a.Key] = v.Error()
+		case fmt.Stringer:
+			m[a.Key] = v.String()
+		default:
+			m[a.Key] = v
+		}
+	}
+	return m
+}
+
+// truncate shortens s to n bytes, as the APIs reject longer fields.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+// newClient returns the HTTP client the sinks use. It doesn't log, since its
+// entries would come back through the hook.
+func newClient() *helpers.HTTPClient {
+	c := helpers.NewHTTPClient(helpers.Discard)
+	c.CaptureBody = 512
+	return c
+}
+
+// client returns c, or a new client if c is nil.
+func client(c *helpers.HTTPClient) *helpers.HTTPClient {
+	if c == nil {
+		return newClient()
+	}
+	return c
+}
+
+// hostname is the default source of events.
+func hostname() string {
+	h, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return h
+}
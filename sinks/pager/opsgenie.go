@@ -0,0 +1,145 @@
+package pager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/jeanhaley32/logger"
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// OpsgenieURL is the Opsgenie API; use https://api.eu.opsgenie.com for the
+// EU instance.
+const OpsgenieURL = "https://api.opsgenie.com"
+
+// Opsgenie sends incidents to the Opsgenie Alert API, using the dedup key
+// as the alert alias.
+type Opsgenie struct {
+	APIKey    string
+	URL       string              // OpsgenieURL if empty
+	Source    string              // the alert source, the hostname by default
+	MinLevel  logger.Level        // least severe level that triggers, CRITICAL by default
+	Recovered *regexp.Regexp      // entries that close alerts, DefaultRecovered by default
+	Client    *helpers.HTTPClient // a quiet client with retries if nil
+
+	tracker
+}
+
+// NewOpsgenie returns a sink creating alerts with the given API integration key.
+func NewOpsgenie(apiKey string) *Opsgenie {
+	return &Opsgenie{
+		APIKey:    apiKey,
+		URL:       OpsgenieURL,
+		Source:    hostname(),
+		MinLevel:  logger.CRITICAL,
+		Recovered: DefaultRecovered,
+		Client:    newClient(),
+	}
+}
+
+// Fire creates an alert for entries at MinLevel or above, and closes open
+// alerts when a recovery is logged, as PagerDuty.Fire does.
+func (o *Opsgenie) Fire(r logger.Record) error {
+	if _, ok := attr(r.Attrs, "alert"); ok {
+		return nil
+	}
+	if r.Level >= o.MinLevel {
+		key, name := incidentKey(r)
+		o.opened(key, name)
+		send := func() error { return o.create(key, r.Message, r.Level, details(r)) }
+		if r.Level == logger.CRITICAL {
+			return send()
+		}
+		o.async(send)
+		return nil
+	}
+	if o.Recovered != nil && o.Recovered.MatchString(r.Message) {
+		for _, key := range o.recovered(r) {
+			key := key
+			o.async(func() error { return o.close(key) })
+		}
+	}
+	return nil
+}
+
+// SendAlert creates an Opsgenie alert for an alert, aliased by its rule.
+func (o *Opsgenie) SendAlert(a logger.Alert) error {
+	key := alertKey(a.Rule)
+	o.opened(key, a.Rule)
+	d := details(a.Last)
+	d["count"], d["window"] = a.Count, a.Window.String()
+	return o.create(key, a.Message, logger.CRITICAL, d)
+}
+
+// Flush waits for the requests sent in the background.
+func (o *Opsgenie) Flush() {
+	o.wg.Wait()
+}
+
+func (o *Opsgenie) create(key, msg string, level logger.Level, d map[string]any) error {
+	// Opsgenie only takes string details.
+	sd := make(map[string]string, len(d))
+	for k, v := range d {
+		sd[k] = fmt.Sprint(v)
+	}
+	return o.post("/v2/alerts", map[string]any{
+		"message":     truncate(msg, 130),
+		"alias":       truncate(key, 512),
+		"description": truncate(msg, 15000),
+		"source":      o.Source,
+		"priority":    opsgeniePriority(level),
+		"details":     sd,
+	})
+}
+
+func (o *Opsgenie) close(key string) error {
+	return o.post("/v2/alerts/"+url.PathEscape(truncate(key, 512))+"/close?identifierType=alias", map[string]any{
+		"source": o.Source,
+	})
+}
+
+func (o *Opsgenie) post(path string, payload map[string]any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("opsgenie: %w", err)
+	}
+	base := o.URL
+	if base == "" {
+		base = OpsgenieURL
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("opsgenie: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+o.APIKey)
+	resp, err := client(o.Client).Do(req)
+	if err != nil {
+		return fmt.Errorf("opsgenie: %w", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return nil
+}
+
+// opsgeniePriority maps a level to an Opsgenie priority.
+func opsgeniePriority(lv logger.Level) string {
+	switch lv {
+	case logger.CRITICAL:
+		return "P1"
+	case logger.ERROR:
+		return "P2"
+	case logger.WARNING:
+		return "P3"
+	}
+	return "P4"
+}
@@ -0,0 +1,146 @@
+package pager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/jeanhaley32/logger"
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// PagerDutyURL is the PagerDuty Events API v2 endpoint.
+const PagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDuty sends incidents to the PagerDuty Events API v2.
+type PagerDuty struct {
+	RoutingKey string
+	URL        string              // PagerDutyURL if empty
+	Source     string              // the event source, the hostname by default
+	MinLevel   logger.Level        // least severe level that triggers, CRITICAL by default
+	Recovered  *regexp.Regexp      // entries that resolve incidents, DefaultRecovered by default
+	Client     *helpers.HTTPClient // a quiet client with retries if nil
+
+	tracker
+}
+
+// NewPagerDuty returns a sink sending to the service with the given
+// integration (routing) key.
+func NewPagerDuty(routingKey string) *PagerDuty {
+	return &PagerDuty{
+		RoutingKey: routingKey,
+		URL:        PagerDutyURL,
+		Source:     hostname(),
+		MinLevel:   logger.CRITICAL,
+		Recovered:  DefaultRecovered,
+		Client:     newClient(),
+	}
+}
+
+// Fire triggers an incident for entries at MinLevel or above, and resolves
+// open incidents when a recovery is logged. CRITICAL entries are sent before
+// returning, since the process exits right after; the rest are sent in the
+// background. Alert entries are left to SendAlert.
+func (p *PagerDuty) Fire(r logger.Record) error {
+	if _, ok := attr(r.Attrs, "alert"); ok {
+		return nil
+	}
+	if r.Level >= p.MinLevel {
+		key, name := incidentKey(r)
+		p.opened(key, name)
+		send := func() error { return p.trigger(key, r.Message, r.Level, r.Time, details(r)) }
+		if r.Level == logger.CRITICAL {
+			return send()
+		}
+		p.async(send)
+		return nil
+	}
+	if p.Recovered != nil && p.Recovered.MatchString(r.Message) {
+		for _, key := range p.recovered(r) {
+			key := key
+			p.async(func() error { return p.resolve(key) })
+		}
+	}
+	return nil
+}
+
+// SendAlert triggers an incident for an alert, keyed by its rule so repeats
+// add to the same incident. Logging a recovery that names the rule resolves it.
+func (p *PagerDuty) SendAlert(a logger.Alert) error {
+	key := alertKey(a.Rule)
+	p.opened(key, a.Rule)
+	d := details(a.Last)
+	d["count"], d["window"] = a.Count, a.Window.String()
+	return p.trigger(key, a.Message, logger.CRITICAL, a.Last.Time, d)
+}
+
+// Flush waits for the events sent in the background.
+func (p *PagerDuty) Flush() {
+	p.wg.Wait()
+}
+
+func (p *PagerDuty) trigger(key, summary string, level logger.Level, t time.Time, d map[string]any) error {
+	return p.post(map[string]any{
+		"routing_key":  p.RoutingKey,
+		"event_action": "trigger",
+		"dedup_key":    key,
+		"payload": map[string]any{
+			"summary":        truncate(summary, 1024),
+			"source":         p.Source,
+			"severity":       pagerDutySeverity(level),
+			"timestamp":      t.Format(time.RFC3339Nano),
+			"custom_details": d,
+		},
+	})
+}
+
+func (p *PagerDuty) resolve(key string) error {
+	return p.post(map[string]any{
+		"routing_key":  p.RoutingKey,
+		"event_action": "resolve",
+		"dedup_key":    key,
+	})
+}
+
+func (p *PagerDuty) post(event map[string]any) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("pagerduty: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	url := p.URL
+	if url == "" {
+		url = PagerDutyURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("pagerduty: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client(p.Client).Do(req)
+	if err != nil {
+		return fmt.Errorf("pagerduty %s %s: %w", event["event_action"], event["dedup_key"], err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return nil
+}
+
+// pagerDutySeverity maps a level to one of PagerDuty's severities.
+func pagerDutySeverity(lv logger.Level) string {
+	switch lv {
+	case logger.CRITICAL:
+		return "critical"
+	case logger.ERROR:
+		return "error"
+	case logger.WARNING:
+		return "warning"
+	}
+	return "info"
+}
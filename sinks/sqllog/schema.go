@@ -0,0 +1,104 @@
+package sqllog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// migrations are the schema changes, in order. Their position is their
+// version, recorded in the <table>_schema table once applied; add new ones at
+// the end and never edit an applied one. "{t}" is the table name.
+var migrations = []struct {
+	sqlite, postgres string
+}{
+	{
+		sqlite: `CREATE TABLE {t} (
+	id       INTEGER PRIMARY KEY AUTOINCREMENT,
+	time     TEXT NOT NULL,
+	level    TEXT NOT NULL,
+	severity INTEGER NOT NULL,
+	message  TEXT NOT NULL,
+	caller   TEXT NOT NULL DEFAULT '',
+	fields   TEXT NOT NULL DEFAULT '{}'
+);
+CREATE INDEX {t}_time ON {t} (time);
+CREATE INDEX {t}_severity ON {t} (severity, time)`,
+		postgres: `CREATE TABLE {t} (
+	id       BIGSERIAL PRIMARY KEY,
+	time     TIMESTAMPTZ NOT NULL,
+	level    TEXT NOT NULL,
+	severity SMALLINT NOT NULL,
+	message  TEXT NOT NULL,
+	caller   TEXT NOT NULL DEFAULT '',
+	fields   JSONB NOT NULL DEFAULT '{}'
+);
+CREATE INDEX {t}_time ON {t} (time);
+CREATE INDEX {t}_severity ON {t} (severity, time)`,
+	},
+}
+
+// queries are the statements of a dialect and table.
+type queries struct {
+	table         string
+	insert        string
+	expire        string
+	createVersion string
+	version       string
+	setVersion    string
+}
+
+func newQueries(d Dialect, table string) queries {
+	q := queries{table: table}
+	ph := func(n int) string { return "?" }
+	if d == Postgres {
+		ph = func(n int) string { return fmt.Sprintf("$%d", n) }
+	}
+	q.insert = fmt.Sprintf("INSERT INTO %s (time, level, severity, message, caller, fields) VALUES (%s, %s, %s, %s, %s, %s)",
+		table, ph(1), ph(2), ph(3), ph(4), ph(5), ph(6))
+	if d == Postgres {
+		q.insert = strings.Replace(q.insert, "$6)", "$6::jsonb)", 1)
+	}
+	q.expire = fmt.Sprintf("DELETE FROM %s WHERE time < %s", table, ph(1))
+	q.createVersion = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s_schema (version INTEGER NOT NULL)", table)
+	q.version = fmt.Sprintf("SELECT COALESCE(MAX(version), 0) FROM %s_schema", table)
+	q.setVersion = fmt.Sprintf("INSERT INTO %s_schema (version) VALUES (%s)", table, ph(1))
+	return q
+}
+
+// migrate applies the migrations the table hasn't had yet, each in its own
+// transaction with the version bump.
+func (s *Sink) migrate(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, s.q.createVersion); err != nil {
+		return fmt.Errorf("schema: %w", err)
+	}
+	var version int
+	if err := s.db.QueryRowContext(ctx, s.q.version).Scan(&version); err != nil {
+		return fmt.Errorf("schema: %w", err)
+	}
+	for v := version + 1; v <= len(migrations); v++ {
+		m := migrations[v-1].sqlite
+		if s.cfg.Dialect == Postgres {
+			m = migrations[v-1].postgres
+		}
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		// Drivers don't all take several statements in one Exec.
+		for _, stmt := range strings.Split(strings.ReplaceAll(m, "{t}", s.q.table), ";\n") {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("schema version %d: %w", v, err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, s.q.setVersion, v); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("schema version %d: %w", v, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("schema version %d: %w", v, err)
+		}
+	}
+	return nil
+}
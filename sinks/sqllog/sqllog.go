@@ -0,0 +1,193 @@
+// Package sqllog writes entries to a SQLite or Postgres table, so the logs of
+// a small deployment can be queried with SQL without running a log stack.
+//
+// The application opens the database with the driver of its choice:
+//
+//	db, err := sql.Open("sqlite", "logs.db")
+//	s, err := sqllog.New(db, sqllog.Config{Dialect: sqllog.SQLite, Retention: 30 * 24 * time.Hour})
+//	l.AddHook(s)
+//
+// The table is created, and migrated by later versions, when the sink
+// starts. Entries are inserted in batches, one transaction per batch.
+package sqllog
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/jeanhaley32/logger"
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// Dialect is the SQL flavour of the database.
+type Dialect int
+
+const (
+	SQLite Dialect = iota
+	Postgres
+)
+
+// sqliteTime is how times are stored in SQLite: fixed width, so they sort
+// as text.
+const sqliteTime = "2006-01-02T15:04:05.000000000Z"
+
+// Config configures a Sink.
+type Config struct {
+	Dialect   Dialect
+	Table     string        // "logs" if empty
+	MinLevel  logger.Level  // least severe level stored, DEBUG by default
+	BatchSize int           // entries per transaction, 100 if zero
+	MaxDelay  time.Duration // longest an entry waits for its batch, 1 second if zero
+	Retention time.Duration // entries older than this are deleted hourly; zero keeps everything
+}
+
+// Sink is a hook inserting entries into a table with the columns
+// id, time, level, severity (0 for DEBUG to 4 for CRITICAL), message,
+// caller and fields (a JSON object).
+type Sink struct {
+	db   *sql.DB
+	cfg  Config
+	q    queries
+	b    *helpers.Batcher[logger.Record]
+	stop chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// New migrates the table to the current schema and starts the sink. The
+// sink is closed, flushing the pending batch, when the process exits through
+// the logger.
+func New(db *sql.DB, cfg Config) (*Sink, error) {
+	if cfg.Table == "" {
+		cfg.Table = "logs"
+	}
+	if !identifier.MatchString(cfg.Table) {
+		return nil, fmt.Errorf("sqllog: invalid table name %q", cfg.Table)
+	}
+	if cfg.Dialect != SQLite && cfg.Dialect != Postgres {
+		return nil, fmt.Errorf("sqllog: unknown dialect %d", cfg.Dialect)
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = time.Second
+	}
+	s := &Sink{db: db, cfg: cfg, q: newQueries(cfg.Dialect, cfg.Table), stop: make(chan struct{})}
+	if err := s.migrate(context.Background()); err != nil {
+		return nil, fmt.Errorf("sqllog: %w", err)
+	}
+	s.b = helpers.NewBatcher(cfg.BatchSize, cfg.MaxDelay, s.insert)
+	if cfg.Retention > 0 {
+		s.wg.Add(1)
+		go s.expire()
+	}
+	helpers.OnExit(func() { s.Close() })
+	return s, nil
+}
+
+// Fire queues r for the next batch.
+func (s *Sink) Fire(r logger.Record) error {
+	if r.Level < s.cfg.MinLevel {
+		return nil
+	}
+	s.b.Add(r)
+	return nil
+}
+
+// Close inserts the pending batch and stops the retention cleanup. The
+// database stays open.
+func (s *Sink) Close() error {
+	s.once.Do(func() {
+		s.b.Close()
+		close(s.stop)
+		s.wg.Wait()
+	})
+	return nil
+}
+
+// insert writes a batch in one transaction.
+func (s *Sink) insert(rs []logger.Record) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, s.q.insert)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, r := range rs {
+		fields, err := json.Marshal(fieldMap(r.Attrs))
+		if err != nil {
+			fields = []byte("{}")
+		}
+		if _, err := stmt.ExecContext(ctx, s.timeValue(r.Time), r.Level.String(), int(r.Level), r.Message, r.Caller, string(fields)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// expire deletes entries past the retention period, hourly.
+func (s *Sink) expire() {
+	defer s.wg.Done()
+	t := time.NewTicker(time.Hour)
+	defer t.Stop()
+	for {
+		if err := s.Expire(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "sqllog: expire: %v\n", err)
+		}
+		select {
+		case <-s.stop:
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Expire deletes the entries older than the retention period now.
+func (s *Sink) Expire(ctx context.Context) error {
+	if s.cfg.Retention <= 0 {
+		return nil
+	}
+	_, err := s.db.ExecContext(ctx, s.q.expire, s.timeValue(time.Now().Add(-s.cfg.Retention)))
+	return err
+}
+
+// timeValue converts t to what the dialect stores.
+func (s *Sink) timeValue(t time.Time) any {
+	if s.cfg.Dialect == SQLite {
+		return t.UTC().Format(sqliteTime)
+	}
+	return t
+}
+
+// fieldMap turns attrs into a map, groups becoming nested maps.
+func fieldMap(attrs []logger.Attr) map[string]any {
+	m := make(map[string]any, len(attrs))
+	for _, a := range attrs {
+		switch v := a.Value.(type) {
+		case []logger.Attr:
+			m[a.Key] = fieldMap(v)
+		case error:
+			m[a.Key] = v.Error()
+		case fmt.Stringer:
+			m[a.Key] = v.String()
+		default:
+			m[a.Key] = v
+		}
+	}
+	return m
+}
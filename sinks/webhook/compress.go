@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// zstdEncoder is shared by every post: EncodeAll is safe for concurrent use,
+// and an encoder is costly to set up.
+var zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) {
+	return zstd.NewWriter(nil)
+})
+
+// compress encodes data with a Content-Encoding: "gzip", "zstd", or
+// "identity" and "" for none.
+func compress(encoding string, data []byte) ([]byte, error) {
+	switch encoding {
+	case "", "identity":
+		return data, nil
+	case "gzip":
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(data)
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case "zstd":
+		zw, err := zstdEncoder()
+		if err != nil {
+			return nil, err
+		}
+		return zw.EncodeAll(data, nil), nil
+	}
+	return nil, fmt.Errorf("unknown content encoding %q", encoding)
+}
@@ -0,0 +1,302 @@
+// Package webhook posts entries and alerts to an HTTP endpoint, as JSON
+// produced by a template, so a bespoke internal system can be fed without
+// writing a sink for it:
+//
+//	w, err := webhook.New("https://ops.internal/events", `{"text": {{json .Message}}, "sev": {{json .Level.String}}}`)
+//	w.Secret = []byte(os.Getenv("WEBHOOK_SECRET"))
+//	l.AddHook(w)
+//	defer w.Close()
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/jeanhaley32/logger"
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// Data is what the payload template is executed with: the entry, its fields
+// as a map, and the alert for alerts.
+type Data struct {
+	logger.Record
+	Host   string
+	Fields map[string]any
+	Alert  *logger.Alert
+}
+
+// Funcs are the functions available to the template: json encodes a value,
+// so strings are quoted and escaped.
+var Funcs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// Webhook is a sink posting each entry at MinLevel or above, and each alert,
+// to URL. Requests are sent in order on a background goroutine and retried
+// with backoff on network errors and 5xx responses.
+type Webhook struct {
+	URL      string
+	MinLevel logger.Level // least severe level sent, ERROR by default
+	Header   http.Header  // extra request headers, e.g. Authorization
+	Secret   []byte       // if set, the body is signed with HMAC-SHA256
+	SigName  string       // header holding the signature, "X-Signature-256" by default
+	Client   *helpers.HTTPClient
+
+	// Batch is the most entries posted in one request, as a JSON array of
+	// payloads; 1 or less posts each payload on its own.
+	Batch int
+	// Compression is the Content-Encoding of request bodies: "gzip", "zstd"
+	// or empty for none. If the endpoint answers 415 Unsupported Media Type,
+	// the webhook stops compressing.
+	Compression string
+
+	tmpl    *template.Template
+	queue   chan Data
+	dropped atomic.Int64
+	plain   atomic.Bool // the endpoint refused compressed bodies
+	once    sync.Once
+	done    chan struct{}
+	closeMu sync.RWMutex
+	closed  bool
+}
+
+// New returns a webhook posting to url. payload is a text/template over
+// Data producing a JSON document; if it's empty the whole Data is sent as
+// JSON.
+func New(url, payload string) (*Webhook, error) {
+	w := &Webhook{
+		URL:      url,
+		MinLevel: logger.ERROR,
+		Header:   http.Header{},
+		SigName:  "X-Signature-256",
+		Client:   helpers.NewHTTPClient(helpers.Discard),
+		queue:    make(chan Data, 1000),
+		done:     make(chan struct{}),
+	}
+	w.Client.CaptureBody = 512
+	if payload != "" {
+		t, err := template.New("payload").Funcs(Funcs).Parse(payload)
+		if err != nil {
+			return nil, fmt.Errorf("webhook: %w", err)
+		}
+		w.tmpl = t
+	}
+	return w, nil
+}
+
+// Fire queues entries at MinLevel or above. Entries are dropped, and
+// counted, when the queue is full, rather than holding up logging.
+func (w *Webhook) Fire(r logger.Record) error {
+	if r.Level < w.MinLevel {
+		return nil
+	}
+	w.enqueue(Data{Record: r})
+	return nil
+}
+
+// SendAlert queues an alert, with its last entry as the Record.
+func (w *Webhook) SendAlert(a logger.Alert) error {
+	w.enqueue(Data{Record: a.Last, Alert: &a})
+	return nil
+}
+
+// Dropped returns the number of entries dropped because the queue was full.
+func (w *Webhook) Dropped() int64 {
+	return w.dropped.Load()
+}
+
+// Close sends the queued entries and stops the webhook.
+func (w *Webhook) Close() error {
+	w.closeMu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.closeMu.Unlock()
+	w.once.Do(func() { go w.run() })
+	<-w.done
+	return nil
+}
+
+func (w *Webhook) enqueue(d Data) {
+	w.closeMu.RLock()
+	defer w.closeMu.RUnlock()
+	if w.closed {
+		return
+	}
+	w.once.Do(func() { go w.run() })
+	select {
+	case w.queue <- d:
+	default:
+		w.dropped.Add(1)
+	}
+}
+
+func (w *Webhook) run() {
+	defer close(w.done)
+	host, _ := os.Hostname()
+	for d := range w.queue {
+		batch := []Data{d}
+		// take whatever else is already queued, up to Batch.
+	fill:
+		for len(batch) < w.Batch {
+			select {
+			case d, ok := <-w.queue:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, d)
+			default:
+				break fill
+			}
+		}
+		for i := range batch {
+			batch[i].Host = host
+		}
+		if err := helpers.Safe(func() error { return w.post(batch) }); err != nil {
+			fmt.Fprintf(os.Stderr, "webhook: %v\n", err)
+		}
+	}
+}
+
+// Payload renders the body posted for d.
+func (w *Webhook) Payload(d Data) ([]byte, error) {
+	d.Fields = fields(d.Attrs)
+	if w.tmpl == nil {
+		return json.Marshal(map[string]any{
+			"time":   d.Time,
+			"level":  d.Level.String(),
+			"msg":    d.Message,
+			"caller": d.Caller,
+			"host":   d.Host,
+			"fields": d.Fields,
+			"alert":  d.Alert,
+		})
+	}
+	var buf bytes.Buffer
+	if err := w.tmpl.Execute(&buf, d); err != nil {
+		return nil, err
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, errors.New("template produced invalid JSON: " + buf.String())
+	}
+	return buf.Bytes(), nil
+}
+
+// Sign returns the signature header value for body: "sha256=" and the hex
+// HMAC-SHA256 of the body keyed with secret, as GitHub signs its webhooks.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// post sends a batch: a single payload on its own, several as an array.
+func (w *Webhook) post(batch []Data) error {
+	var body []byte
+	if len(batch) == 1 && w.Batch <= 1 {
+		p, err := w.Payload(batch[0])
+		if err != nil {
+			return err
+		}
+		body = p
+	} else {
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for _, d := range batch {
+			p, err := w.Payload(d)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "webhook: %v\n", err)
+				continue
+			}
+			if buf.Len() > 1 {
+				buf.WriteByte(',')
+			}
+			buf.Write(p)
+		}
+		buf.WriteByte(']')
+		body = buf.Bytes()
+	}
+	encoding := w.Compression
+	if w.plain.Load() {
+		encoding = ""
+	}
+	status, err := w.send(body, encoding)
+	if status == http.StatusUnsupportedMediaType && encoding != "" {
+		w.plain.Store(true)
+		_, err = w.send(body, "")
+	}
+	return err
+}
+
+// send posts body, compressed with encoding, and returns the status.
+func (w *Webhook) send(body []byte, encoding string) (int, error) {
+	zbody, err := compress(encoding, body)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(zbody))
+	if err != nil {
+		return 0, err
+	}
+	for k, v := range w.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	if len(w.Secret) > 0 {
+		// signed before compression, so the receiver checks what it decoded.
+		req.Header.Set(w.SigName, Sign(w.Secret, body))
+	}
+	client := w.Client
+	if client == nil {
+		client = helpers.NewHTTPClient(helpers.Discard)
+	}
+	resp, err := client.Do(req)
+	var he *helpers.HTTPError
+	if errors.As(err, &he) {
+		return he.StatusCode, err
+	}
+	if err != nil {
+		return 0, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// fields turns attrs into a map, groups becoming nested maps.
+func fields(attrs []logger.Attr) map[string]any {
+	m := make(map[string]any, len(attrs))
+	for _, a := range attrs {
+		switch v := a.Value.(type) {
+		case []logger.Attr:
+			m[a.Key] = fields(v)
+		case error:
+			m[a.Key] = v.Error()
+		default:
+			m[a.Key] = v
+		}
+	}
+	return m
+}
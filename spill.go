@@ -0,0 +1,297 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// spill holds entries on disk while the queue is full, see WithSpill.
+type spill struct {
+	mu      sync.Mutex
+	dir     string
+	f       *os.File // created on the first spill, removed at shutdown
+	written int64    // bytes of entries appended to f
+	read    int64    // bytes of entries replayed into the queue
+	active  bool     // entries go to f until it's replayed, to keep their order
+	closed  bool     // the logger drained, nothing more is replayed
+	count   *helpers.Counter
+}
+
+// maxSpilled is the longest line of the spill file. Longer entries aren't
+// spilled, they wait for room in the queue.
+const maxSpilled = 16 << 20
+
+// spilled is an entry as written to the spill file. The message is written
+// formatted, and the fields of a CodedError value with the others, so the
+// entry reads back the same without its value. Field values that JSON can't
+// hold are kept as text.
+type spilled struct {
+	Time    time.Time     `json:"t"`
+	Level   Level         `json:"l"`
+	Message string        `json:"m"`
+	JSONAt  int           `json:"j,omitempty"` // see Record.jsonAt
+	Caller  string        `json:"c,omitempty"`
+	Attrs   []spilledAttr `json:"a,omitempty"`
+	Always  bool          `json:"w,omitempty"`
+}
+
+type spilledAttr struct {
+	Key   string        `json:"k"`
+	Value any           `json:"v,omitempty"`
+	Group []spilledAttr `json:"g,omitempty"`
+}
+
+// WithSpill makes a full queue spill entries to a file in dir (the temp
+// directory if empty) instead of blocking the caller. They're fed back into
+// the queue, in order, as the mediator catches up, so memory stays bounded
+// and nothing is lost. Entries of levels with guaranteed delivery don't
+// spill: they wait for room in the queue, and may be written ahead of
+// spilled entries.
+func WithSpill(dir string) Option {
+	return func(l *Mylogger) error {
+		if dir == "" {
+			dir = os.TempDir()
+		}
+		if info, err := os.Stat(dir); err != nil {
+			return fmt.Errorf("spill directory: %w", err)
+		} else if !info.IsDir() {
+			return fmt.Errorf("spill directory %s is not a directory", dir)
+		}
+		l.spill = &spill{dir: dir, count: l.stats.counter("logger_entries_spilled_total", nil)}
+		return nil
+	}
+}
+
+// offer queues en, or appends it to the spill file if the queue is full or
+// entries are already spilled. It's called with sendMu held for reading.
+func (s *spill) offer(l *Mylogger, en entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.active {
+		select {
+		case l.chans.entries <- en:
+			return
+		default:
+		}
+	}
+	if err := s.append(l, en); err != nil {
+		// no disk to spill to: block like the queue would.
+		l.reportError(fmt.Errorf("spill: %w", err))
+		l.enqueue(en)
+		return
+	}
+	if !s.active {
+		s.active = true
+		go s.replay(l)
+	}
+}
+
+// append writes en to the spill file.
+func (s *spill) append(l *Mylogger, en entry) error {
+	if s.f == nil {
+		f, err := os.CreateTemp(s.dir, "logger-spill-*.jsonl")
+		if err != nil {
+			return err
+		}
+		s.f = f
+	}
+	b, err := json.Marshal(spilled{
+		Time:    en.at,
+		Level:   en.level,
+		Message: en.text(),
+		JSONAt:  jsonStart(en.value),
+		Caller:  en.caller,
+		Attrs:   spillAttrs(errorAttrs(en.value, en.attrs)),
+		Always:  en.always,
+	})
+	if err != nil {
+		return err
+	}
+	if len(b) >= maxSpilled {
+		return fmt.Errorf("entry of %s is too long to spill", helpers.Bytes(int64(len(b))))
+	}
+	b = append(b, '\n')
+	if _, err := s.f.WriteAt(b, s.written); err != nil {
+		return err
+	}
+	s.written += int64(len(b))
+	s.count.Inc()
+	return nil
+}
+
+// replay feeds spilled entries back into the queue until the file is
+// empty, then lets entries go to the queue again.
+func (s *spill) replay(l *Mylogger) {
+	for {
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			return
+		}
+		if s.read == s.written {
+			s.active = false
+			s.read, s.written = 0, 0
+			s.f.Truncate(0)
+			s.mu.Unlock()
+			return
+		}
+		off, end := s.read, s.written
+		s.mu.Unlock()
+
+		closed := false
+		s.scan(l, off, end, func(en *entry, n int64) bool {
+			l.sendMu.RLock()
+			defer l.sendMu.RUnlock()
+			if closed = l.closed; closed {
+				return false
+			}
+			if en != nil {
+				l.chans.entries <- *en
+			}
+			// counted while sendMu is held, so drain sees exactly what's left.
+			s.mu.Lock()
+			s.read += n
+			s.mu.Unlock()
+			return true
+		})
+		if closed {
+			return
+		}
+	}
+}
+
+// scan calls fn with each entry spilled between off and end, and the bytes
+// it takes, until fn returns false. Lines that can't be read or decoded are
+// skipped: fn is called with a nil entry for their bytes, and they're
+// reported once fn took them.
+func (s *spill) scan(l *Mylogger, off, end int64, fn func(en *entry, n int64) bool) {
+	r := bufio.NewReader(io.NewSectionReader(s.f, off, end-off))
+	for off < end {
+		var line []byte
+		var n int64
+		var err error
+		long := false
+		for {
+			b, rerr := r.ReadSlice('\n')
+			n += int64(len(b))
+			if !long {
+				line = append(line, b...)
+				long = n > maxSpilled
+			}
+			if rerr != bufio.ErrBufferFull {
+				err = rerr
+				break
+			}
+		}
+		var en *entry
+		var bad error
+		switch {
+		case err != nil:
+			// unreadable: give up on the rest.
+			if fn(nil, end-off) {
+				l.reportError(fmt.Errorf("spill: skipped %s: %w", helpers.Bytes(end-off), err))
+			}
+			return
+		case long:
+			bad = fmt.Errorf("spill: skipped a line of %s", helpers.Bytes(n))
+		default:
+			e, derr := unspill(line)
+			if derr != nil {
+				bad = fmt.Errorf("spill: %w", derr)
+			} else {
+				en = &e
+			}
+		}
+		if !fn(en, n) {
+			return
+		}
+		if bad != nil {
+			l.reportError(bad)
+		}
+		off += n
+	}
+}
+
+// drain writes what's left in the spill file and removes it. It's called
+// once nothing can be queued any more.
+func (s *spill) drain(l *Mylogger) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	if s.f == nil {
+		return
+	}
+	s.scan(l, s.read, s.written, func(en *entry, _ int64) bool {
+		if en != nil {
+			l.write(*en)
+		}
+		return true
+	})
+	s.f.Close()
+	os.Remove(s.f.Name())
+}
+
+// unspill decodes a line of the spill file back into an entry.
+func unspill(line []byte) (entry, error) {
+	var sp spilled
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&sp); err != nil {
+		return entry{}, err
+	}
+	var value any = sp.Message
+	if j := sp.JSONAt; j >= 2 && j <= len(sp.Message) {
+		value = jsonMessage{sp.Message[:j-2], sp.Message[j:]}
+	}
+	return entry{at: sp.Time, level: sp.Level, value: value, caller: sp.Caller, attrs: unspillAttrs(sp.Attrs), always: sp.Always}, nil
+}
+
+func spillAttrs(attrs []Attr) []spilledAttr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	out := make([]spilledAttr, len(attrs))
+	for i, a := range attrs {
+		out[i].Key = a.Key
+		switch v := a.Value.(type) {
+		case []Attr:
+			out[i].Group = spillAttrs(v)
+		case error, fmt.Stringer, time.Time:
+			out[i].Value = attrString(v)
+		default:
+			if _, err := json.Marshal(v); err != nil {
+				out[i].Value = attrString(v)
+			} else {
+				out[i].Value = v
+			}
+		}
+	}
+	return out
+}
+
+func unspillAttrs(attrs []spilledAttr) []Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	out := make([]Attr, len(attrs))
+	for i, a := range attrs {
+		out[i].Key = a.Key
+		if a.Group != nil {
+			out[i].Value = unspillAttrs(a.Group)
+		} else {
+			out[i].Value = a.Value
+		}
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// spillWhileHeld runs log with the mediator held by a hook and the queue
+// full, so what it logs spills, then shuts the logger down and returns what
+// was written to a terminal.
+func spillWhileHeld(t *testing.T, log func(l *Mylogger)) string {
+	t.Helper()
+	var out syncBuffer
+	l, err := StartLogger(WithOutput(&out), WithEncoder(TextEncoder{}), WithSpill(t.TempDir()), WithQueueSize(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.out.mu.Lock()
+	l.out.terminal = true
+	l.out.mu.Unlock()
+	busy, release := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	l.AddHook(HookFunc(func(Record) error {
+		once.Do(func() {
+			close(busy)
+			<-release
+		})
+		return nil
+	}))
+	l.Info("holds the mediator")
+	<-busy
+	l.Info("fills the queue")
+	log(l)
+	close(release)
+	l.Shutdown(nil)
+	if l.spill.count.Load() == 0 {
+		t.Fatal("nothing spilled")
+	}
+	return out.String()
+}
+
+func TestSpilledEntriesReplayWhole(t *testing.T) {
+	body := map[string]any{"user": "ada"}
+	s := spillWhileHeld(t, func(l *Mylogger) {
+		l.Infof("%d%% done", 100)
+		l.Error(E("E42", "db down", Field("table", "users")))
+		l.InfoJSON("body", body)
+	})
+	for _, want := range []string{
+		" 100% done\n",
+		" db down code=E42 table=users\n",
+		" body:\n" + helpers.HighlightJSON(helpers.PrettyJSON(body)),
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("output lacks %q:\n%s", want, s)
+		}
+	}
+
+	// settings changes are written whatever the level, spilled or not.
+	s = spillWhileHeld(t, func(l *Mylogger) { l.SetLevel(ERROR) })
+	if !strings.Contains(s, "Logger level changed from info to error") {
+		t.Errorf("the spilled level change was lost:\n%s", s)
+	}
+}
+
+func TestSpillSkipsBadLines(t *testing.T) {
+	var errs errorLog
+	s := spillWhileHeld(t, func(l *Mylogger) {
+		l.SetErrorHandler(errs.handle)
+		l.Info("before")
+		// a line that isn't an entry and one too long to be read.
+		l.spill.mu.Lock()
+		if err := l.spill.append(l, entry{value: strings.Repeat("x", maxSpilled)}); err == nil {
+			t.Error("an entry longer than a spill line was spilled")
+		}
+		for _, bad := range []string{"not json\n", strings.Repeat("x", maxSpilled+1) + "\n"} {
+			if _, err := l.spill.f.WriteAt([]byte(bad), l.spill.written); err != nil {
+				t.Fatal(err)
+			}
+			l.spill.written += int64(len(bad))
+		}
+		l.spill.mu.Unlock()
+		l.Info("after")
+	})
+	for _, want := range []string{" before\n", " after\n"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("output lacks %q:\n%s", want, s)
+		}
+	}
+	if got := errs.list(); len(got) != 2 || !strings.Contains(got[1], "skipped a line") {
+		t.Errorf("reported %q, want the two bad lines", got)
+	}
+}
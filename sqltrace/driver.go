@@ -0,0 +1,284 @@
+package sqltrace
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"time"
+)
+
+// conn wraps a driver connection. It implements every optional interface
+// database/sql looks for, falling back to what database/sql would do when
+// the wrapped connection doesn't.
+type conn struct {
+	driver.Conn
+	t *tracer
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var s driver.Stmt
+	var err error
+	if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		s, err = pc.PrepareContext(ctx, query)
+	} else {
+		s, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{Stmt: s, query: query, t: c.t}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	res, err := ec.ExecContext(ctx, query, args)
+	c.t.log("exec", query, args, rowsAffected(res, err), start, err)
+	return res, err
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := qc.QueryContext(ctx, query, args)
+	if err != nil {
+		c.t.log("query", query, args, -1, start, err)
+		return nil, err
+	}
+	return &traceRows{Rows: rows, query: query, args: args, start: start, t: c.t}, nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	start := time.Now()
+	var tx driver.Tx
+	var err error
+	if bc, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = bc.BeginTx(ctx, opts)
+	} else {
+		tx, err = c.Conn.Begin()
+	}
+	c.t.log("begin", "BEGIN", nil, -1, start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &traceTx{Tx: tx, t: c.t}, nil
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// stmt wraps a prepared statement.
+type stmt struct {
+	driver.Stmt
+	query string
+	t     *tracer
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var res driver.Result
+	var err error
+	if ec, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = ec.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = plainValues(args); err == nil {
+			res, err = s.Stmt.Exec(values)
+		}
+	}
+	s.t.log("exec", s.query, args, rowsAffected(res, err), start, err)
+	return res, err
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if qc, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = qc.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = plainValues(args); err == nil {
+			rows, err = s.Stmt.Query(values)
+		}
+	}
+	if err != nil {
+		s.t.log("query", s.query, args, -1, start, err)
+		return nil, err
+	}
+	return &traceRows{Rows: rows, query: s.query, args: args, start: start, t: s.t}, nil
+}
+
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// traceRows counts the rows read, and logs the query when they're closed, so
+// its duration covers reading the results.
+type traceRows struct {
+	driver.Rows
+	query string
+	args  []driver.NamedValue
+	start time.Time
+	t     *tracer
+	n     int64
+	err   error
+}
+
+func (r *traceRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	switch {
+	case err == nil:
+		r.n++
+	case err != io.EOF:
+		r.err = err
+	}
+	return err
+}
+
+func (r *traceRows) HasNextResultSet() bool {
+	if rs, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rs.HasNextResultSet()
+	}
+	return false
+}
+
+func (r *traceRows) NextResultSet() error {
+	if rs, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rs.NextResultSet()
+	}
+	return io.EOF
+}
+
+// The column type methods return what database/sql assumes when a driver
+// doesn't implement them.
+
+func (r *traceRows) ColumnTypeScanType(i int) reflect.Type {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return ct.ColumnTypeScanType(i)
+	}
+	return reflect.TypeOf(new(any)).Elem()
+}
+
+func (r *traceRows) ColumnTypeDatabaseTypeName(i int) string {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return ct.ColumnTypeDatabaseTypeName(i)
+	}
+	return ""
+}
+
+func (r *traceRows) ColumnTypeLength(i int) (int64, bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+		return ct.ColumnTypeLength(i)
+	}
+	return 0, false
+}
+
+func (r *traceRows) ColumnTypeNullable(i int) (bool, bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+		return ct.ColumnTypeNullable(i)
+	}
+	return false, false
+}
+
+func (r *traceRows) ColumnTypePrecisionScale(i int) (int64, int64, bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return ct.ColumnTypePrecisionScale(i)
+	}
+	return 0, 0, false
+}
+
+func (r *traceRows) Close() error {
+	err := r.Rows.Close()
+	r.t.log("query", r.query, r.args, r.n, r.start, errors.Join(r.err, err))
+	return err
+}
+
+// traceTx logs commits and rollbacks.
+type traceTx struct {
+	driver.Tx
+	t *tracer
+}
+
+func (tx *traceTx) Commit() error {
+	start := time.Now()
+	err := tx.Tx.Commit()
+	tx.t.log("commit", "COMMIT", nil, -1, start, err)
+	return err
+}
+
+func (tx *traceTx) Rollback() error {
+	start := time.Now()
+	err := tx.Tx.Rollback()
+	tx.t.log("rollback", "ROLLBACK", nil, -1, start, err)
+	return err
+}
+
+// rowsAffected returns the rows affected by an exec, or -1 if it's unknown.
+func rowsAffected(res driver.Result, err error) int64 {
+	if err != nil || res == nil {
+		return -1
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// plainValues converts args for drivers without the context methods, which
+// don't support named arguments.
+func plainValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, a := range args {
+		if a.Name != "" {
+			return nil, errors.New("sqltrace: driver does not support named arguments")
+		}
+		values[i] = a.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,180 @@
+package sqltrace
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// insertRe matches the start of INSERT INTO t (a, b) VALUES, whose tuples'
+// placeholders are matched to the columns by their position in the tuple.
+var insertRe = regexp.MustCompile(`(?is)insert\s+into\s+\S+\s*\(([^)]*)\)\s*values\s*`)
+
+// paren is an open parenthesis of the query.
+type paren struct {
+	column string // the column of x IN (...)
+	tuple  bool   // a VALUES tuple, whose items are bound to columns[item]
+	item   int
+}
+
+// placeholderColumns maps the ordinals of the positional placeholders in
+// query (? and $N) to the column each is bound to. A placeholder whose
+// column can't be told, or a $N bound to different columns, maps to "".
+func placeholderColumns(query string) map[int]string {
+	var columns []string
+	values := -1 // where the VALUES tuples start
+	if m := insertRe.FindStringSubmatchIndex(query); m != nil {
+		columns = strings.Split(query[m[2]:m[3]], ",")
+		values = m[1]
+	}
+	cols := map[int]string{}
+	set := func(ord int, col string) {
+		if old, ok := cols[ord]; ok && old != col {
+			col = ""
+		}
+		cols[ord] = col
+	}
+	var stack []paren
+	inValues := false
+	n := 0
+	inQuote := byte(0)
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		if i == values {
+			inValues = true
+		}
+		if inQuote != 0 {
+			if c == inQuote {
+				inQuote = 0
+			}
+			continue
+		}
+		if inValues && len(stack) == 0 && c != '(' && c != ',' && !isSpace(c) {
+			// ON CONFLICT, RETURNING and the like.
+			inValues = false
+		}
+		ord, start := 0, i
+		switch c {
+		case '\'', '"', '`':
+			inQuote = c
+			continue
+		case '(':
+			stack = append(stack, paren{column: parenColumn(query[:i+1]), tuple: inValues && len(stack) == 0})
+			continue
+		case ')':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			continue
+		case ',':
+			if len(stack) > 0 && stack[len(stack)-1].tuple {
+				stack[len(stack)-1].item++
+			}
+			continue
+		case '?':
+			n++
+			ord = n
+		case '$':
+			j := i + 1
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			if j == i+1 {
+				continue
+			}
+			ord, _ = strconv.Atoi(query[i+1 : j])
+			i = j - 1
+		default:
+			continue
+		}
+		col := comparedColumn(query[:start])
+		for k := len(stack) - 1; k >= 0 && col == ""; k-- {
+			switch p := stack[k]; {
+			case p.column != "":
+				col = p.column
+			case p.tuple:
+				if p.item < len(columns) {
+					col = columnName(columns[p.item])
+				}
+				k = 0
+			}
+		}
+		set(ord, col)
+	}
+	return cols
+}
+
+// comparedColumn returns the column a placeholder following prefix is
+// compared with or assigned to: "u.password = ", "name LIKE ", "id IN (".
+// It looks back from the end only, so scanning a query stays linear.
+func comparedColumn(prefix string) string {
+	s := strings.TrimRight(prefix, " \t\r\n")
+	switch {
+	case strings.HasSuffix(s, "("):
+		s = strings.TrimRight(s[:len(s)-1], " \t\r\n")
+		if !hasWordSuffix(s, "in") {
+			return ""
+		}
+		s = s[:len(s)-2]
+	case hasWordSuffix(s, "like"):
+		s = s[:len(s)-4]
+	default:
+		j := len(s)
+		for j > 0 && strings.IndexByte("=<>!", s[j-1]) >= 0 {
+			j--
+		}
+		if op := s[j:]; op == "" || strings.IndexByte("<>", op[0]) < 0 && op != "=" && op != "!=" {
+			return ""
+		}
+		s = s[:j]
+	}
+	s = strings.TrimRight(s, " \t\r\n")
+	j := len(s)
+	for j > 0 && isColumnByte(s[j-1]) {
+		j--
+	}
+	if j == len(s) {
+		return ""
+	}
+	return columnName(s[j:])
+}
+
+// parenColumn returns the column of the placeholders in a parenthesis opened
+// at the end of prefix: "id IN (", or "email = lower(" for a function call.
+func parenColumn(prefix string) string {
+	if col := comparedColumn(prefix); col != "" {
+		return col
+	}
+	s := prefix[:len(prefix)-1]
+	j := len(s)
+	for j > 0 && isColumnByte(s[j-1]) {
+		j--
+	}
+	if j == len(s) {
+		return ""
+	}
+	return comparedColumn(s[:j])
+}
+
+// hasWordSuffix reports whether s ends with the keyword word, ignoring case.
+func hasWordSuffix(s, word string) bool {
+	n := len(s) - len(word)
+	return n >= 0 && strings.EqualFold(s[n:], word) && (n == 0 || !isColumnByte(s[n-1]))
+}
+
+func isColumnByte(c byte) bool {
+	return c == '_' || c == '.' || c == '"' || c == '`' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// columnName strips the table and quoting from a column reference.
+func columnName(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndexByte(s, '.'); i >= 0 {
+		s = s[i+1:]
+	}
+	return strings.Trim(s, "\"`")
+}
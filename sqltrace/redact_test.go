@@ -0,0 +1,92 @@
+package sqltrace
+
+import (
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jeanhaley32/logger"
+)
+
+func TestPlaceholderColumns(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		want  map[int]string
+	}{
+		{"INSERT INTO users (name, password) VALUES (?, ?), (?, ?)",
+			map[int]string{1: "name", 2: "password", 3: "name", 4: "password"}},
+		{"INSERT INTO users (name, password) VALUES ($1, lower($2))",
+			map[int]string{1: "name", 2: "password"}},
+		{"insert into t (a, b, c) values (?, coalesce(?, 'x,y'), ?), (?, f(?, ?), ?) returning id",
+			map[int]string{1: "a", 2: "b", 3: "c", 4: "a", 5: "b", 6: "b", 7: "c"}},
+		{"INSERT INTO users (name) VALUES (?, ?)",
+			map[int]string{1: "name", 2: ""}},
+		{"UPDATE users SET password = ?, name = ? WHERE u.id = ?",
+			map[int]string{1: "password", 2: "name", 3: "id"}},
+		{`SELECT * FROM t WHERE "token" <> $1 AND email = lower($2)`,
+			map[int]string{1: "token", 2: "email"}},
+		{"SELECT * FROM t WHERE id IN (?, ?) AND note LIKE ?",
+			map[int]string{1: "id", 2: "id", 3: "note"}},
+		{"SELECT * FROM t WHERE a = $1 OR b = $1", map[int]string{1: ""}},
+		{"SELECT * FROM t WHERE name = 'a = ?' LIMIT ?", map[int]string{1: ""}},
+	} {
+		if got := placeholderColumns(tc.query); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("placeholderColumns(%q) = %v, want %v", tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestArgsAreRedacted(t *testing.T) {
+	tr := &tracer{opts: Options{Redact: DefaultRedact, MaxArgLen: 64}}
+	args := func(vs ...any) []driver.NamedValue {
+		out := make([]driver.NamedValue, len(vs))
+		for i, v := range vs {
+			out[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+		}
+		return out
+	}
+	for _, tc := range []struct {
+		query string
+		args  []driver.NamedValue
+		want  []string
+	}{
+		{"INSERT INTO users (name, password) VALUES (?, ?), (?, ?)", args("ann", "s1", "bob", "s2"),
+			[]string{`"ann"`, "[redacted]", `"bob"`, "[redacted]"}},
+		{"INSERT INTO users (name, password) VALUES ($1, lower($2))", args("ann", "s1"),
+			[]string{`"ann"`, "[redacted]"}},
+		{"SELECT * FROM t WHERE id = ? LIMIT ?", args(7, 10), []string{"7", "[redacted]"}},
+		{"SELECT * FROM t WHERE id = @id", []driver.NamedValue{{Name: "id", Ordinal: 1, Value: 7}}, []string{"7"}},
+	} {
+		if got := tr.args(tc.query, tc.args); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("args of %q = %q, want %q", tc.query, got, tc.want)
+		}
+	}
+}
+
+// countedArg counts how often it's formatted.
+type countedArg struct{ n *int }
+
+func (a countedArg) String() string {
+	*a.n++
+	return "arg"
+}
+
+func TestLogSkipsDisabledQueries(t *testing.T) {
+	l, err := logger.StartLogger(logger.WithSyncMode(), logger.WithOutput(io.Discard), logger.WithLevel(logger.INFO))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Shutdown(nil)
+	var n int
+	tr := &tracer{l: l, opts: Options{Slow: time.Hour, Redact: DefaultRedact, MaxArgLen: 64}}
+	tr.log("query", "SELECT * FROM t WHERE id = ?", []driver.NamedValue{{Ordinal: 1, Value: countedArg{&n}}}, -1, time.Now(), nil)
+	if n != 0 {
+		t.Errorf("a query logged at DEBUG with INFO on formatted its args %d times", n)
+	}
+	tr.log("query", "SELECT * FROM t WHERE id = ?", []driver.NamedValue{{Ordinal: 1, Value: countedArg{&n}}}, -1, time.Now().Add(-2*time.Hour), nil)
+	if n != 1 {
+		t.Errorf("a slow query formatted its args %d times, want 1", n)
+	}
+}
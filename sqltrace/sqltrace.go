@@ -0,0 +1,189 @@
+// Package sqltrace logs the queries an application makes through
+// database/sql. It wraps the driver, so every query, exec, prepared
+// statement and transaction goes through the logger without changing the
+// code that uses the *sql.DB:
+//
+//	db, err := sqltrace.Open("postgres", dsn, l, sqltrace.Options{Slow: 200 * time.Millisecond})
+//
+// Each query is logged at DEBUG with its text, arguments, rows affected or
+// read, and duration, and at WARNING if it took longer than Options.Slow.
+// Arguments bound to sensitive columns or names, like password, and those
+// whose column can't be told from the query, are replaced by "[redacted]".
+package sqltrace
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jeanhaley32/logger"
+)
+
+// Options configures the logging of queries.
+type Options struct {
+	// Queries taking longer than Slow are logged at WARNING instead of
+	// DEBUG; 0 never promotes them.
+	Slow time.Duration
+	// Redact lists the argument names and column names whose values are
+	// replaced by "[redacted]", matched ignoring case. DefaultRedact if nil.
+	Redact []string
+	// MaxArgLen truncates long string and []byte arguments, 64 if zero.
+	MaxArgLen int
+}
+
+// DefaultRedact is the Redact list used when Options.Redact is nil.
+var DefaultRedact = []string{"password", "passwd", "secret", "token", "api_key", "apikey"}
+
+// Open opens a database with the registered driver driverName, as sql.Open
+// does, with its queries logged to l.
+func Open(driverName, dsn string, l *logger.Mylogger, opts Options) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	d := db.Driver()
+	db.Close()
+	if dc, ok := d.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+		return sql.OpenDB(WrapConnector(c, l, opts)), nil
+	}
+	return sql.OpenDB(WrapConnector(dsnConnector{dsn: dsn, d: d}, l, opts)), nil
+}
+
+// WrapConnector returns a connector whose connections log their queries to l.
+func WrapConnector(c driver.Connector, l *logger.Mylogger, opts Options) driver.Connector {
+	if opts.Redact == nil {
+		opts.Redact = DefaultRedact
+	}
+	if opts.MaxArgLen <= 0 {
+		opts.MaxArgLen = 64
+	}
+	return &connector{c: c, t: &tracer{l: l, opts: opts}}
+}
+
+// dsnConnector is the connector of a driver that doesn't provide one.
+type dsnConnector struct {
+	dsn string
+	d   driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.d.Open(c.dsn) }
+func (c dsnConnector) Driver() driver.Driver                        { return c.d }
+
+type connector struct {
+	c driver.Connector
+	t *tracer
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dc, err := c.c.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: dc, t: c.t}, nil
+}
+
+func (c *connector) Driver() driver.Driver { return c.c.Driver() }
+
+// tracer logs queries.
+type tracer struct {
+	l    *logger.Mylogger
+	opts Options
+}
+
+// log logs a finished query. rows is the number of rows affected or read,
+// or -1 if unknown.
+func (t *tracer) log(op, query string, args []driver.NamedValue, rows int64, start time.Time, err error) {
+	if err == driver.ErrSkip {
+		return
+	}
+	d := time.Since(start)
+	slow := t.opts.Slow > 0 && d > t.opts.Slow
+	if !t.l.Enabled(logger.DEBUG) && !(slow && t.l.Enabled(logger.WARNING)) {
+		return
+	}
+	attrs := []logger.Attr{
+		logger.Field("query", compact(query)),
+		logger.Field("duration", d.String()),
+	}
+	if len(args) > 0 {
+		attrs = append(attrs, logger.Field("args", t.args(query, args)))
+	}
+	if rows >= 0 {
+		attrs = append(attrs, logger.Field("rows", rows))
+	}
+	if err != nil {
+		attrs = append(attrs, logger.Field("error", err.Error()))
+	}
+	s := t.l.With(attrs...)
+	msg := "sql " + op
+	if slow {
+		s.Warning(fmt.Sprintf("%s: slow, took %s", msg, d.Round(time.Millisecond)))
+		return
+	}
+	s.Debug(msg)
+}
+
+// args formats args for logging, redacting sensitive ones and those whose
+// column can't be told from the query.
+func (t *tracer) args(query string, args []driver.NamedValue) []string {
+	cols := placeholderColumns(query)
+	out := make([]string, len(args))
+	for i, a := range args {
+		name := a.Name
+		if name == "" {
+			name = cols[a.Ordinal]
+		}
+		if name == "" || t.sensitive(name) {
+			out[i] = "[redacted]"
+			continue
+		}
+		out[i] = formatArg(a.Value, t.opts.MaxArgLen)
+	}
+	return out
+}
+
+// sensitive reports whether values named name are redacted.
+func (t *tracer) sensitive(name string) bool {
+	for _, r := range t.opts.Redact {
+		if strings.EqualFold(name, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatArg formats an argument, truncating it past max.
+func formatArg(v any, max int) string {
+	var s string
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		if len(v) > max {
+			return fmt.Sprintf("[%d bytes]", len(v))
+		}
+		s = string(v)
+	case string:
+		s = v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+	if len(s) > max {
+		s = s[:max] + "…"
+	}
+	return fmt.Sprintf("%q", s)
+}
+
+// compact collapses the whitespace of a query written over several lines.
+func compact(q string) string {
+	return strings.Join(strings.Fields(q), " ")
+}
@@ -0,0 +1,190 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+// stats are the logger's own metrics. They're registered with
+// helpers.DefaultRegistry, so they show up in the heartbeat entry and the
+// Prometheus handler next to the application's metrics, labeled with the
+// logger's run_id so loggers started side by side keep their own counts.
+// Shutdown unregisters them.
+type stats struct {
+	runID    string
+	metrics  []helpers.Metric // everything registered, for unregister
+	entries  map[Level]*helpers.Counter
+	bytes    *helpers.Counter
+	filtered *helpers.Counter // entries dropped by filters
+	errors   *helpers.Counter // the logger's own problems, see SetErrorHandler
+	dropped  *helpers.Counter // entries dropped by TryInfo and friends
+}
+
+func newStats(runID string) *stats {
+	s := &stats{runID: runID, entries: map[Level]*helpers.Counter{}}
+	for _, e := range []Level{DEBUG, INFO, WARNING, ERROR, CRITICAL} {
+		s.entries[e] = s.counter("logger_entries_total", helpers.Labels{"level": e.name()})
+	}
+	s.bytes = s.counter("logger_bytes_written_total", nil)
+	s.filtered = s.counter("logger_entries_filtered_total", nil)
+	s.errors = s.counter("logger_errors_total", nil)
+	s.dropped = s.counter("logger_entries_dropped_total", nil)
+	return s
+}
+
+// counter registers a counter of the logger's, labeled with its run_id.
+func (s *stats) counter(name string, labels helpers.Labels) *helpers.Counter {
+	c := helpers.NewCounter(name, s.labels(labels))
+	s.metrics = append(s.metrics, c)
+	return c
+}
+
+// gauge registers a gauge of the logger's, labeled with its run_id.
+func (s *stats) gauge(name string, labels helpers.Labels) *helpers.Gauge {
+	g := helpers.NewGauge(name, s.labels(labels))
+	s.metrics = append(s.metrics, g)
+	return g
+}
+
+func (s *stats) labels(labels helpers.Labels) helpers.Labels {
+	out := helpers.Labels{"run_id": s.runID}
+	for k, v := range labels {
+		out[k] = v
+	}
+	return out
+}
+
+// unregister removes the logger's metrics from helpers.DefaultRegistry.
+func (s *stats) unregister() {
+	for _, m := range s.metrics {
+		helpers.DefaultRegistry.Unregister(m)
+	}
+}
+
+// metricsSummary is helpers.DefaultRegistry's Summary for the heartbeat of
+// the logger with this run ID: other loggers' metrics are left out, and
+// its own are shown without the run_id label.
+func metricsSummary(runID string) string {
+	var parts []string
+	for _, m := range helpers.DefaultRegistry.Metrics() {
+		labels := m.Labels()
+		if id, ok := labels["run_id"]; ok {
+			if id != runID {
+				continue
+			}
+			labels = maps.Clone(labels)
+			delete(labels, "run_id")
+		}
+		parts = append(parts, m.Name()+labels.String()+"="+strconv.FormatFloat(m.Value(), 'g', -1, 64))
+	}
+	return strings.Join(parts, " ")
+}
+
+// entry counts one entry written at level e.
+func (s *stats) entry(e Level) {
+	if c, ok := s.entries[e]; ok {
+		c.Inc()
+	}
+}
+
+// name returns the lowercase name of a log level.
+func (e Level) name() string {
+	switch e {
+	case DEBUG:
+		return "debug"
+	case INFO:
+		return "info"
+	case WARNING:
+		return "warning"
+	case ERROR:
+		return "error"
+	case CRITICAL:
+		return "critical"
+	}
+	return "unknown"
+}
+
+// HeartbeatOption changes what Heartbeat reports.
+type HeartbeatOption func(*heartbeat)
+
+type heartbeat struct {
+	mem  bool
+	last runtime.MemStats // as of the previous beat
+	gor  int              // goroutines at the previous beat
+}
+
+// WithMemStats adds the heap in use, garbage collections and their pauses,
+// and the number of goroutines to each heartbeat, with the change since the
+// previous one, for services without a metrics stack.
+func WithMemStats() HeartbeatOption {
+	return func(h *heartbeat) { h.mem = true }
+}
+
+// Heartbeat logs a summary entry every interval until ctx is done or the
+// logger shuts down: uptime, every metric in helpers.DefaultRegistry,
+// which includes the logger's own entry and byte counts but not those of
+// other loggers, and the p50, p95
+// and p99 durations of each operation, see Begin.
+func (l *Mylogger) Heartbeat(ctx context.Context, every time.Duration, opts ...HeartbeatOption) {
+	h := &heartbeat{}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.mem {
+		runtime.ReadMemStats(&h.last)
+		h.gor = runtime.NumGoroutine()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-l.chans.done:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+	go helpers.TickFunc(ctx, every, func(time.Time) {
+		msg := fmt.Sprintf("heartbeat: uptime=%s %s", helpers.Duration(time.Since(l.start)), metricsSummary(l.runID))
+		if h.mem {
+			msg += " " + h.memStats()
+		}
+		if ops := l.ops.summary(); ops != "" {
+			msg += " " + ops
+		}
+		l.Info(msg)
+	})
+}
+
+// memStats summarizes the runtime's memory, GC and goroutines, with the
+// change since the last call.
+func (h *heartbeat) memStats() string {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	gor := runtime.NumGoroutine()
+	last, lastGor := h.last, h.gor
+	h.last, h.gor = m, gor
+	gcs := m.NumGC - last.NumGC
+	pause := time.Duration(m.PauseTotalNs - last.PauseTotalNs)
+	// PauseNs holds the last 256 pauses; the one of GC k is at (k+255)%256.
+	var maxPause time.Duration
+	for k := m.NumGC - min(gcs, 256) + 1; k <= m.NumGC; k++ {
+		maxPause = max(maxPause, time.Duration(m.PauseNs[(k+255)%256]))
+	}
+	return fmt.Sprintf("heap_inuse=%s (%s) gc=%d (+%d, paused %s, max %s) goroutines=%d (%+d)",
+		helpers.Bytes(int64(m.HeapInuse)), signedBytes(int64(m.HeapInuse)-int64(last.HeapInuse)),
+		m.NumGC, gcs, helpers.Duration(pause), helpers.Duration(maxPause), gor, gor-lastGor)
+}
+
+// signedBytes is helpers.Bytes with a + on increases.
+func signedBytes(n int64) string {
+	if n >= 0 {
+		return "+" + helpers.Bytes(n)
+	}
+	return helpers.Bytes(n)
+}
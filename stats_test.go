@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jeanhaley32/logger/helpers"
+)
+
+func TestLoggersKeepTheirOwnCounts(t *testing.T) {
+	var out1, out2 bytes.Buffer
+	l1, err := StartLogger(WithSyncMode(), WithOutput(&out1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l2, err := StartLogger(WithSyncMode(), WithOutput(&out2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l1.Info("one")
+	l1.Info("two")
+	l2.Info("three")
+	if n := l1.stats.entries[INFO].Load(); n != 2 {
+		t.Errorf("first logger counted %d INFO entries, want 2", n)
+	}
+	if n := l2.stats.entries[INFO].Load(); n != 1 {
+		t.Errorf("second logger counted %d INFO entries, want 1", n)
+	}
+	sum := metricsSummary(l1.runID)
+	if !strings.Contains(sum, `logger_entries_total{level="info"}=2`) || strings.Contains(sum, "run_id") {
+		t.Errorf("heartbeat metrics of the first logger = %s", sum)
+	}
+
+	l2.Shutdown(nil)
+	for _, m := range helpers.DefaultRegistry.Metrics() {
+		if m.Labels()["run_id"] == l2.runID {
+			t.Errorf("%s%s still registered after Shutdown", m.Name(), m.Labels())
+		}
+	}
+	l1.Shutdown(nil)
+}
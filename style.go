@@ -0,0 +1,35 @@
+package logger
+
+// style is how a logger's text entries look: the level colors and the time
+// layout. A logger's style is replaced as a whole, see Mylogger.setStyle, and
+// handed to the encoders with each Record.
+type style struct {
+	colors     [5]Color // by severity, DEBUG first
+	timeFormat string   // Go layout or helpers.FormatTime name
+}
+
+// defaultStyle is the style of a new logger, and of records without one.
+var defaultStyle = &style{
+	colors:     [5]Color{BLUE, WHITE, YELLOW, RED, PURPLE},
+	timeFormat: "2006-01-02 15:04:05",
+}
+
+// color returns the color of level e; values that aren't levels get INFO's.
+func (s *style) color(e Level) Color {
+	if i := e.severity(); i >= 0 {
+		return s.colors[i]
+	}
+	return s.colors[INFO.severity()]
+}
+
+// setStyle changes the logger's style with fn, which is given a copy of it.
+func (l *Mylogger) setStyle(fn func(s *style)) {
+	for {
+		old := l.style.Load()
+		s := *old
+		fn(&s)
+		if l.style.CompareAndSwap(old, &s) {
+			return
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTimeFormatIsPerLogger(t *testing.T) {
+	fail := func(*Mylogger) error { return errors.New("bad option") }
+	if _, err := StartLogger(WithTimeFormat("15:04"), fail); err == nil {
+		t.Fatal("StartLogger succeeded with a failing option")
+	}
+	var week, plain syncBuffer
+	l1, err := StartLogger(WithSyncMode(), WithOutput(&week), WithEncoder(TextEncoder{}), WithTimeFormat("isoweek"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l1.Shutdown(nil)
+	l2, err := StartLogger(WithSyncMode(), WithOutput(&plain), WithEncoder(TextEncoder{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l2.Shutdown(nil)
+	l1.Info("one")
+	l2.Info("two")
+	if !isoWeekRe.MatchString(strings.SplitN(week.String(), ":", 2)[0]) {
+		t.Errorf("isoweek logger wrote %q", week.String())
+	}
+	e, err := ParseEntry([]byte(plain.String()))
+	if err != nil || e.Time.IsZero() {
+		t.Errorf("default logger wrote %q, which parses as %v, %v", plain.String(), e.Time, err)
+	}
+}
+
+func TestColorsArePerLogger(t *testing.T) {
+	var out1, out2 syncBuffer
+	l1, err := StartLogger(WithSyncMode(), WithOutput(&out1), WithEncoder(TextEncoder{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l1.Shutdown(nil)
+	l2, err := StartLogger(WithSyncMode(), WithOutput(&out2), WithEncoder(TextEncoder{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l2.Shutdown(nil)
+	for _, l := range []*Mylogger{l1, l2} {
+		l.out.mu.Lock()
+		l.out.terminal = true
+		l.out.mu.Unlock()
+	}
+	if err := l1.ApplyConfig(Config{Colors: map[string]string{"error": "green"}, TimeFormat: "rfc3339nano"}); err != nil {
+		t.Fatal(err)
+	}
+	l1.Error("one")
+	l2.Error("two")
+	if !strings.Contains(out1.String(), colorWrap(GREEN, "ERROR:")) {
+		t.Errorf("configured logger wrote %q", out1.String())
+	}
+	if !strings.Contains(out2.String(), colorWrap(RED, "ERROR:")) || strings.Contains(out2.String(), "T") {
+		t.Errorf("other logger wrote %q, want the default color and time format", out2.String())
+	}
+	if ERROR.Color() != RED {
+		t.Errorf("ERROR.Color() = %v, want the default", ERROR.Color())
+	}
+}
@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"time"
+)
+
+// Timer measures a section of code, see Mylogger.Timer.
+type Timer struct {
+	l         *Mylogger
+	name      string
+	start     time.Time
+	warnAfter time.Duration
+}
+
+// Timer starts timing the section called name. Stop logs the elapsed time at
+// DEBUG, or at WARNING if it took longer than the optional warnAfter threshold.
+// Example:
+// t := l.Timer("load users", time.Second)
+// defer t.Stop()
+func (l *Mylogger) Timer(name string, warnAfter ...time.Duration) *Timer {
+	t := &Timer{l: l, name: name, start: time.Now()}
+	if len(warnAfter) > 0 {
+		t.warnAfter = warnAfter[0]
+	}
+	return t
+}
+
+// Stop logs and returns the time elapsed since the timer started.
+func (t *Timer) Stop() time.Duration {
+	d := time.Since(t.start)
+	if t.warnAfter > 0 && d > t.warnAfter {
+		t.l.Warningf("%s took %s (threshold %s)", t.name, d, t.warnAfter)
+	} else {
+		t.l.Debugf("%s took %s", t.name, d)
+	}
+	return d
+}
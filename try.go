@@ -0,0 +1,97 @@
+package logger
+
+import "time"
+
+// TryDebug logs a at DEBUG if there's room in the queue, without waiting.
+// It returns false if the entry was dropped because the queue was full.
+func (l *Mylogger) TryDebug(a any) bool { return l.tryLog(DEBUG, a, 0) }
+
+// TryInfo logs a at INFO if there's room in the queue, without waiting.
+// It returns false if the entry was dropped because the queue was full.
+func (l *Mylogger) TryInfo(a any) bool { return l.tryLog(INFO, a, 0) }
+
+// TryWarning logs a at WARNING if there's room in the queue, without waiting.
+// It returns false if the entry was dropped because the queue was full.
+func (l *Mylogger) TryWarning(a any) bool { return l.tryLog(WARNING, a, 0) }
+
+// TryError logs a at ERROR if there's room in the queue, without waiting.
+// It returns false if the entry was dropped because the queue was full.
+func (l *Mylogger) TryError(a any) bool { return l.tryLog(ERROR, a, 0) }
+
+// DebugWithTimeout logs a at DEBUG, waiting at most d for room in the queue.
+// It returns false if the entry was dropped because the queue stayed full.
+func (l *Mylogger) DebugWithTimeout(a any, d time.Duration) bool { return l.tryLog(DEBUG, a, d) }
+
+// InfoWithTimeout logs a at INFO, waiting at most d for room in the queue.
+// It returns false if the entry was dropped because the queue stayed full.
+func (l *Mylogger) InfoWithTimeout(a any, d time.Duration) bool { return l.tryLog(INFO, a, d) }
+
+// WarningWithTimeout logs a at WARNING, waiting at most d for room in the
+// queue. It returns false if the entry was dropped because the queue stayed
+// full.
+func (l *Mylogger) WarningWithTimeout(a any, d time.Duration) bool {
+	return l.tryLog(WARNING, a, d)
+}
+
+// ErrorWithTimeout logs a at ERROR, waiting at most d for room in the queue.
+// It returns false if the entry was dropped because the queue stayed full.
+func (l *Mylogger) ErrorWithTimeout(a any, d time.Duration) bool { return l.tryLog(ERROR, a, d) }
+
+func (l *Mylogger) tryLog(e Level, a any, d time.Duration) bool {
+	if !l.enabled(e) {
+		return true
+	}
+	return l.trySend(entry{level: e, value: a}, d)
+}
+
+// trySend is send with a bound on the wait for room in the queue: none if d
+// is 0. Entries that don't get in are dropped, counted and reported, once
+// until one gets in again. The callers of
+// guaranteed levels don't wait for the write either, since that has no
+// bound; and in sync mode, or once the logger has shut down, the entry is
+// written by the caller as usual.
+func (l *Mylogger) trySend(en entry, d time.Duration) bool {
+	en.at = l.now()
+	if l.caller {
+		en.caller = caller()
+	}
+	if l.sync {
+		l.syncMu.Lock()
+		defer l.syncMu.Unlock()
+		l.write(en)
+		return true
+	}
+	l.sendMu.RLock()
+	if l.closed {
+		l.sendMu.RUnlock()
+		l.syncMu.Lock()
+		defer l.syncMu.Unlock()
+		l.write(en)
+		return true
+	}
+	defer l.sendMu.RUnlock()
+	if l.spill != nil {
+		// spilling doesn't wait for the mediator.
+		l.spill.offer(l, en)
+		return true
+	}
+	select {
+	case l.chans.entries <- en:
+		l.queued()
+		return true
+	default:
+	}
+	if d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case l.chans.entries <- en:
+			l.queued()
+			return true
+		case <-t.C:
+		}
+	}
+	l.stats.dropped.Inc()
+	l.reportDrop()
+	return false
+}
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// watches are the pending Watch calls.
+type watches struct {
+	mu     sync.Mutex
+	list   []*watch
+	n      atomic.Int32 // len(list), so writes can skip the lock when it's 0
+	closed bool         // the logger shut down
+}
+
+type watch struct {
+	match func(Record) bool
+	ch    chan Record
+}
+
+// Watch returns a channel that receives the next entry written that matches
+// pattern, and is then closed; it's closed without an entry if the logger
+// shuts down first. pattern is a regular expression, as a string or
+// *regexp.Regexp, tested against the message, or a func(Record) bool. It
+// lets tests and supervisors wait for a message rather than sleep and grep.
+// Example:
+// up, err := l.Watch(`server listening`)
+// go srv.Run()
+// select {
+// case <-up:
+// case <-time.After(5 * time.Second):
+// }
+func (l *Mylogger) Watch(pattern any) (<-chan Record, error) {
+	fn, err := matcher(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("watch: %w", err)
+	}
+	w := &watch{match: fn, ch: make(chan Record, 1)}
+	ws := &l.watches
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ws.closed {
+		close(w.ch)
+		return w.ch, nil
+	}
+	ws.list = append(ws.list, w)
+	ws.n.Store(int32(len(ws.list)))
+	return w.ch, nil
+}
+
+// check hands r to the watches it matches, and drops them.
+func (ws *watches) check(l *Mylogger, r Record) {
+	if ws.n.Load() == 0 {
+		return
+	}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	kept := ws.list[:0]
+	for _, w := range ws.list {
+		if !l.matches("watch", w.match, r) {
+			kept = append(kept, w)
+			continue
+		}
+		w.ch <- r // buffered for exactly this one.
+		close(w.ch)
+	}
+	clear(ws.list[len(kept):])
+	ws.list = kept
+	ws.n.Store(int32(len(kept)))
+}
+
+// close closes the channels of the watches still pending.
+func (ws *watches) close() {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	for _, w := range ws.list {
+		close(w.ch)
+	}
+	ws.list, ws.closed = nil, true
+	ws.n.Store(0)
+}